@@ -0,0 +1,37 @@
+package cmdauth
+
+import (
+	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/easy-model-fusion/emf-cli/internal/controller"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/cobrautil"
+	"github.com/spf13/cobra"
+)
+
+const authCommandName string = "auth"
+
+var authController controller.AuthController
+
+// AuthCmd represents the auth command
+var AuthCmd = &cobra.Command{
+	Use:   authCommandName,
+	Short: "Palette that contains the hugging face authentication commands",
+	Long:  "Palette that contains the hugging face authentication commands",
+	Run:   runAuth,
+}
+
+func init() {
+	// Adding the subcommands
+	AuthCmd.AddCommand(authLoginCmd)
+	AuthCmd.AddCommand(authStatusCmd)
+	AuthCmd.AddCommand(authLogoutCmd)
+}
+
+// runAuth runs auth command
+func runAuth(cmd *cobra.Command, args []string) {
+
+	// Running command as palette : allowing user to choose subcommand
+	err := cobrautil.RunCommandAsPalette(cmd, args, authCommandName, []string{})
+	if err != nil {
+		app.UI().Error().Println("Something went wrong :", err)
+	}
+}
@@ -0,0 +1,29 @@
+package cmdauth
+
+import (
+	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+// authLoginCmd represents the auth login command
+var authLoginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Store a hugging face access token for the gated and private models",
+	Long: `Store a hugging face access token for the gated and private models, once validated against the hub.
+The token is used when none is given by the --access-token flag nor saved for the model, after HF_TOKEN.`,
+	Args: cobra.NoArgs,
+	Run:  runAuthLogin,
+}
+
+func init() {
+	authLoginCmd.Flags().StringVarP(&authController.Token, "token", "t", "", "Access token to store, asked for when omitted")
+}
+
+// runAuthLogin runs the auth login command
+func runAuthLogin(cmd *cobra.Command, args []string) {
+	if err := authController.RunLogin(); err != nil {
+		app.UI().Error().Println(err.Error())
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,24 @@
+package cmdauth
+
+import (
+	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+// authLogoutCmd represents the auth logout command
+var authLogoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "Remove the stored hugging face access token",
+	Long:  "Remove the stored hugging face access token",
+	Args:  cobra.NoArgs,
+	Run:   runAuthLogout,
+}
+
+// runAuthLogout runs the auth logout command
+func runAuthLogout(cmd *cobra.Command, args []string) {
+	if err := authController.RunLogout(); err != nil {
+		app.UI().Error().Println(err.Error())
+		os.Exit(1)
+	}
+}
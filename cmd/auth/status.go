@@ -0,0 +1,30 @@
+package cmdauth
+
+import (
+	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/outpututil"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/stringutil"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+// authStatusCmd represents the auth status command
+var authStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Validate the access token in use and display its scopes",
+	Long:  `Validate the access token in use, from HF_TOKEN then from the stored one, against the hub and display its owner and scopes`,
+	Args:  cobra.NoArgs,
+	Run:   runAuthStatus,
+}
+
+func init() {
+	authStatusCmd.Flags().StringVarP(&authController.Output, "output", "o", string(outpututil.Table), "Output format "+stringutil.SliceToArgsFormat(outpututil.AllFormatsString()))
+}
+
+// runAuthStatus runs the auth status command
+func runAuthStatus(cmd *cobra.Command, args []string) {
+	if err := authController.RunStatus(); err != nil {
+		app.UI().Error().Println(err.Error())
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/easy-model-fusion/emf-cli/internal/controller"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/outpututil"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/stringutil"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+// envCmd represents the env command
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Display the settings in use, such as the hub endpoint and proxy",
+	Long:  `Display the settings in use, such as the hub endpoint and proxy, along with where they come from`,
+	Run:   runEnv,
+}
+
+var envController controller.EnvController
+
+func init() {
+	envCmd.Flags().StringVarP(&envController.Output, "output", "o", string(outpututil.Table), "Output format "+stringutil.SliceToArgsFormat(outpututil.AllFormatsString()))
+}
+
+// runEnv runs the env command
+func runEnv(cmd *cobra.Command, args []string) {
+	if err := envController.Run(); err != nil {
+		app.UI().Error().Println(err.Error())
+		os.Exit(1)
+	}
+}
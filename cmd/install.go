@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"github.com/easy-model-fusion/emf-cli/internal/controller"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/outpututil"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/stringutil"
 	"github.com/spf13/cobra"
 	"os"
 )
@@ -30,4 +32,8 @@ func runInstall(cmd *cobra.Command, args []string) {
 func init() {
 	installCmd.Flags().BoolVarP(&installUseTorchCuda, "cuda", "c", false, "Use torch with cuda")
 	installCmd.Flags().StringVarP(&protectedModelsAccessToken, "access-token", "a", "", "Access token for gated models")
+	installCmd.Flags().BoolVar(&installController.Frozen, "frozen", false, "Fail if the project doesn't match the lockfile instead of updating it")
+	installCmd.Flags().IntVarP(&installController.Jobs, "jobs", "j", 1, "Maximum number of models downloaded at the same time")
+	installCmd.Flags().BoolVar(&installController.DryRun, "dry-run", false, "Only display what would be changed")
+	installCmd.Flags().StringVarP(&installController.Output, "output", "o", string(outpututil.Table), "Output format of the dry run "+stringutil.SliceToArgsFormat(outpututil.AllFormatsString()))
 }
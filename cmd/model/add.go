@@ -4,6 +4,7 @@ import (
 	"github.com/easy-model-fusion/emf-cli/internal/app"
 	"github.com/easy-model-fusion/emf-cli/internal/controller/model"
 	"github.com/easy-model-fusion/emf-cli/internal/downloader/model"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/stringutil"
 	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
 	"github.com/spf13/cobra"
 	"os"
@@ -11,10 +12,13 @@ import (
 
 // addCmd represents the add model by names command
 var modelAddCmd = &cobra.Command{
-	Use:   "add [model name]",
-	Short: "Add model by name to your project",
-	Long:  `Add model by name to your project`,
-	Run:   runAdd,
+	Use:   "add [<model names>...]",
+	Short: "Add models by name to your project",
+	Long: `Add models by name or through a manifest file to your project
+
+An interrupted download resumes where it stopped on the next run,
+the partially downloaded files being kept until the download completes.`,
+	Run: runAdd,
 }
 
 var (
@@ -23,14 +27,14 @@ var (
 )
 
 func init() {
-	// Initialize hugging face api
-	app.InitHuggingFace(huggingface.BaseUrl, "")
-
 	// Bind cobra args to the downloader script args
 	customArgs.ToCobra(modelAddCmd)
 	customArgs.DirectoryPath = app.DownloadDirectoryPath
 	modelAddCmd.Flags().BoolVarP(&addController.AuthorizeDownload, "yes", "y", false, "Automatic yes to prompts")
-	modelAddCmd.Flags().BoolVarP(&addController.SingleFile, "single-file", "S", false, "Use the model as a single file, (usually its a safetensors file)")
+	modelAddCmd.Flags().BoolVarP(&addController.SingleFile, "single-file", "S", false, "Use the model as a single file (a safetensors or GGUF file for instance), given as a path or an HTTP(S) URL")
+	modelAddCmd.Flags().StringVar(&addController.PipelineTag, "pipeline-tag", "", "Pipeline tag of a single file model, inferred when omitted "+stringutil.SliceToArgsFormat(huggingface.AllTagsString()))
+	modelAddCmd.Flags().StringVar(&addController.Sha256, "sha256", "", "Expected sha256 checksum of a single file model")
+	modelAddCmd.Flags().StringVarP(&addController.ManifestPath, "file", "f", "", "Manifest file listing the models to be added")
 }
 
 // runAddByNames runs the add command to add models by name
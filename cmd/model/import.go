@@ -0,0 +1,37 @@
+package cmdmodel
+
+import (
+	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/easy-model-fusion/emf-cli/internal/controller/model"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/stringutil"
+	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+// modelImportCmd represents the model import command
+var modelImportCmd = &cobra.Command{
+	Use:   "import <directory>",
+	Short: "Import a model stored in a local directory",
+	Long:  "Import a model saved with save_pretrained in a local directory, detecting its module, class, tokenizers and pipeline tag",
+	Args:  cobra.ExactArgs(1),
+	Run:   runModelImport,
+}
+
+var importController modelcontroller.ImportController
+
+func init() {
+	modelImportCmd.Flags().StringVarP(&importController.Name, "name", "n", "", "Name of the imported model (e.g. organization/model)")
+	modelImportCmd.Flags().StringVarP(&importController.PipelineTag, "pipeline-tag", "p", "", "Pipeline tag of the model, detected when omitted "+stringutil.SliceToArgsFormat(huggingface.AllTagsString()))
+	modelImportCmd.Flags().BoolVarP(&importController.Hardlink, "hardlink", "l", false, "Hardlink the files instead of copying them when possible")
+	_ = modelImportCmd.MarkFlagRequired("name")
+}
+
+// runModelImport runs the model import command
+func runModelImport(cmd *cobra.Command, args []string) {
+	err := importController.Run(args)
+	if err != nil {
+		app.UI().Error().Println(err.Error())
+		os.Exit(1)
+	}
+}
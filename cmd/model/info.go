@@ -0,0 +1,35 @@
+package cmdmodel
+
+import (
+	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/easy-model-fusion/emf-cli/internal/controller/model"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/outpututil"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/stringutil"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+// modelInfoCmd represents the model info command
+var modelInfoCmd = &cobra.Command{
+	Use:   "info <model name>",
+	Short: "Display everything about a configured model",
+	Long:  "Display the configuration, the state on the device and the state on the hub of a configured model",
+	Args:  cobra.ExactArgs(1),
+	Run:   runModelInfo,
+}
+
+var infoController modelcontroller.InfoController
+
+func init() {
+	modelInfoCmd.Flags().StringVarP(&infoController.Output, "output", "o", string(outpututil.Table), "Output format "+stringutil.SliceToArgsFormat(outpututil.AllFormatsString()))
+	modelInfoCmd.Flags().StringVarP(&infoController.AccessToken, "access-token", "a", "", "Access token for gated models")
+}
+
+// runModelInfo runs the model info command
+func runModelInfo(cmd *cobra.Command, args []string) {
+	err := infoController.Run(args)
+	if err != nil {
+		app.UI().Error().Println(err.Error())
+		os.Exit(1)
+	}
+}
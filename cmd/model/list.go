@@ -0,0 +1,37 @@
+package cmdmodel
+
+import (
+	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/easy-model-fusion/emf-cli/internal/controller/model"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/outpututil"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/stringutil"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+// modelListCmd represents the model list command
+var modelListCmd = &cobra.Command{
+	Use:   "list [<model names>...]",
+	Short: "List the configured models",
+	Long:  "List the configured models",
+	Run:   runModelList,
+}
+
+var listController modelcontroller.ListController
+
+func init() {
+	modelListCmd.Flags().StringVarP(&listController.Output, "output", "o", string(outpututil.Table), "Output format "+stringutil.SliceToArgsFormat(outpututil.AllFormatsString()))
+	modelListCmd.Flags().StringVarP(&listController.Module, "module", "m", "", "Only list the models using this module")
+	modelListCmd.Flags().StringVarP(&listController.Source, "source", "s", "", "Only list the models coming from this source")
+	modelListCmd.Flags().BoolVarP(&listController.Downloaded, "downloaded", "d", false, "Only list the downloaded models")
+	modelListCmd.Flags().BoolVarP(&listController.Binary, "binary", "b", false, "Only list the models added to the binary file")
+}
+
+// runModelList runs the model list command
+func runModelList(cmd *cobra.Command, args []string) {
+	err := listController.Run(args)
+	if err != nil {
+		app.UI().Error().Println(err.Error())
+		os.Exit(1)
+	}
+}
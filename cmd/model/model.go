@@ -3,7 +3,6 @@ package cmdmodel
 import (
 	"github.com/easy-model-fusion/emf-cli/internal/app"
 	"github.com/easy-model-fusion/emf-cli/internal/utils/cobrautil"
-	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
 	"github.com/spf13/cobra"
 )
 
@@ -18,13 +17,15 @@ var ModelCmd = &cobra.Command{
 }
 
 func init() {
-	// Preparing to use the hugging face API
-	app.InitHuggingFace(huggingface.BaseUrl, "")
-
 	// Adding the subcommands
 	ModelCmd.AddCommand(modelRemoveCmd)
 	ModelCmd.AddCommand(modelUpdateCmd)
 	ModelCmd.AddCommand(modelAddCmd)
+	ModelCmd.AddCommand(modelListCmd)
+	ModelCmd.AddCommand(modelInfoCmd)
+	ModelCmd.AddCommand(modelSearchCmd)
+	ModelCmd.AddCommand(modelOutdatedCmd)
+	ModelCmd.AddCommand(modelImportCmd)
 }
 
 // runModel runs model command
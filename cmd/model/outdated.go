@@ -0,0 +1,37 @@
+package cmdmodel
+
+import (
+	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/easy-model-fusion/emf-cli/internal/controller/model"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/outpututil"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/stringutil"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+// modelOutdatedCmd represents the model outdated command
+var modelOutdatedCmd = &cobra.Command{
+	Use:   "outdated [<model names>...]",
+	Short: "Check which models can be updated, without downloading anything",
+	Long:  "Check which models can be updated, without downloading anything. Exits with a non-zero code when updates are pending.",
+	Run:   runModelOutdated,
+}
+
+var outdatedController modelcontroller.OutdatedController
+
+func init() {
+	modelOutdatedCmd.Flags().StringVarP(&outdatedController.Output, "output", "o", string(outpututil.Table), "Output format "+stringutil.SliceToArgsFormat(outpututil.AllFormatsString()))
+	modelOutdatedCmd.Flags().StringVarP(&outdatedController.AccessToken, "access-token", "a", "", "Access token for gated models")
+}
+
+// runModelOutdated runs the model outdated command
+func runModelOutdated(cmd *cobra.Command, args []string) {
+	pending, err := outdatedController.Run(args)
+	if err != nil {
+		app.UI().Error().Println(err.Error())
+		os.Exit(1)
+	}
+	if pending {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,42 @@
+package cmdmodel
+
+import (
+	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/easy-model-fusion/emf-cli/internal/controller/model"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/outpututil"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/stringutil"
+	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+// modelSearchCmd represents the model search command
+var modelSearchCmd = &cobra.Command{
+	Use:   "search [<query>]",
+	Short: "Search models on the hub",
+	Long:  "Search models on the hub, only keeping those using a supported module",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   runModelSearch,
+}
+
+var searchController modelcontroller.SearchController
+
+func init() {
+	modelSearchCmd.Flags().StringVarP(&searchController.Output, "output", "o", string(outpututil.Table), "Output format "+stringutil.SliceToArgsFormat(outpututil.AllFormatsString()))
+	modelSearchCmd.Flags().StringVar(&searchController.Author, "author", "", "Only search the models of this author")
+	modelSearchCmd.Flags().StringVar(&searchController.Tag, "tag", "", "Only search the models having this tag (i.e. text-to-image)")
+	modelSearchCmd.Flags().StringVar(&searchController.PipelineTag, "pipeline-tag", "", "Only search the models of this pipeline "+stringutil.SliceToArgsFormat(huggingface.AllTagsString()))
+	modelSearchCmd.Flags().StringVar(&searchController.Library, "library", "", "Only search the models using this library "+stringutil.SliceToArgsFormat(huggingface.AllModulesString()))
+	modelSearchCmd.Flags().StringVar(&searchController.Sort, "sort", string(huggingface.SortDownloads), "Sort the models by "+stringutil.SliceToArgsFormat(huggingface.AllSortsString()))
+	modelSearchCmd.Flags().IntVar(&searchController.Limit, "limit", 20, "Maximum number of models to search, following the pages of results")
+	modelSearchCmd.Flags().StringVarP(&searchController.AccessToken, "access-token", "a", "", "Access token for gated models")
+}
+
+// runModelSearch runs the model search command
+func runModelSearch(cmd *cobra.Command, args []string) {
+	err := searchController.Run(args)
+	if err != nil {
+		app.UI().Error().Println(err.Error())
+		os.Exit(1)
+	}
+}
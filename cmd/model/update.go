@@ -1,29 +1,51 @@
 package cmdmodel
 
 import (
+	"github.com/easy-model-fusion/emf-cli/internal/app"
 	modelcontroller "github.com/easy-model-fusion/emf-cli/internal/controller/model"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/outpututil"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/stringutil"
 	"github.com/spf13/cobra"
+	"os"
 )
 
 // modelUpdateCmd represents the model update command
 var modelUpdateCmd = &cobra.Command{
 	Use:   "update <model name> [<other model names>...]",
 	Short: "Update one or more models",
-	Long:  "Update one or more models",
-	Run:   runModelUpdate,
+	Long: `Update one or more models
+
+The models added or updated with a given revision are pinned to it :
+they are only moved to another revision using --to.`,
+	Run: runModelUpdate,
 }
 
 var (
 	accessToken        string
 	authorizeOverwrite bool
+	targetRevision     string
+	updateJobs         int
+	updateDryRun       bool
+	updateOutput       string
 )
 
 func init() {
 	modelUpdateCmd.Flags().BoolVarP(&authorizeOverwrite, "yes", "y", false, "Automatic yes to prompts")
 	modelUpdateCmd.Flags().StringVarP(&accessToken, "access-token", "a", "", "Access token for gated models")
+	modelUpdateCmd.Flags().StringVar(&targetRevision, "to", "", "Revision to move the models to (commit sha, branch or tag), pinning them to it")
+	modelUpdateCmd.Flags().IntVarP(&updateJobs, "jobs", "j", 1, "Maximum number of models downloaded at the same time")
+	modelUpdateCmd.Flags().BoolVar(&updateDryRun, "dry-run", false, "Only display what would be changed")
+	modelUpdateCmd.Flags().StringVarP(&updateOutput, "output", "o", string(outpututil.Table), "Output format of the dry run "+stringutil.SliceToArgsFormat(outpututil.AllFormatsString()))
 }
 
 // runModelUpdate runs the model update command
 func runModelUpdate(cmd *cobra.Command, args []string) {
-	modelcontroller.RunModelUpdate(args, authorizeOverwrite, accessToken)
+	if updateDryRun {
+		if err := modelcontroller.RunModelUpdatePlan(args, authorizeOverwrite, accessToken, targetRevision, updateOutput); err != nil {
+			app.UI().Error().Println(err.Error())
+			os.Exit(1)
+		}
+		return
+	}
+	modelcontroller.RunModelUpdate(args, authorizeOverwrite, accessToken, targetRevision, updateJobs)
 }
@@ -25,7 +25,7 @@ func TestRunModelUpdate(t *testing.T) {
 	args = append(args, "model4")
 
 	// Create hugging face mock
-	huggingFace := huggingface.MockHuggingFace{GetModelResult: huggingface.Model{LastModified: "2022", LibraryName: huggingface.TRANSFORMERS}}
+	huggingFace := huggingface.MockHuggingFace{GetModelResult: huggingface.Model{LastModified: "2022", Sha: "sha2022", LibraryName: huggingface.TRANSFORMERS}}
 	app.SetHuggingFace(&huggingFace)
 
 	// Create Ui mock
@@ -63,5 +63,6 @@ func GetModel(id int, version string) model.Model {
 		Source:       model.HUGGING_FACE,
 		IsDownloaded: true,
 		Version:      version,
+		Revision:     "sha" + version,
 	}
 }
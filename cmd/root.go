@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"github.com/easy-model-fusion/emf-cli/cmd/auth"
 	"github.com/easy-model-fusion/emf-cli/cmd/model"
 	"github.com/easy-model-fusion/emf-cli/cmd/tokenizer"
 	"github.com/easy-model-fusion/emf-cli/internal/app"
@@ -21,6 +22,9 @@ func Execute() {
 
 const rootCommandName string = app.Name
 
+var offline bool
+var noCache bool
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   rootCommandName,
@@ -31,14 +35,18 @@ var rootCmd = &cobra.Command{
 
 func init() {
 	app.InitGit(app.Repository, "")
+	cobra.OnInitialize(initOffline, initProject)
 	// Add persistent flag for configuration file path
 	rootCmd.PersistentFlags().StringVar(&config.FilePath, "config-path", ".", "config file path")
 	rootCmd.PersistentFlags().StringVar(app.G().GetAuthToken(), "git-auth-token", "", "Git auth token")
+	rootCmd.PersistentFlags().BoolVar(&offline, "offline", false, "Skip every huggingface and git call (also enabled by "+app.OfflineEnv+")")
+	rootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Fetch the huggingface metadata again instead of using the cached responses")
 
 	// Adding subcommands
 	rootCmd.CompletionOptions.HiddenDefaultCmd = true
 	rootCmd.AddCommand(completionCmd)
 	rootCmd.AddCommand(versionCmd)
+	rootCmd.AddCommand(envCmd)
 	rootCmd.AddCommand(initCmd)
 	rootCmd.AddCommand(upgradeCmd)
 	rootCmd.AddCommand(buildCmd)
@@ -48,6 +56,28 @@ func init() {
 	rootCmd.AddCommand(cmdmodel.ModelCmd)
 	rootCmd.AddCommand(buildCmd)
 	rootCmd.AddCommand(cmdtokenizer.TokenizerCmd)
+	rootCmd.AddCommand(cmdauth.AuthCmd)
+}
+
+// initOffline enables the offline mode from the flag or the environment
+func initOffline() {
+	app.InitOffline(offline)
+}
+
+// initProject applies the hub settings and selects the downloader configured in the project, if any
+func initProject() {
+	loaded := config.Load(config.FilePath) == nil
+
+	// Reaching the hub through the endpoint and proxy from the environment, then from the configuration file
+	config.ApplyHuggingFace(!noCache)
+
+	if !loaded {
+		// Not inside a project : keeping the default downloader
+		return
+	}
+	if err := config.ApplyDownloader(); err != nil {
+		app.UI().Warning().Println(err.Error())
+	}
 }
 
 func runRoot(cmd *cobra.Command, args []string) {
@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"github.com/easy-model-fusion/emf-cli/internal/controller"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/outpututil"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/stringutil"
 	"github.com/spf13/cobra"
 	"os"
 )
@@ -10,8 +12,11 @@ import (
 var tidyCmd = &cobra.Command{
 	Use:   "tidy",
 	Short: "Synchronizes the configuration file with the downloaded models",
-	Long:  `Synchronizes the configuration file with the downloaded models`,
-	Run:   runTidy,
+	Long: `Synchronizes the configuration file with the downloaded models
+
+An interrupted download resumes where it stopped on the next run,
+the partially downloaded files being kept until the download completes.`,
+	Run: runTidy,
 }
 var (
 	tidyController               controller.TidyController
@@ -22,6 +27,10 @@ var (
 func init() {
 	tidyCmd.Flags().BoolVarP(&authorizeAllSynchronisations, "yes", "y", false, "Automatic yes to prompts")
 	tidyCmd.Flags().StringVarP(&accessToken, "access-token", "a", "", "Access token for gated models")
+	tidyCmd.Flags().BoolVar(&tidyController.Frozen, "frozen", false, "Fail if the configuration file doesn't match the lockfile instead of updating it")
+	tidyCmd.Flags().IntVarP(&tidyController.Jobs, "jobs", "j", 1, "Maximum number of models downloaded at the same time")
+	tidyCmd.Flags().BoolVar(&tidyController.DryRun, "dry-run", false, "Only display what would be changed")
+	tidyCmd.Flags().StringVarP(&tidyController.Output, "output", "o", string(outpututil.Table), "Output format of the dry run "+stringutil.SliceToArgsFormat(outpututil.AllFormatsString()))
 }
 
 // runTidy runs the model tidy command
@@ -1,10 +1,8 @@
 package cmdtokenizer
 
 import (
-	"github.com/easy-model-fusion/emf-cli/internal/app"
 	"github.com/easy-model-fusion/emf-cli/internal/controller/tokenizer"
 	"github.com/easy-model-fusion/emf-cli/internal/downloader/model"
-	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
 	"github.com/spf13/cobra"
 )
 
@@ -25,9 +23,6 @@ var customArgs downloadermodel.Args
 var yes bool
 
 func init() {
-	// Initialize hugging face api
-	app.InitHuggingFace(huggingface.BaseUrl, "")
-
 	// Bind cobra args to the downloader script args
 	customArgs.ToCobraTokenizer(tokenizerAddCmd)
 	tokenizerAddCmd.Flags().BoolVarP(&yes, "yes", "y", false, "Automatic yes to prompts")
@@ -3,7 +3,6 @@ package cmdtokenizer
 import (
 	"github.com/easy-model-fusion/emf-cli/internal/app"
 	"github.com/easy-model-fusion/emf-cli/internal/utils/cobrautil"
-	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
 	"github.com/spf13/cobra"
 )
 
@@ -18,9 +17,6 @@ var TokenizerCmd = &cobra.Command{
 }
 
 func init() {
-	// Preparing to use the hugging face API
-	app.InitHuggingFace(huggingface.BaseUrl, "")
-
 	// Adding the subcommands
 	TokenizerCmd.AddCommand(tokenizerRemoveCmd)
 	TokenizerCmd.AddCommand(tokenizerUpdateCmd)
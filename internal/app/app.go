@@ -10,6 +10,9 @@ const Name = "emf-cli"
 const Repository = "https://github.com/easy-model-fusion"
 const DownloadDirectoryPath = "./models/"
 
+// StagingDirectoryPath holds the downloads in progress, until they replace the downloaded models
+const StagingDirectoryPath = "./.models-staging/"
+
 var (
 	// Version is the binary version + build number
 	Version string
@@ -20,6 +23,7 @@ var (
 var _ui ui.UI
 var _python python.Python
 var _downloader downloader.Downloader
+var concurrentDownloads bool
 
 func Init(version, buildDate string) {
 	Version = version
@@ -49,10 +53,14 @@ func SetUI(newUI ui.UI) {
 }
 
 // Python returns the current Python instance
+// The python packages can't be installed when offline.
 func Python() python.Python {
 	if _python == nil {
 		fatal("Python not initialized")
 	}
+	if offline {
+		return offlinePython{_python}
+	}
 	return _python
 }
 
@@ -62,7 +70,11 @@ func SetPython(newPython python.Python) {
 }
 
 // Downloader returns the current Downloader instance
+// Every download fails when offline.
 func Downloader() downloader.Downloader {
+	if offline {
+		return offlineDownloader{}
+	}
 	return _downloader
 }
 
@@ -70,3 +82,14 @@ func Downloader() downloader.Downloader {
 func SetDownloader(newDownloader downloader.Downloader) {
 	_downloader = newDownloader
 }
+
+// ConcurrentDownloads returns true while several models are downloaded at the same time
+// Their progress bars would overwrite each other : the progress is then summarized once per model.
+func ConcurrentDownloads() bool {
+	return concurrentDownloads
+}
+
+// SetConcurrentDownloads sets whether several models are downloaded at the same time
+func SetConcurrentDownloads(value bool) {
+	concurrentDownloads = value
+}
@@ -0,0 +1,145 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"github.com/easy-model-fusion/emf-cli/internal/downloader/model"
+	"github.com/easy-model-fusion/emf-cli/internal/git"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/python"
+	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
+	"os"
+	"strconv"
+)
+
+// OfflineEnv is the environment variable enabling the offline mode
+const OfflineEnv = "EMF_OFFLINE"
+
+var offline bool
+
+// OfflineError is returned when an operation requires the network while the offline mode is enabled
+type OfflineError struct {
+	Operation string
+}
+
+// NewOfflineError creates an error for the operation requiring the network
+func NewOfflineError(operation string) *OfflineError {
+	return &OfflineError{Operation: operation}
+}
+
+func (e *OfflineError) Error() string {
+	return fmt.Sprintf("%s requires network access, which is disabled by the offline mode (--offline or %s)", e.Operation, OfflineEnv)
+}
+
+// Offline returns true if every network call must be skipped
+func Offline() bool {
+	return offline
+}
+
+// SetOffline enables or disables the offline mode
+func SetOffline(value bool) {
+	offline = value
+}
+
+// InitOffline enables the offline mode when requested by the flag or the environment
+func InitOffline(flag bool) {
+	offline = flag || isTruthy(os.Getenv(OfflineEnv))
+}
+
+// isTruthy returns true if the environment variable value enables a setting (i.e. 1, true, yes)
+func isTruthy(value string) bool {
+	if value == "yes" || value == "on" {
+		return true
+	}
+	enabled, err := strconv.ParseBool(value)
+	return err == nil && enabled
+}
+
+// offlineHuggingFace replaces the huggingface instance when offline
+// The responses cached while online are still served, if the cache is enabled.
+type offlineHuggingFace struct {
+	cached huggingface.HuggingFace
+}
+
+func (h offlineHuggingFace) GetModelsByPipelineTag(ctx context.Context, tag huggingface.PipelineTag, limit int, authorizationKey string) (huggingface.Models, error) {
+	if h.cached != nil {
+		if models, err := h.cached.GetModelsByPipelineTag(ctx, tag, limit, authorizationKey); err == nil {
+			return models, nil
+		}
+	}
+	return nil, NewOfflineError("fetching models from huggingface")
+}
+
+func (h offlineHuggingFace) GetModelById(ctx context.Context, id string, authorizationKey string) (huggingface.Model, error) {
+	if h.cached != nil {
+		if model, err := h.cached.GetModelById(ctx, id, authorizationKey); err == nil {
+			return model, nil
+		}
+	}
+	return huggingface.Model{}, NewOfflineError("fetching model " + id + " from huggingface")
+}
+
+func (h offlineHuggingFace) GetModelByRevision(ctx context.Context, id string, revision string, authorizationKey string) (huggingface.Model, error) {
+	if h.cached != nil {
+		if model, err := h.cached.GetModelByRevision(ctx, id, revision, authorizationKey); err == nil {
+			return model, nil
+		}
+	}
+	return huggingface.Model{}, NewOfflineError("fetching model " + id + " from huggingface")
+}
+
+func (h offlineHuggingFace) SearchModels(ctx context.Context, params huggingface.SearchParams, authorizationKey string) (huggingface.Models, error) {
+	if h.cached != nil {
+		if models, err := h.cached.SearchModels(ctx, params, authorizationKey); err == nil {
+			return models, nil
+		}
+	}
+	return nil, NewOfflineError("searching models on huggingface")
+}
+
+func (h offlineHuggingFace) WhoAmI(_ context.Context, _ string) (huggingface.User, error) {
+	return huggingface.User{}, NewOfflineError("validating the access token on huggingface")
+}
+
+// offlineGit replaces the git instance when offline, only the local settings remaining available
+type offlineGit struct {
+	git.Git
+}
+
+func (offlineGit) CheckNewSDKVersion() bool {
+	return false
+}
+
+func (offlineGit) CheckNewCLIVersion() bool {
+	return false
+}
+
+func (offlineGit) GetLatestTag(project string) (string, error) {
+	return "", NewOfflineError("fetching the latest tag of " + project)
+}
+
+func (offlineGit) CloneSDK(_, _ string) error {
+	return NewOfflineError("cloning the sdk")
+}
+
+// offlinePython replaces the python instance when offline, pip being only able to uninstall packages
+type offlinePython struct {
+	python.Python
+}
+
+func (offlinePython) InstallDependencies(_, _ string) error {
+	return NewOfflineError("installing the python dependencies")
+}
+
+func (p offlinePython) ExecutePip(pipPath string, args []string) error {
+	if len(args) > 0 && args[0] == "uninstall" {
+		return p.Python.ExecutePip(pipPath, args)
+	}
+	return NewOfflineError("installing the python dependencies")
+}
+
+// offlineDownloader replaces the downloader when offline
+type offlineDownloader struct{}
+
+func (offlineDownloader) Execute(downloaderArgs downloadermodel.Args, _ python.Python, _ context.Context) (downloadermodel.Model, error) {
+	return downloadermodel.Model{}, NewOfflineError("downloading " + downloaderArgs.ModelName)
+}
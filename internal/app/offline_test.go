@@ -0,0 +1,101 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"github.com/easy-model-fusion/emf-cli/internal/downloader/model"
+	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
+	"github.com/easy-model-fusion/emf-cli/test"
+	"github.com/easy-model-fusion/emf-cli/test/mock"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestInitOffline tests that the offline mode is enabled by the flag or the environment.
+func TestInitOffline(t *testing.T) {
+	defer SetOffline(false)
+
+	t.Setenv(OfflineEnv, "")
+	InitOffline(false)
+	test.AssertEqual(t, Offline(), false)
+	InitOffline(true)
+	test.AssertEqual(t, Offline(), true)
+
+	t.Setenv(OfflineEnv, "1")
+	InitOffline(false)
+	test.AssertEqual(t, Offline(), true)
+
+	t.Setenv(OfflineEnv, "false")
+	InitOffline(false)
+	test.AssertEqual(t, Offline(), false)
+}
+
+// TestOffline_Repositories tests that the network calls fail with an offline error when offline.
+func TestOffline_Repositories(t *testing.T) {
+	// Init
+	SetHuggingFace(&huggingface.MockHuggingFace{})
+	SetGit(&mock.MockGit{Tag: "test-1.0"})
+	SetOffline(true)
+	defer SetOffline(false)
+	var offlineErr *OfflineError
+
+	// Execute
+	_, hfErr := H().GetModelById(context.Background(), "model", "")
+	_, tagErr := G().GetLatestTag("sdk")
+	_, downloadErr := Downloader().Execute(downloadermodel.Args{ModelName: "model"}, nil, context.Background())
+
+	// Assert
+	test.AssertEqual(t, errors.As(hfErr, &offlineErr), true)
+	test.AssertEqual(t, errors.As(tagErr, &offlineErr), true)
+	test.AssertEqual(t, errors.As(downloadErr, &offlineErr), true)
+	test.AssertEqual(t, offlineErr.Operation, "downloading model")
+	test.AssertEqual(t, G().CheckNewSDKVersion(), false)
+}
+
+// TestOffline_Python tests that the python packages can only be uninstalled when offline.
+func TestOffline_Python(t *testing.T) {
+	// Init
+	var pipCommands [][]string
+	SetPython(mock.MockPython{PipCommands: &pipCommands})
+	SetOffline(true)
+	defer SetOffline(false)
+	var offlineErr *OfflineError
+
+	// Execute
+	installErr := Python().InstallDependencies("pip", "requirements.txt")
+	pipErr := Python().ExecutePip("pip", []string{"install", "torch"})
+	uninstallErr := Python().ExecutePip("pip", []string{"uninstall", "-y", "torch"})
+
+	// Assert
+	test.AssertEqual(t, errors.As(installErr, &offlineErr), true)
+	test.AssertEqual(t, errors.As(pipErr, &offlineErr), true)
+	test.AssertEqual(t, offlineErr.Operation, "installing the python dependencies")
+	test.AssertEqual(t, uninstallErr, nil)
+	test.AssertEqual(t, len(pipCommands), 1)
+}
+
+// TestOffline_CachedHuggingFace tests that the responses cached while online are served when offline.
+func TestOffline_CachedHuggingFace(t *testing.T) {
+	// Init
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"modelId":"org/model","library_name":"diffusers"}`))
+	}))
+	defer server.Close()
+	InitHuggingFace(server.URL, "", huggingface.DefaultTimeouts, &huggingface.Cache{Directory: t.TempDir()})
+	defer InitHuggingFace(server.URL, "", huggingface.DefaultTimeouts, nil)
+	_, err := H().GetModelById(context.Background(), "org/model", "")
+	test.AssertEqual(t, err, nil)
+	SetOffline(true)
+	defer SetOffline(false)
+	var offlineErr *OfflineError
+
+	// Execute
+	cachedModel, cachedErr := H().GetModelById(context.Background(), "org/model", "")
+	_, missingErr := H().GetModelById(context.Background(), "org/missing", "")
+
+	// Assert
+	test.AssertEqual(t, cachedErr, nil)
+	test.AssertEqual(t, cachedModel.LibraryName, huggingface.DIFFUSERS)
+	test.AssertEqual(t, errors.As(missingErr, &offlineErr), true)
+}
@@ -4,32 +4,61 @@ import (
 	"github.com/easy-model-fusion/emf-cli/internal/git"
 	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
 	"github.com/pterm/pterm"
+	"net/http"
 )
 
 var huggingFace huggingface.HuggingFace
+var cachedHuggingFace huggingface.HuggingFace
+var downloadClient *http.Client
 var gitInstance git.Git
 var fatal = pterm.Fatal.Println // make it a variable, so we can mock it in tests
 
 // H returns the current huggingface instance
+// Every call fails when offline.
 func H() huggingface.HuggingFace {
+	if offline {
+		return offlineHuggingFace{cached: cachedHuggingFace}
+	}
 	if huggingFace == nil {
 		fatal("HuggingFace is not initialized, please run InitHuggingFace() first.")
 	}
 	return huggingFace
 }
 
+// DownloadClient returns the http client of the file downloads, going through the hub proxy if any
+// Only the connection is bounded by the timeouts, a download taking as long as needed.
+func DownloadClient() *http.Client {
+	if downloadClient == nil {
+		return http.DefaultClient
+	}
+	return downloadClient
+}
+
 // G returns the current git instance
+// The remote calls fail when offline.
 func G() git.Git {
 	if gitInstance == nil {
 		fatal("Git is not initialized, please run InitGit() first.")
 	}
+	if offline {
+		return offlineGit{Git: gitInstance}
+	}
 	return gitInstance
 
 }
 
-// InitHuggingFace Initialize HuggingFace
-func InitHuggingFace(baseUrl, proxyUrl string) {
-	huggingFace = huggingface.NewHuggingFace(baseUrl, proxyUrl)
+// InitHuggingFace Initialize HuggingFace along with the client of the file downloads
+// Given a cache, the api responses are stored to be revalidated, and served as is when offline.
+func InitHuggingFace(baseUrl, proxyUrl string, timeouts huggingface.Timeouts, cache *huggingface.Cache) {
+	downloadClient = huggingface.NewClient(proxyUrl, huggingface.Timeouts{Connect: timeouts.Connect})
+	if cache == nil {
+		huggingFace = huggingface.NewHuggingFace(baseUrl, proxyUrl, timeouts)
+		cachedHuggingFace = nil
+		return
+	}
+	huggingFace = huggingface.NewCachedHuggingFace(baseUrl, proxyUrl, timeouts, cache)
+	cachedHuggingFace = huggingface.NewCachedHuggingFace(baseUrl, proxyUrl, timeouts,
+		&huggingface.Cache{Directory: cache.Directory, TTL: cache.TTL, Only: true})
 }
 
 // SetHuggingFace sets the current hugging face instance
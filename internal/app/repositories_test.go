@@ -5,11 +5,13 @@ import (
 	"github.com/easy-model-fusion/emf-cli/test"
 	"github.com/easy-model-fusion/emf-cli/test/mock"
 	"github.com/pterm/pterm"
+	"net/http"
 	"testing"
+	"time"
 )
 
 func TestInitHuggingFace(t *testing.T) {
-	InitHuggingFace("http://localhost:8080", "")
+	InitHuggingFace("http://localhost:8080", "", huggingface.DefaultTimeouts, nil)
 	test.AssertNotEqual(t, huggingFace, nil, "Should not be nil if huggingface is initialized")
 	test.AssertNotEqual(t, H(), nil, "Should not be nil if huggingface is initialized")
 	huggingFace = nil
@@ -23,6 +25,25 @@ func TestInitHuggingFace(t *testing.T) {
 	test.AssertEqual(t, fatalCalled, true, "Should call the fatal function")
 }
 
+// TestDownloadClient tests that the file downloads go through the hub proxy, only their connection being bounded.
+func TestDownloadClient(t *testing.T) {
+	// Init
+	downloadClient = nil
+	test.AssertEqual(t, DownloadClient(), http.DefaultClient)
+
+	// Execute
+	InitHuggingFace("http://localhost:8080", "http://proxy:3128", huggingface.DefaultTimeouts, nil)
+	client := DownloadClient()
+
+	// Assert
+	request, _ := http.NewRequest("GET", "https://example.com/model.safetensors", nil)
+	proxyUrl, err := client.Transport.(*http.Transport).Proxy(request)
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, proxyUrl.Host, "proxy:3128")
+	test.AssertEqual(t, client.Timeout, time.Duration(0))
+	test.AssertEqual(t, client.Transport.(*http.Transport).TLSHandshakeTimeout, huggingface.DefaultTimeouts.Connect)
+}
+
 func TestInitGit(t *testing.T) {
 	InitGit("http://localhost:8080", "")
 	test.AssertNotEqual(t, gitInstance, nil, "Should not be nil if git is initialized")
@@ -0,0 +1,14 @@
+package app
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// InterruptContext returns a context cancelled once the process is interrupted (i.e. Ctrl-C)
+// The default handling of the signals is restored by stop, which must be called once the operation is over.
+func InterruptContext() (ctx context.Context, stop context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
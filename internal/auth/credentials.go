@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// TokenEnv is the environment variable holding the hugging face access token
+const TokenEnv = huggingface.TokenEnv
+
+// CredentialsFileEnv is the environment variable replacing the path of the credentials file
+const CredentialsFileEnv = "EMF_CREDENTIALS_FILE"
+
+// credentialsFileName is the file storing the access token, inside the user configuration directory
+const credentialsFileName = "credentials.json"
+
+// Sources of an access token, from the highest to the lowest precedence
+const (
+	SourceFlag        = "flag"
+	SourceModel       = "model"
+	SourceEnvironment = "environment"
+	SourceStore       = "store"
+)
+
+// Token represents the access token in use and where it comes from, empty when there is none
+type Token struct {
+	Value  string
+	Source string
+}
+
+// credentials represents the content of the credentials file
+type credentials struct {
+	Token string `json:"token"`
+}
+
+// CredentialsPath returns where the access token is stored
+func CredentialsPath() (string, error) {
+	if path := os.Getenv(CredentialsFileEnv); path != "" {
+		return path, nil
+	}
+	directory, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(directory, app.Name, credentialsFileName), nil
+}
+
+// LoadToken returns the stored access token, empty when none was stored
+func LoadToken() (string, error) {
+	path, err := CredentialsPath()
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+
+	var stored credentials
+	if err = json.Unmarshal(data, &stored); err != nil {
+		return "", fmt.Errorf("invalid credentials file %s, log in again : %w", path, err)
+	}
+	return stored.Token, nil
+}
+
+// SaveToken stores the access token, the credentials file only being readable by the user
+func SaveToken(token string) error {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return errors.New("the access token is empty")
+	}
+	path, err := CredentialsPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(credentials{Token: token}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	// The temporary file is created with 0600 permissions, then replaces the previous credentials atomically
+	temporary, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = temporary.Write(data)
+	if closeErr := temporary.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(temporary.Name(), 0600)
+	}
+	if err == nil {
+		err = os.Rename(temporary.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(temporary.Name())
+	}
+	return err
+}
+
+// RemoveToken removes the stored access token, returning false if none was stored
+func RemoveToken() (bool, error) {
+	path, err := CredentialsPath()
+	if err != nil {
+		return false, err
+	}
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// DefaultToken returns the access token used when none is given for the request nor for the model :
+// the environment variable, otherwise the stored one
+func DefaultToken() (Token, error) {
+	if value := strings.TrimSpace(os.Getenv(TokenEnv)); value != "" {
+		return Token{Value: value, Source: SourceEnvironment}, nil
+	}
+	value, err := LoadToken()
+	if err != nil || value == "" {
+		return Token{}, err
+	}
+	return Token{Value: value, Source: SourceStore}, nil
+}
+
+// ResolveToken returns the access token to use : the flag, then the token of the model, then the default one
+func ResolveToken(flag, modelToken string) (Token, error) {
+	if flag != "" {
+		return Token{Value: flag, Source: SourceFlag}, nil
+	}
+	if modelToken != "" {
+		return Token{Value: modelToken, Source: SourceModel}, nil
+	}
+	return DefaultToken()
+}
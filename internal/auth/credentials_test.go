@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"github.com/easy-model-fusion/emf-cli/test"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// setupCredentials points the credentials file to a temporary directory, without any token in the environment
+func setupCredentials(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "emf-cli", credentialsFileName)
+	t.Setenv(CredentialsFileEnv, path)
+	t.Setenv(TokenEnv, "")
+	return path
+}
+
+// TestSaveToken tests that the access token is stored in a file only readable by the user.
+func TestSaveToken(t *testing.T) {
+	// Init
+	path := setupCredentials(t)
+
+	// Execute
+	err := SaveToken(" hf_token \n")
+
+	// Assert
+	test.AssertEqual(t, err, nil, "The token should've been stored.")
+	token, err := LoadToken()
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, token, "hf_token")
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		test.AssertEqual(t, err, nil)
+		test.AssertEqual(t, info.Mode().Perm(), os.FileMode(0600))
+	}
+
+	// An empty token isn't stored
+	test.AssertNotEqual(t, SaveToken(" "), nil)
+}
+
+// TestLoadToken_Invalid tests that a missing file means no token, while an invalid one is an error.
+func TestLoadToken_Invalid(t *testing.T) {
+	// Init
+	path := setupCredentials(t)
+
+	// Execute
+	token, err := LoadToken()
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, token, "")
+
+	test.AssertEqual(t, os.MkdirAll(filepath.Dir(path), 0700), nil)
+	test.AssertEqual(t, os.WriteFile(path, []byte("invalid"), 0600), nil)
+	_, err = LoadToken()
+	test.AssertNotEqual(t, err, nil)
+}
+
+// TestRemoveToken tests that the stored token is removed, removing it twice not being an error.
+func TestRemoveToken(t *testing.T) {
+	// Init
+	setupCredentials(t)
+	test.AssertEqual(t, SaveToken("hf_token"), nil)
+
+	// Execute
+	removed, err := RemoveToken()
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, removed, true)
+	token, _ := LoadToken()
+	test.AssertEqual(t, token, "")
+
+	removed, err = RemoveToken()
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, removed, false)
+}
+
+// TestResolveToken tests the precedence : flag, then model, then environment, then store.
+func TestResolveToken(t *testing.T) {
+	// Init
+	setupCredentials(t)
+
+	// Execute & Assert
+	token, err := ResolveToken("", "")
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, token, Token{})
+
+	test.AssertEqual(t, SaveToken("stored"), nil)
+	token, _ = ResolveToken("", "")
+	test.AssertEqual(t, token, Token{Value: "stored", Source: SourceStore})
+
+	t.Setenv(TokenEnv, "environment")
+	token, _ = ResolveToken("", "")
+	test.AssertEqual(t, token, Token{Value: "environment", Source: SourceEnvironment})
+
+	token, _ = ResolveToken("", "model")
+	test.AssertEqual(t, token, Token{Value: "model", Source: SourceModel})
+
+	token, _ = ResolveToken("flag", "model")
+	test.AssertEqual(t, token, Token{Value: "flag", Source: SourceFlag})
+}
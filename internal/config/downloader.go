@@ -0,0 +1,31 @@
+package config
+
+import (
+	"fmt"
+	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/easy-model-fusion/emf-cli/internal/downloader"
+	"github.com/spf13/viper"
+)
+
+// Downloaders selectable through the "downloader" key of the configuration file
+const (
+	DownloaderKey    = "downloader"
+	ScriptDownloader = "script"
+	HubDownloader    = "hub"
+)
+
+// ApplyDownloader sets the downloader selected in the configuration file, the script one being the default
+// Both reach the hub through the configured endpoint and proxy.
+func ApplyDownloader() error {
+	settings := GetHubSettings()
+	switch name := viper.GetString(DownloaderKey); name {
+	case "", ScriptDownloader:
+		app.SetDownloader(downloader.NewScriptDownloader(downloader.HubEnv(settings.Endpoint.Value, settings.Proxy.Value)...))
+	case HubDownloader:
+		timeouts, _ := settings.Timeouts()
+		app.SetDownloader(downloader.NewHubDownloader(settings.ApiUrl(), settings.Proxy.Value, timeouts))
+	default:
+		return fmt.Errorf("unknown downloader '%s' in configuration file, expected '%s' or '%s'", name, ScriptDownloader, HubDownloader)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/easy-model-fusion/emf-cli/internal/downloader"
+	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
+	"github.com/easy-model-fusion/emf-cli/test"
+	"github.com/spf13/viper"
+	"reflect"
+	"testing"
+)
+
+// TestApplyDownloader_Default tests that the script downloader is used by default.
+func TestApplyDownloader_Default(t *testing.T) {
+	// Init
+	viper.Reset()
+	app.SetDownloader(nil)
+
+	// Execute
+	err := ApplyDownloader()
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, reflect.TypeOf(app.Downloader()), reflect.TypeOf(downloader.NewScriptDownloader()))
+}
+
+// TestApplyDownloader_Hub tests that the hub downloader is selected through the configuration file.
+func TestApplyDownloader_Hub(t *testing.T) {
+	// Init
+	viper.Reset()
+	viper.Set(DownloaderKey, HubDownloader)
+	app.SetDownloader(nil)
+
+	// Execute
+	err := ApplyDownloader()
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, reflect.TypeOf(app.Downloader()), reflect.TypeOf(downloader.NewHubDownloader(huggingface.BaseUrl, "", huggingface.DefaultTimeouts)))
+}
+
+// TestApplyDownloader_Unknown tests that an unknown downloader is rejected.
+func TestApplyDownloader_Unknown(t *testing.T) {
+	// Init
+	viper.Reset()
+	viper.Set(DownloaderKey, "unknown")
+	app.SetDownloader(nil)
+
+	// Execute
+	err := ApplyDownloader()
+
+	// Assert
+	test.AssertNotEqual(t, err, nil)
+	test.AssertEqual(t, app.Downloader(), nil)
+}
@@ -0,0 +1,164 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
+	"github.com/spf13/viper"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Keys of the configuration file selecting the hub and the proxy used to reach it
+const (
+	HuggingFaceEndpointKey       = "huggingface.endpoint"
+	HuggingFaceProxyKey          = "huggingface.proxy"
+	HuggingFaceTimeoutKey        = "huggingface.timeout"
+	HuggingFaceConnectTimeoutKey = "huggingface.connect-timeout"
+	HuggingFaceCacheTTLKey       = "huggingface.cache-ttl"
+)
+
+// Environment variables bounding the requests sent to the hub, as a duration (i.e. 30s) or a number of seconds
+const (
+	TimeoutEnv        = "EMF_HUB_TIMEOUT"
+	ConnectTimeoutEnv = "EMF_HUB_CONNECT_TIMEOUT"
+	CacheTTLEnv       = "EMF_HUB_CACHE_TTL"
+)
+
+// cacheDirectoryName is the directory of the hub responses, inside the user cache directory
+const cacheDirectoryName = "hub"
+
+// Sources of a setting, the environment taking precedence over the configuration file
+const (
+	SourceEnvironment = "environment"
+	SourceConfig      = "config"
+	SourceDefault     = "default"
+)
+
+// Setting represents the active value of a setting and where it comes from
+type Setting struct {
+	Name   string `json:"name" yaml:"name"`
+	Value  string `json:"value" yaml:"value"`
+	Source string `json:"source" yaml:"source"`
+}
+
+// HubSettings represents how the hub is reached
+type HubSettings struct {
+	Endpoint       Setting `json:"endpoint" yaml:"endpoint"`
+	Proxy          Setting `json:"proxy" yaml:"proxy"`
+	Timeout        Setting `json:"timeout" yaml:"timeout"`
+	ConnectTimeout Setting `json:"connectTimeout" yaml:"connectTimeout"`
+	CacheTTL       Setting `json:"cacheTtl" yaml:"cacheTtl"`
+}
+
+// GetHubSettings returns the hub endpoint and proxy, read from the environment then from the configuration file
+func GetHubSettings() HubSettings {
+	settings := HubSettings{
+		Endpoint: getSetting(HuggingFaceEndpointKey, huggingface.EndpointEnv, huggingface.Endpoint),
+		Proxy:    getSetting(HuggingFaceProxyKey, huggingface.ProxyEnv, ""),
+		Timeout: getSetting(HuggingFaceTimeoutKey, TimeoutEnv,
+			huggingface.DefaultTimeouts.Request.String()),
+		ConnectTimeout: getSetting(HuggingFaceConnectTimeoutKey, ConnectTimeoutEnv,
+			huggingface.DefaultTimeouts.Connect.String()),
+		CacheTTL: getSetting(HuggingFaceCacheTTLKey, CacheTTLEnv,
+			huggingface.DefaultCacheTTL.String()),
+	}
+	settings.Endpoint.Value = strings.TrimSuffix(settings.Endpoint.Value, "/")
+	return settings
+}
+
+// getSetting returns the value of the environment variable, otherwise the value of the configuration file key
+func getSetting(key, env, defaultValue string) Setting {
+	if value := os.Getenv(env); value != "" {
+		return Setting{Name: env, Value: value, Source: SourceEnvironment}
+	}
+	if value := viper.GetString(key); value != "" {
+		return Setting{Name: key, Value: value, Source: SourceConfig}
+	}
+	return Setting{Name: key, Value: defaultValue, Source: SourceDefault}
+}
+
+// ApiUrl returns the url of the hub api
+func (s HubSettings) ApiUrl() string {
+	return huggingface.ApiUrl(s.Endpoint.Value)
+}
+
+// Timeouts returns the timeouts of the requests sent to the hub
+// An invalid timeout is replaced by its default value, the error telling which one.
+func (s HubSettings) Timeouts() (timeouts huggingface.Timeouts, err error) {
+	timeouts = huggingface.DefaultTimeouts
+	var errs []error
+	for _, timeout := range []struct {
+		setting Setting
+		value   *time.Duration
+	}{{s.Timeout, &timeouts.Request}, {s.ConnectTimeout, &timeouts.Connect}} {
+		value, err := parseDuration(timeout.setting.Value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s '%s', expected a duration (i.e. 30s) : the default one is used",
+				timeout.setting.Name, timeout.setting.Value))
+			continue
+		}
+		*timeout.value = value
+	}
+	return timeouts, errors.Join(errs...)
+}
+
+// Cache returns the cache of the hub responses, stored in the user cache directory
+// An invalid TTL is replaced by its default value, the error telling so.
+func (s HubSettings) Cache() (*huggingface.Cache, error) {
+	directory, err := CacheDirectory()
+	if err != nil {
+		return nil, fmt.Errorf("the hub responses can't be cached : %w", err)
+	}
+	cache := &huggingface.Cache{Directory: directory, TTL: huggingface.DefaultCacheTTL}
+	ttl, err := parseDuration(s.CacheTTL.Value)
+	if err != nil {
+		return cache, fmt.Errorf("invalid %s '%s', expected a duration (i.e. 10m) : the default one is used",
+			s.CacheTTL.Name, s.CacheTTL.Value)
+	}
+	cache.TTL = ttl
+	return cache, nil
+}
+
+// CacheDirectory returns where the hub responses are cached
+func CacheDirectory() (string, error) {
+	directory, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(directory, app.Name, cacheDirectoryName), nil
+}
+
+// parseDuration parses a duration (i.e. 30s), a plain number being a number of seconds
+func parseDuration(value string) (time.Duration, error) {
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds >= 0 {
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout < 0 {
+		return 0, fmt.Errorf("invalid duration %s", value)
+	}
+	return timeout, nil
+}
+
+// ApplyHuggingFace initializes the hugging face api with the hub endpoint, proxy and timeouts
+// Unless useCache is false (i.e. --no-cache), the api responses are cached.
+func ApplyHuggingFace(useCache bool) {
+	settings := GetHubSettings()
+	timeouts, err := settings.Timeouts()
+	if err != nil {
+		app.UI().Warning().Println(err.Error())
+	}
+
+	var cache *huggingface.Cache
+	if useCache {
+		if cache, err = settings.Cache(); err != nil {
+			app.UI().Warning().Println(err.Error())
+		}
+	}
+	app.InitHuggingFace(settings.ApiUrl(), settings.Proxy.Value, timeouts, cache)
+}
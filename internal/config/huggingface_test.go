@@ -0,0 +1,120 @@
+package config
+
+import (
+	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
+	"github.com/easy-model-fusion/emf-cli/test"
+	"github.com/spf13/viper"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestGetHubSettings_Default tests that the public hub is reached without proxy by default.
+func TestGetHubSettings_Default(t *testing.T) {
+	// Init
+	viper.Reset()
+	t.Setenv(huggingface.EndpointEnv, "")
+	t.Setenv(huggingface.ProxyEnv, "")
+
+	// Execute
+	settings := GetHubSettings()
+
+	// Assert
+	test.AssertEqual(t, settings.Endpoint.Value, huggingface.Endpoint)
+	test.AssertEqual(t, settings.Endpoint.Source, SourceDefault)
+	test.AssertEqual(t, settings.ApiUrl(), huggingface.BaseUrl)
+	test.AssertEqual(t, settings.Proxy.Value, "")
+}
+
+// TestGetHubSettings_Config tests that the endpoint and proxy are read from the configuration file.
+func TestGetHubSettings_Config(t *testing.T) {
+	// Init
+	viper.Reset()
+	viper.Set(HuggingFaceEndpointKey, "https://mirror.local/")
+	viper.Set(HuggingFaceProxyKey, "http://proxy:3128")
+	t.Setenv(huggingface.EndpointEnv, "")
+	t.Setenv(huggingface.ProxyEnv, "")
+
+	// Execute
+	settings := GetHubSettings()
+
+	// Assert
+	test.AssertEqual(t, settings.Endpoint.Value, "https://mirror.local")
+	test.AssertEqual(t, settings.Endpoint.Source, SourceConfig)
+	test.AssertEqual(t, settings.ApiUrl(), "https://mirror.local/api")
+	test.AssertEqual(t, settings.Proxy.Value, "http://proxy:3128")
+	test.AssertEqual(t, settings.Proxy.Source, SourceConfig)
+}
+
+// TestGetHubSettings_Environment tests that the environment takes precedence over the configuration file.
+func TestGetHubSettings_Environment(t *testing.T) {
+	// Init
+	viper.Reset()
+	viper.Set(HuggingFaceEndpointKey, "https://mirror.local")
+	t.Setenv(huggingface.EndpointEnv, "https://other.local")
+	t.Setenv(huggingface.ProxyEnv, "http://proxy:3128")
+
+	// Execute
+	settings := GetHubSettings()
+
+	// Assert
+	test.AssertEqual(t, settings.Endpoint.Value, "https://other.local")
+	test.AssertEqual(t, settings.Endpoint.Name, huggingface.EndpointEnv)
+	test.AssertEqual(t, settings.Endpoint.Source, SourceEnvironment)
+	test.AssertEqual(t, settings.Proxy.Value, "http://proxy:3128")
+	test.AssertEqual(t, settings.Proxy.Source, SourceEnvironment)
+}
+
+// TestHubSettings_Timeouts tests that the timeouts are read as durations or seconds, the invalid ones being replaced.
+func TestHubSettings_Timeouts(t *testing.T) {
+	// Init
+	viper.Reset()
+	viper.Set(HuggingFaceTimeoutKey, "2m")
+	t.Setenv(TimeoutEnv, "")
+	t.Setenv(ConnectTimeoutEnv, "5")
+
+	// Execute
+	timeouts, err := GetHubSettings().Timeouts()
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, timeouts.Request, 2*time.Minute)
+	test.AssertEqual(t, timeouts.Connect, 5*time.Second)
+
+	// Init
+	t.Setenv(TimeoutEnv, "forever")
+
+	// Execute
+	timeouts, err = GetHubSettings().Timeouts()
+
+	// Assert
+	test.AssertNotEqual(t, err, nil)
+	test.AssertEqual(t, timeouts.Request, huggingface.DefaultTimeouts.Request)
+	test.AssertEqual(t, timeouts.Connect, 5*time.Second)
+}
+
+// TestHubSettings_Cache tests that the responses are cached in the user cache directory for the configured TTL.
+func TestHubSettings_Cache(t *testing.T) {
+	// Init
+	viper.Reset()
+	t.Setenv(CacheTTLEnv, "1h")
+
+	// Execute
+	cache, err := GetHubSettings().Cache()
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, cache.TTL, time.Hour)
+	test.AssertEqual(t, cache.Only, false)
+	test.AssertEqual(t, filepath.Base(cache.Directory), cacheDirectoryName)
+
+	// Init
+	t.Setenv(CacheTTLEnv, "never")
+
+	// Execute
+	cache, err = GetHubSettings().Cache()
+
+	// Assert
+	test.AssertNotEqual(t, err, nil)
+	test.AssertEqual(t, cache.TTL, huggingface.DefaultCacheTTL)
+}
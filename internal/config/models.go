@@ -188,6 +188,7 @@ func RemoveModelsByNames(models model.Models, modelsNamesToRemove []string) (war
 }
 
 // Validate to validate a model before adding it.
+// Nothing is removed : the downloaded version of the model is only replaced once the new one is ready.
 func Validate(current model.Model, yes bool) (warning string, success bool, err error) {
 
 	// Check if model is already configured
@@ -210,23 +211,13 @@ func Validate(current model.Model, yes bool) (warning string, success bool, err
 		return warning, false, err
 	} else if downloaded && !current.AddToBinaryFile {
 		// Model won't be downloaded but a version is already downloaded
+		// It is only removed by the caller once the model is configured, so that a failure keeps it.
 		message := fmt.Sprintf("Model '%s' is already downloaded. Do you wish to delete it?", current.Name)
 		overwrite := yes || app.UI().AskForUsersConfirmation(message)
 		if !overwrite {
 			warning = fmt.Sprintf("This model is already downloaded and should be checked manually %s", current.Name)
 			return warning, false, err
 		}
-
-		// Removing model
-		modelPath := fileutil.PathJoin(app.DownloadDirectoryPath, current.Name)
-		spinner := app.UI().StartSpinner(fmt.Sprintf("Removing item %s...", current.Name))
-		err = RemoveItemPhysically(modelPath)
-		if err != nil {
-			return warning, false, err
-		} else {
-			spinner.Success()
-			return warning, true, err
-		}
 	} else if downloaded {
 		// A version of the model is already downloaded
 		message := fmt.Sprintf("Model '%s' is already downloaded. Do you wish to overwrite it?", current.Name)
@@ -254,6 +245,37 @@ func GenerateExistingModelsPythonCode() error {
 
 // GenerateModelsPythonCode generates the python code for the given models
 func GenerateModelsPythonCode(models model.Models) error {
+	result, err := BuildModelsPythonCode(models)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(ModelsPythonCodePath(), []byte(result), 0644)
+}
+
+// ModelsPythonCodeChanged returns true if the python code generated for the given models differs from the existing one
+func ModelsPythonCodeChanged(models model.Models) (bool, error) {
+	result, err := BuildModelsPythonCode(models)
+	if err != nil {
+		return false, err
+	}
+
+	existing, err := os.ReadFile(ModelsPythonCodePath())
+	if os.IsNotExist(err) {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+	return string(existing) != result, nil
+}
+
+// ModelsPythonCodePath returns the path of the python code generated for the models
+func ModelsPythonCodePath() string {
+	return fileutil.PathJoin("sdk", "generated_models.py")
+}
+
+// BuildModelsPythonCode builds the python code for the given models
+func BuildModelsPythonCode(models model.Models) (string, error) {
 	genFile := &codegen.File{
 		Name: "generated_models.py",
 		HeaderComments: []string{
@@ -287,10 +309,5 @@ func GenerateModelsPythonCode(models model.Models) error {
 	}
 
 	cg := codegen.NewPythonCodeGenerator(true)
-	result, err := cg.Generate(genFile)
-	if err != nil {
-		return err
-	}
-
-	return os.WriteFile(fileutil.PathJoin("sdk", "generated_models.py"), []byte(result), 0644)
+	return cg.Generate(genFile)
 }
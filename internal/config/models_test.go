@@ -10,6 +10,7 @@ import (
 	"github.com/easy-model-fusion/emf-cli/test/mock"
 	"gopkg.in/yaml.v3"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/easy-model-fusion/emf-cli/test"
@@ -266,6 +267,41 @@ func TestAddModel(t *testing.T) {
 	cleanConfDir(t, confDir)
 }
 
+// TestAddModel_Filters tests that the download filters of a model are stored and read back from the config file
+func TestAddModel_Filters(t *testing.T) {
+	// Init
+	confDir, initialConfigFile := setupConfigDir(t)
+	err := setupConfigFile(initialConfigFile, nil, false)
+	test.AssertEqual(t, err, nil, "Error while creating temporary configuration file.")
+	err = Load(confDir)
+	test.AssertEqual(t, err, nil, "Error while loading configuration file.")
+	newModel := getModel(0)
+	newModel.Include = []string{"*.json", "*.safetensors"}
+	newModel.Exclude = []string{"*.bin"}
+	newModel.PreferFormat = "safetensors"
+
+	// Execute
+	err = AddModels(model.Models{newModel})
+	test.AssertEqual(t, err, nil, "Error while updating configuration file.")
+	err = Load(confDir)
+	test.AssertEqual(t, err, nil, "Error while loading configuration file.")
+	models, err := GetModels()
+
+	// Assert
+	test.AssertEqual(t, err, nil, "Error while getting updated models.")
+	test.AssertEqual(t, len(models), 1)
+	test.AssertEqual(t, len(models[0].Include), 2)
+	test.AssertEqual(t, models[0].Include[1], "*.safetensors")
+	test.AssertEqual(t, models[0].Exclude[0], "*.bin")
+	test.AssertEqual(t, models[0].PreferFormat, "safetensors")
+	content, err := os.ReadFile(initialConfigFile)
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, strings.Contains(string(content), "prefer-format: safetensors"), true)
+
+	// Clean up directory afterward
+	cleanConfDir(t, confDir)
+}
+
 // TestErrorOnAddModelWithEmptyViper tests the AddModels function with an empty config file
 func TestAddModelOnEmptyConfFile(t *testing.T) {
 	// Use the setup function
@@ -605,8 +641,8 @@ func TestValidate_DownloadedAndBinaryFalse_ConfirmFalse(t *testing.T) {
 	cleanConfDir(t, confDir)
 }
 
-// TestValidate_DownloadedAndBinaryFalse_ConfirmTrueAndRemove tests the Validate function to invalidate a model that is downloaded.
-func TestValidate_DownloadedAndBinaryFalse_ConfirmTrueAndRemove(t *testing.T) {
+// TestValidate_DownloadedAndBinaryFalse_ConfirmTrue tests the Validate function to validate a downloaded model without removing it yet.
+func TestValidate_DownloadedAndBinaryFalse_ConfirmTrue(t *testing.T) {
 	// Setup config directory
 	confDir, initialConfigFile := setupConfigDir(t)
 	err := setupConfigFile(initialConfigFile, []model.Model{}, false)
@@ -629,7 +665,7 @@ func TestValidate_DownloadedAndBinaryFalse_ConfirmTrueAndRemove(t *testing.T) {
 	modelToValidate.AddToBinaryFile = false
 	modelToValidate.Name = modelName
 
-	// test "no" to the confirmation
+	// test "yes" to the confirmation
 	app.SetUI(&mock.MockUI{})
 	app.UI().(*mock.MockUI).UserConfirmationResult = true
 
@@ -637,12 +673,12 @@ func TestValidate_DownloadedAndBinaryFalse_ConfirmTrueAndRemove(t *testing.T) {
 	_, valid, _ := Validate(modelToValidate, false)
 
 	// Assert
-	exists, err := fileutil.IsExistingPath(modelName)
+	exists, err := fileutil.IsExistingPath(modelPath)
 	if err != nil {
 		t.Fatal(err)
 	}
 	test.AssertEqual(t, true, valid)
-	test.AssertEqual(t, false, exists)
+	test.AssertEqual(t, true, exists)
 
 	// Clean up config afterward
 	cleanConfDir(t, confDir)
@@ -757,6 +793,28 @@ func TestGenerateModelsPythonCode(t *testing.T) {
 	// TODO: implement this
 }
 
+// TestModelsPythonCodeChanged tests that the generated code is only reported as changed when it differs
+func TestModelsPythonCodeChanged(t *testing.T) {
+	// Init
+	ts := test.TestSuite{}
+	_ = ts.CreateFullTestSuite(t)
+	defer ts.CleanTestSuite(t)
+	models := model.Models{{Name: "model", Module: huggingface.DIFFUSERS, Class: "StableDiffusionPipeline",
+		PipelineTag: huggingface.TextToImage}}
+
+	// Execute
+	changedBefore, errBefore := ModelsPythonCodeChanged(models)
+	err := GenerateModelsPythonCode(models)
+	changedAfter, errAfter := ModelsPythonCodeChanged(models)
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, errBefore, nil)
+	test.AssertEqual(t, errAfter, nil)
+	test.AssertEqual(t, changedBefore, true)
+	test.AssertEqual(t, changedAfter, false)
+}
+
 func TestGenerateExistingModelsPythonCode(t *testing.T) {
 	// TODO: implement this
 }
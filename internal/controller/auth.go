@@ -0,0 +1,139 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/easy-model-fusion/emf-cli/internal/auth"
+	"github.com/easy-model-fusion/emf-cli/internal/hfinterface"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/outpututil"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/stringutil"
+	"os"
+	"strings"
+)
+
+type AuthController struct {
+	Output string
+	Token  string
+}
+
+// AuthStatus represents the access token in use and what it grants
+type AuthStatus struct {
+	LoggedIn        bool     `json:"loggedIn" yaml:"loggedIn"`
+	Source          string   `json:"source,omitempty" yaml:"source,omitempty"`
+	CredentialsFile string   `json:"credentialsFile" yaml:"credentialsFile"`
+	Token           string   `json:"token,omitempty" yaml:"token,omitempty"`
+	User            string   `json:"user,omitempty" yaml:"user,omitempty"`
+	Email           string   `json:"email,omitempty" yaml:"email,omitempty"`
+	Orgs            []string `json:"orgs,omitempty" yaml:"orgs,omitempty"`
+	TokenName       string   `json:"tokenName,omitempty" yaml:"tokenName,omitempty"`
+	Role            string   `json:"role,omitempty" yaml:"role,omitempty"`
+	Scopes          []string `json:"scopes,omitempty" yaml:"scopes,omitempty"`
+}
+
+// RunLogin validates the access token against the hub, then stores it for the next commands
+func (ac AuthController) RunLogin() error {
+	token := strings.TrimSpace(ac.Token)
+	if token == "" {
+		token = strings.TrimSpace(app.UI().AskForUsersSecret("Enter your hugging face access token"))
+	}
+	if token == "" {
+		return errors.New("no access token was provided")
+	}
+
+	// Validating the token before storing it
+	ctx, stop := app.InterruptContext()
+	user, err := hfinterface.WhoAmI(ctx, token)
+	stop()
+	if err != nil {
+		return err
+	}
+
+	if err = auth.SaveToken(token); err != nil {
+		return fmt.Errorf("the access token couldn't be stored : %w", err)
+	}
+	path, _ := auth.CredentialsPath()
+	app.UI().Success().Printfln("Logged in as %s, the access token being stored in %s", user.Name, path)
+	if os.Getenv(auth.TokenEnv) != "" {
+		app.UI().Warning().Printfln("%s is set and takes precedence over the stored access token", auth.TokenEnv)
+	}
+	return nil
+}
+
+// RunStatus validates the access token in use against the hub and displays what it grants
+func (ac AuthController) RunStatus() error {
+	// Validate the output format before doing anything
+	format, err := outpututil.ParseFormat(ac.Output)
+	if err != nil {
+		return err
+	}
+
+	status, err := BuildAuthStatus()
+	if err != nil {
+		return err
+	}
+	if format.IsStructured() {
+		return outpututil.Write(os.Stdout, format, status)
+	}
+
+	if !status.LoggedIn {
+		app.UI().Info().Printfln("Not logged in : run '%s auth login' or set %s", app.Name, auth.TokenEnv)
+		return nil
+	}
+	app.UI().DisplayTable([][]string{
+		{"Setting", "Value"},
+		{"User", status.User},
+		{"Email", status.Email},
+		{"Organizations", strings.Join(status.Orgs, ", ")},
+		{"Token", status.Token},
+		{"Token name", status.TokenName},
+		{"Token source", status.Source},
+		{"Role", status.Role},
+		{"Scopes", strings.Join(status.Scopes, "\n")},
+		{"Credentials file", status.CredentialsFile},
+	})
+	return nil
+}
+
+// BuildAuthStatus gathers the access token in use, from the environment then from the store, and validates it
+func BuildAuthStatus() (status AuthStatus, err error) {
+	status.CredentialsFile, _ = auth.CredentialsPath()
+	token, err := auth.DefaultToken()
+	if err != nil || token.Value == "" {
+		return status, err
+	}
+	status.LoggedIn = true
+	status.Source = token.Source
+	status.Token = stringutil.MaskSecret(token.Value)
+
+	ctx, stop := app.InterruptContext()
+	user, err := hfinterface.WhoAmI(ctx, token.Value)
+	stop()
+	if err != nil {
+		return status, fmt.Errorf("%w (access token from the %s)", err, token.Source)
+	}
+	status.User = user.Name
+	status.Email = user.Email
+	status.Orgs = user.OrgNames()
+	status.TokenName = user.Auth.AccessToken.DisplayName
+	status.Role = user.Auth.AccessToken.Role
+	status.Scopes = user.Auth.AccessToken.Scopes()
+	return status, nil
+}
+
+// RunLogout removes the stored access token
+func (ac AuthController) RunLogout() error {
+	removed, err := auth.RemoveToken()
+	if err != nil {
+		return fmt.Errorf("the access token couldn't be removed : %w", err)
+	}
+	if removed {
+		app.UI().Success().Println("Logged out, the stored access token was removed")
+	} else {
+		app.UI().Info().Println("No access token was stored")
+	}
+	if os.Getenv(auth.TokenEnv) != "" {
+		app.UI().Warning().Printfln("%s is still set and keeps being used as the access token", auth.TokenEnv)
+	}
+	return nil
+}
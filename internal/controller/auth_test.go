@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/easy-model-fusion/emf-cli/internal/auth"
+	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
+	"github.com/easy-model-fusion/emf-cli/test"
+	"github.com/easy-model-fusion/emf-cli/test/mock"
+	"path/filepath"
+	"testing"
+)
+
+// setupAuth stores the credentials in a temporary directory, without any token in the environment
+func setupAuth(t *testing.T) {
+	t.Setenv(auth.CredentialsFileEnv, filepath.Join(t.TempDir(), "credentials.json"))
+	t.Setenv(auth.TokenEnv, "")
+	app.SetUI(&mock.MockUI{})
+}
+
+// TestAuthController_RunLogin tests that a valid token is stored while an invalid one isn't.
+func TestAuthController_RunLogin(t *testing.T) {
+	// Init
+	setupAuth(t)
+	app.SetHuggingFace(&huggingface.MockHuggingFace{Error: &huggingface.APIError{Err: huggingface.ErrUnauthorized}})
+
+	// Execute
+	err := AuthController{Token: "invalid"}.RunLogin()
+
+	// Assert
+	test.AssertNotEqual(t, err, nil)
+	token, _ := auth.LoadToken()
+	test.AssertEqual(t, token, "")
+
+	app.SetHuggingFace(&huggingface.MockHuggingFace{WhoAmIResult: huggingface.User{Name: "user"}})
+	err = AuthController{Token: "hf_valid_token"}.RunLogin()
+	test.AssertEqual(t, err, nil)
+	token, _ = auth.LoadToken()
+	test.AssertEqual(t, token, "hf_valid_token")
+}
+
+// TestBuildAuthStatus tests that the token in use is reported with its source and scopes.
+func TestBuildAuthStatus(t *testing.T) {
+	// Init
+	setupAuth(t)
+	app.SetHuggingFace(&huggingface.MockHuggingFace{WhoAmIResult: huggingface.User{
+		Name: "user",
+		Auth: huggingface.Auth{AccessToken: huggingface.TokenInfo{DisplayName: "cli", Role: "read"}},
+	}})
+
+	// Execute
+	status, err := BuildAuthStatus()
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, status.LoggedIn, false)
+
+	test.AssertEqual(t, auth.SaveToken("hf_stored_token"), nil)
+	status, err = BuildAuthStatus()
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, status.LoggedIn, true)
+	test.AssertEqual(t, status.Source, auth.SourceStore)
+	test.AssertEqual(t, status.Token, "hf_********oken")
+	test.AssertEqual(t, status.User, "user")
+	test.AssertEqual(t, status.Scopes[0], "read")
+
+	t.Setenv(auth.TokenEnv, "hf_environment")
+	status, _ = BuildAuthStatus()
+	test.AssertEqual(t, status.Source, auth.SourceEnvironment)
+}
+
+// TestAuthController_RunLogout tests that the stored token is removed.
+func TestAuthController_RunLogout(t *testing.T) {
+	// Init
+	setupAuth(t)
+	test.AssertEqual(t, auth.SaveToken("hf_stored_token"), nil)
+
+	// Execute
+	err := AuthController{}.RunLogout()
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	token, _ := auth.LoadToken()
+	test.AssertEqual(t, token, "")
+	test.AssertEqual(t, AuthController{}.RunLogout(), nil)
+}
@@ -148,7 +148,7 @@ func (bc BuildController) Build(libraryPath string) (err error) {
 	buildArgs := bc.createBuildArgs()
 
 	app.UI().Info().Println(fmt.Sprintf("Building project using %s...", bc.Library))
-	app.UI().Info().Println(fmt.Sprintf("Using the following arguments: %s", buildArgs))
+	app.UI().Info().Println(fmt.Sprintf("Using the following arguments: %s", stringutil.RedactArgs(buildArgs)))
 	app.UI().Info().Println(fmt.Sprintf("The project will be built to %s", bc.DestinationDir))
 
 	// Setup signal catching
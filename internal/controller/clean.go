@@ -42,6 +42,11 @@ func RunClean(allFlagDelete bool, authorizeAllDelete bool) {
 			spinner.Fail(fmt.Sprintf("Error cleaning all models: %s", err))
 		}
 
+		// The interrupted downloads are discarded as well
+		if err = os.RemoveAll(app.StagingDirectoryPath); err != nil {
+			app.UI().Warning().Printfln("Error cleaning the interrupted downloads: %s", err)
+		}
+
 	}
 
 	_, err := os.Stat(cleanDirName)
@@ -0,0 +1,88 @@
+package controller
+
+import (
+	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/easy-model-fusion/emf-cli/internal/config"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/outpututil"
+	"github.com/spf13/viper"
+	"net/url"
+	"os"
+	"strconv"
+)
+
+type EnvController struct {
+	Output string
+}
+
+// EnvReport represents the settings in use, to diagnose how the hub is reached
+type EnvReport struct {
+	Version        string         `json:"version" yaml:"version"`
+	ConfigPath     string         `json:"configPath" yaml:"configPath"`
+	Project        bool           `json:"project" yaml:"project"`
+	Offline        bool           `json:"offline" yaml:"offline"`
+	Downloader     string         `json:"downloader" yaml:"downloader"`
+	Endpoint       config.Setting `json:"endpoint" yaml:"endpoint"`
+	ApiUrl         string         `json:"apiUrl" yaml:"apiUrl"`
+	Proxy          config.Setting `json:"proxy" yaml:"proxy"`
+	Timeout        config.Setting `json:"timeout" yaml:"timeout"`
+	ConnectTimeout config.Setting `json:"connectTimeout" yaml:"connectTimeout"`
+	CacheTTL       config.Setting `json:"cacheTtl" yaml:"cacheTtl"`
+	CacheDirectory string         `json:"cacheDirectory" yaml:"cacheDirectory"`
+}
+
+// Run runs the env command
+func (ec EnvController) Run() error {
+	// Validate the output format before doing anything
+	format, err := outpututil.ParseFormat(ec.Output)
+	if err != nil {
+		return err
+	}
+
+	report := BuildEnvReport()
+	if format.IsStructured() {
+		return outpututil.Write(os.Stdout, format, report)
+	}
+
+	app.UI().DisplayTable([][]string{
+		{"Setting", "Value", "Source"},
+		{"Version", report.Version, ""},
+		{"Configuration file", report.ConfigPath, "project: " + strconv.FormatBool(report.Project)},
+		{"Offline", strconv.FormatBool(report.Offline), "--offline / " + app.OfflineEnv},
+		{"Downloader", report.Downloader, config.DownloaderKey},
+		{"Hub endpoint", report.Endpoint.Value, report.Endpoint.Source + " (" + report.Endpoint.Name + ")"},
+		{"Hub api", report.ApiUrl, ""},
+		{"Proxy", report.Proxy.Value, report.Proxy.Source + " (" + report.Proxy.Name + ")"},
+		{"Request timeout", report.Timeout.Value, report.Timeout.Source + " (" + report.Timeout.Name + ")"},
+		{"Connect timeout", report.ConnectTimeout.Value, report.ConnectTimeout.Source + " (" + report.ConnectTimeout.Name + ")"},
+		{"Cache TTL", report.CacheTTL.Value, report.CacheTTL.Source + " (" + report.CacheTTL.Name + ")"},
+		{"Cache directory", report.CacheDirectory, "--no-cache to bypass"},
+	})
+	return nil
+}
+
+// BuildEnvReport gathers the settings in use, the credentials of the proxy being redacted
+func BuildEnvReport() EnvReport {
+	report := EnvReport{
+		Version:    app.Version,
+		ConfigPath: config.FilePath,
+		Project:    config.Load(config.FilePath) == nil,
+		Offline:    app.Offline(),
+		Downloader: viper.GetString(config.DownloaderKey),
+	}
+	if report.Downloader == "" {
+		report.Downloader = config.ScriptDownloader
+	}
+
+	settings := config.GetHubSettings()
+	report.Endpoint = settings.Endpoint
+	report.ApiUrl = settings.ApiUrl()
+	report.Proxy = settings.Proxy
+	report.Timeout = settings.Timeout
+	report.ConnectTimeout = settings.ConnectTimeout
+	report.CacheTTL = settings.CacheTTL
+	report.CacheDirectory, _ = config.CacheDirectory()
+	if proxyUrl, err := url.Parse(report.Proxy.Value); err == nil && report.Proxy.Value != "" {
+		report.Proxy.Value = proxyUrl.Redacted()
+	}
+	return report
+}
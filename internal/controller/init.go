@@ -226,7 +226,7 @@ func (ic InitController) cloneSDK(projectName, tag string) (err error) {
 		tag, err = app.G().GetLatestTag("sdk")
 		if err != nil {
 			spinner.Fail(fmt.Sprintf("Error checking for latest sdk version: %s", err))
-			return errors.New("error checking for latest sdk version")
+			return fmt.Errorf("error checking for latest sdk version: %w", err)
 		}
 		spinner.Success("Using latest sdk version: " + tag)
 	}
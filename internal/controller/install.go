@@ -14,10 +14,12 @@
 // Here the install controller comes into play:
 // - It will create a virtual environment
 // - Clone the configured sdk version
+// - Install the python packages locked in the lockfile (emf.lock) if any, pinning the other dependencies to them
 // - Install the dependencies (requirements.txt & sdk/requirements.txt)
 // - Install torch with cuda if the user wants to
 // - Download the missing models
 // - Generate the needed python code
+// - Verify the installed python packages and the downloaded files against the lockfile if any
 //
 // In the end, the user should have a fully working project that should look like this:
 // cloned-project/
@@ -36,16 +38,34 @@ import (
 	"fmt"
 	"github.com/easy-model-fusion/emf-cli/internal/app"
 	"github.com/easy-model-fusion/emf-cli/internal/config"
+	"github.com/easy-model-fusion/emf-cli/internal/lockfile"
+	"github.com/easy-model-fusion/emf-cli/internal/plan"
 	"github.com/easy-model-fusion/emf-cli/internal/utils/fileutil"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/outpututil"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/python"
 	"github.com/spf13/viper"
 	"os"
+	"slices"
+	"strings"
 	"time"
 )
 
-type InstallController struct{}
+type InstallController struct {
+	// Frozen fails instead of updating the lockfile when the project doesn't match it
+	Frozen bool
+	// Jobs is the maximum number of models downloaded at the same time
+	Jobs int
+	// DryRun only displays what would be changed, using the Output format
+	DryRun bool
+	Output string
+}
 
 // Run runs the install command
 func (ic InstallController) Run(args []string, useTorchCuda bool, accessToken string) error {
+	if ic.DryRun {
+		return ic.runPlan(useTorchCuda, accessToken)
+	}
+
 	start := time.Now()
 
 	// Only clean if config file exists (so we know it's a EMF project)
@@ -74,8 +94,13 @@ func (ic InstallController) Run(args []string, useTorchCuda bool, accessToken st
 		return err
 	}
 
+	// Check that the locked packages were installed
+	if err := ic.verifyLockedPackages(); err != nil {
+		return err
+	}
+
 	// handle errors in run tidy (new structure)
-	if err := tidyController.RunTidy(false, accessToken); err != nil {
+	if err := (TidyController{Frozen: ic.Frozen, Jobs: ic.Jobs}).RunTidy(false, accessToken); err != nil {
 		return err
 	}
 
@@ -84,6 +109,50 @@ func (ic InstallController) Run(args []string, useTorchCuda bool, accessToken st
 	return nil
 }
 
+// runPlan displays what the installation would change, without installing anything
+func (ic InstallController) runPlan(useTorchCuda bool, accessToken string) error {
+	format, err := outpututil.ParseFormat(ic.Output)
+	if err != nil {
+		app.UI().Error().Println(err.Error())
+		return err
+	}
+
+	result, err := ic.buildPlan(useTorchCuda, accessToken)
+	if err != nil {
+		app.UI().Error().Println(err.Error())
+		return err
+	}
+
+	return result.Display(format)
+}
+
+// buildPlan computes the setup steps of the project followed by the changes tidy would make to the models
+func (ic InstallController) buildPlan(useTorchCuda bool, accessToken string) (result plan.Plan, err error) {
+	result, err = (TidyController{Frozen: ic.Frozen}).buildPlan(false, accessToken)
+	if err != nil {
+		return result, err
+	}
+
+	var setup []string
+	for _, directory := range []string{"sdk", "models"} {
+		if exists, _ := fileutil.IsExistingPath(directory); !exists {
+			setup = append(setup, fmt.Sprintf("Create the %s directory", directory))
+		}
+	}
+	setup = append(setup, fmt.Sprintf("Clone the sdk (%s)", viper.GetString("sdk-tag")))
+	if _, err = app.Python().FindVEnvExecutable(".venv", "python"); err != nil {
+		setup = append(setup, "Create the virtual environment .venv")
+	}
+	setup = append(setup, "Install the dependencies of sdk/requirements.txt")
+	if useTorchCuda {
+		setup = append(setup, "Install torch with cuda")
+	}
+	setup = append(setup, "Install the dependencies of requirements.txt")
+	result.Setup = append(setup, result.Setup...)
+
+	return result, nil
+}
+
 // createMissingDirectories creates the missing directories (sdk, models)
 func (ic InstallController) createMissingDirectories() (err error) {
 	spinner := app.UI().StartSpinner("Creating missing directories")
@@ -141,8 +210,18 @@ func (ic InstallController) installDependencies(pythonPath string, useTorchCuda
 		return err
 	}
 
-	// First install the sdk dependencies
-	err = app.Python().InstallDependencies(pipPath, "sdk/requirements.txt")
+	// The packages locked in the lockfile are installed first, then constrain the versions of the requirements
+	constraintsPath, localConstraints, err := ic.installLockedPackages(pipPath)
+	if constraintsPath != "" {
+		defer os.Remove(constraintsPath)
+	}
+	if err != nil {
+		spinner.Fail("Unable to install locked packages: ", err)
+		return err
+	}
+
+	// Then install the sdk dependencies
+	err = ic.installRequirements(pipPath, "sdk/requirements.txt", constraintsPath)
 	if err != nil {
 		spinner.Fail("Unable to install sdk dependencies: ", err)
 		return err
@@ -157,17 +236,32 @@ func (ic InstallController) installDependencies(pythonPath string, useTorchCuda
 			return err
 		}
 
-		err = app.Python().ExecutePip(pipPath, []string{"install", "torch", "-f", app.TorchCudaURL})
+		// The packages locked at a local version (i.e. torch==2.2.0+cu121) are only available from the torch cuda index
+		torchArgs := append([]string{"install"}, localConstraints...)
+		if !slices.ContainsFunc(localConstraints, func(constraint string) bool {
+			return strings.HasPrefix(constraint, "torch==")
+		}) {
+			torchArgs = append(torchArgs, "torch")
+		}
+		torchArgs = append(torchArgs, "-f", app.TorchCudaURL)
+		if constraintsPath != "" {
+			torchArgs = append(torchArgs, "-c", constraintsPath)
+		}
+		err = app.Python().ExecutePip(pipPath, torchArgs)
 		if err != nil {
 			spinner.Fail("Unable to install torch cuda: ", err)
 			return err
 		}
 		spinner.Success()
+	} else {
+		for _, constraint := range localConstraints {
+			app.UI().Warning().Printfln("The locked package %s is only available with torch cuda : it was not installed", constraint)
+		}
 	}
 
 	// Install the project dependencies
 	spinner = app.UI().StartSpinner("Installing project dependencies")
-	err = app.Python().InstallDependencies(pipPath, "requirements.txt")
+	err = ic.installRequirements(pipPath, "requirements.txt", constraintsPath)
 	if err != nil {
 		spinner.Warning("Unable to install project dependencies: ", err)
 	} else {
@@ -177,6 +271,71 @@ func (ic InstallController) installDependencies(pythonPath string, useTorchCuda
 	return nil
 }
 
+// installRequirements installs the requirements file, constrained by the locked packages if any
+func (ic InstallController) installRequirements(pipPath, path, constraintsPath string) error {
+	if constraintsPath == "" {
+		return app.Python().InstallDependencies(pipPath, path)
+	}
+	return app.Python().ExecutePip(pipPath, []string{"install", "-r", path, "-c", constraintsPath})
+}
+
+// installLockedPackages installs the python packages locked in the lockfile, written to a temporary pip constraints file
+// An empty path is returned when no package is locked, otherwise the caller removes the file once installed.
+// The packages locked at a local version are returned instead of being installed, since they require their own index.
+func (ic InstallController) installLockedPackages(pipPath string) (constraintsPath string, localConstraints []string, err error) {
+	lock, exists, err := lockfile.Read()
+	if err != nil || !exists {
+		return "", nil, err
+	}
+
+	localConstraints = lock.LocalConstraints()
+	constraints := lock.Constraints()
+	if len(constraints) == 0 {
+		return "", localConstraints, nil
+	}
+
+	file, err := os.CreateTemp("", "emf-constraints-*.txt")
+	if err != nil {
+		return "", nil, err
+	}
+	_, err = file.WriteString(strings.Join(constraints, "\n") + "\n")
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(file.Name())
+		return "", nil, err
+	}
+
+	return file.Name(), localConstraints, app.Python().ExecutePip(pipPath, []string{"install", "-r", file.Name()})
+}
+
+// verifyLockedPackages checks that the python packages installed in the virtual environment match the lockfile
+// A difference (i.e. a locked package unavailable on this platform) is only a warning unless the installation is frozen
+func (ic InstallController) verifyLockedPackages() error {
+	lock, exists, err := lockfile.Read()
+	if err != nil || !exists {
+		return err
+	}
+
+	installed, err := python.GetInstalledPackages(lockfile.GetVenvPath())
+	if err != nil {
+		return err
+	}
+
+	differences := lock.VerifyPackages(installed)
+	if len(differences) == 0 {
+		return nil
+	}
+	if ic.Frozen {
+		return fmt.Errorf("the installed packages don't match the lockfile :\n - %s", strings.Join(differences, "\n - "))
+	}
+	for _, difference := range differences {
+		app.UI().Warning().Println(difference)
+	}
+	return nil
+}
+
 // cloneSDK clones the sdk into the project
 func (ic InstallController) cloneSDK() (err error) {
 	// Get sdk tag
@@ -190,6 +349,12 @@ clone:
 	if err != nil {
 		spinner.Fail("Unable to clone sdk: ", err)
 
+		// Retrying is pointless when offline
+		var offlineErr *app.OfflineError
+		if errors.As(err, &offlineErr) {
+			return err
+		}
+
 		if !retry && app.UI().AskForUsersConfirmation("Do you want to remove the sdk folder and try again?") {
 
 			// Remove sdk folder
@@ -3,9 +3,13 @@ package controller
 import (
 	"errors"
 	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/easy-model-fusion/emf-cli/internal/config"
+	"github.com/easy-model-fusion/emf-cli/internal/lockfile"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/fileutil"
 	"github.com/easy-model-fusion/emf-cli/test"
 	"github.com/easy-model-fusion/emf-cli/test/mock"
 	"os"
+	"slices"
 	"strings"
 	"testing"
 )
@@ -74,6 +78,37 @@ func TestInstallController_Run(t *testing.T) {
 	test.AssertEqual(t, err, nil, "Error should be nil")
 }
 
+// TestInstallController_Run_DryRun tests that the dry run only plans the installation.
+func TestInstallController_Run_DryRun(t *testing.T) {
+	// Init
+	mockPython := &mock.MockPython{
+		CalledFunctions:         make(map[string]int),
+		FindVEnvExecutableError: errors.New("not found"),
+	}
+	app.SetUI(&mock.MockUI{})
+	app.SetPython(mockPython)
+	app.SetGit(&mock.MockGit{})
+	ic := InstallController{DryRun: true, Output: "table"}
+	ts := test.TestSuite{}
+	_ = ts.CreateFullTestSuite(t)
+	defer ts.CleanTestSuite(t)
+
+	// Execute
+	result, err := ic.buildPlan(true, "")
+	runErr := ic.Run([]string{}, true, "")
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, runErr, nil)
+	test.AssertEqual(t, result.Setup[0], "Create the models directory")
+	test.AssertEqual(t, slices.Contains(result.Setup, "Create the virtual environment .venv"), true)
+	test.AssertEqual(t, slices.Contains(result.Setup, "Install torch with cuda"), true)
+	test.AssertEqual(t, mockPython.CalledFunctions["InstallDependencies"], 0)
+	test.AssertEqual(t, mockPython.CalledFunctions["CreateVirtualEnv"], 0)
+	exists, _ := fileutil.IsExistingPath("models")
+	test.AssertEqual(t, exists, false)
+}
+
 func TestInstallController_createMissingDirectories(t *testing.T) {
 	app.SetUI(&mock.MockUI{})
 	ic := InstallController{}
@@ -216,3 +251,82 @@ func TestInstallController_cloneSDKConfirm_retry(t *testing.T) {
 	test.AssertNotEqual(t, err, nil, "Error should not be nil")
 	test.AssertEqual(t, err.Error(), "error cloning sdk", "Error should be 'error cloning sdk'")
 }
+
+// TestInstallController_installDependencies_Locked tests that the locked packages are installed before the requirements,
+// which are constrained by them.
+func TestInstallController_installDependencies_Locked(t *testing.T) {
+	// Init
+	var pipCommands [][]string
+	mockPython := &mock.MockPython{CalledFunctions: make(map[string]int), PipCommands: &pipCommands}
+	app.SetUI(&mock.MockUI{})
+	app.SetPython(mockPython)
+	ts := test.TestSuite{}
+	_ = ts.CreateFullTestSuite(t)
+	defer ts.CleanTestSuite(t)
+	config.FilePath = "."
+	err := lockfile.Write(lockfile.Lock{Packages: map[string]string{"torch": "2.2.0+cu121", "diffusers": "0.27.0", "pip": "24.0"}})
+	test.AssertEqual(t, err, nil)
+
+	// Execute
+	err = InstallController{}.installDependencies("python", true)
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, mockPython.CalledFunctions["InstallDependencies"], 0)
+	test.AssertEqual(t, len(pipCommands), 5)
+	test.AssertEqual(t, strings.Join(pipCommands[0][:2], " "), "install -r")
+	constraintsPath := pipCommands[0][2]
+	test.AssertEqual(t, strings.Join(pipCommands[1], " "), "install -r sdk/requirements.txt -c "+constraintsPath)
+	test.AssertEqual(t, strings.Join(pipCommands[3], " "), "install torch==2.2.0+cu121 -f "+app.TorchCudaURL+" -c "+constraintsPath)
+	test.AssertEqual(t, strings.Join(pipCommands[4], " "), "install -r requirements.txt -c "+constraintsPath)
+	exists, _ := fileutil.IsExistingPath(constraintsPath)
+	test.AssertEqual(t, exists, false, "The constraints file should've been removed.")
+}
+
+// TestInstallController_installDependencies_LocalVersion tests that the packages locked at a local version
+// are not installed without torch cuda, since they are only available from its index.
+func TestInstallController_installDependencies_LocalVersion(t *testing.T) {
+	// Init
+	var pipCommands [][]string
+	mockPython := &mock.MockPython{CalledFunctions: make(map[string]int), PipCommands: &pipCommands}
+	app.SetUI(&mock.MockUI{})
+	app.SetPython(mockPython)
+	ts := test.TestSuite{}
+	_ = ts.CreateFullTestSuite(t)
+	defer ts.CleanTestSuite(t)
+	config.FilePath = "."
+	err := lockfile.Write(lockfile.Lock{Packages: map[string]string{"torch": "2.2.0+cu121"}})
+	test.AssertEqual(t, err, nil)
+
+	// Execute
+	err = InstallController{}.installDependencies("python", false)
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, len(pipCommands), 0)
+	test.AssertEqual(t, mockPython.CalledFunctions["InstallDependencies"], 2)
+}
+
+// TestInstallController_installDependencies_Offline tests that pip isn't run against the network when offline.
+func TestInstallController_installDependencies_Offline(t *testing.T) {
+	// Init
+	var pipCommands [][]string
+	mockPython := &mock.MockPython{CalledFunctions: make(map[string]int), PipCommands: &pipCommands}
+	app.SetUI(&mock.MockUI{})
+	app.SetPython(mockPython)
+	app.SetOffline(true)
+	defer app.SetOffline(false)
+	ts := test.TestSuite{}
+	_ = ts.CreateFullTestSuite(t)
+	defer ts.CleanTestSuite(t)
+	var offlineErr *app.OfflineError
+
+	// Execute
+	err := InstallController{}.installDependencies("python", false)
+
+	// Assert
+	test.AssertEqual(t, errors.As(err, &offlineErr), true)
+	test.AssertEqual(t, offlineErr.Operation, "installing the python dependencies")
+	test.AssertEqual(t, mockPython.CalledFunctions["InstallDependencies"], 0)
+	test.AssertEqual(t, len(pipCommands), 0)
+}
@@ -1,27 +1,44 @@
 package modelcontroller
 
 import (
+	"errors"
 	"fmt"
 	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/easy-model-fusion/emf-cli/internal/auth"
 	"github.com/easy-model-fusion/emf-cli/internal/config"
 	"github.com/easy-model-fusion/emf-cli/internal/downloader/model"
 	"github.com/easy-model-fusion/emf-cli/internal/hfinterface"
+	"github.com/easy-model-fusion/emf-cli/internal/lockfile"
 	"github.com/easy-model-fusion/emf-cli/internal/model"
 	"github.com/easy-model-fusion/emf-cli/internal/sdk"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/fileutil"
 	"github.com/easy-model-fusion/emf-cli/internal/utils/resultutil"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/stringutil"
 	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
 	"os"
+	"path/filepath"
+	"strings"
 )
 
 type AddController struct {
 	AuthorizeDownload bool
 	SingleFile        bool
+	ManifestPath      string
+	PipelineTag       string
+	Sha256            string
 }
 
 // Run runs the add command to add models by name
 func (ac AddController) Run(args []string, customArgs downloadermodel.Args) error {
 	sdk.SendUpdateSuggestion()
 
+	// Several models requested : processing them as a batch
+	if ac.ManifestPath != "" || len(args) > 1 {
+		result := ac.processAddBatch(args, customArgs)
+		result.Display("Operation succeeded", "Operation failed")
+		return result.Error
+	}
+
 	selectedModel, err := ac.getRequestedModel(args, customArgs.AccessToken)
 	if err != nil {
 		return err
@@ -46,15 +63,17 @@ func (ac AddController) getRequestedModel(args []string, authorizationKey string
 		return model.Model{}, err
 	}
 
-	// Get all existing models
-	existingModels, err := config.GetModels()
+	// Without the flag, the models are fetched with the default access token
+	token, err := auth.ResolveToken(authorizationKey, "")
 	if err != nil {
 		return model.Model{}, err
 	}
+	authorizationKey = token.Value
 
-	// Verify if the user entered more than one argument
-	if len(args) > 1 {
-		return model.Model{}, fmt.Errorf("you can enter only one model at a time")
+	// Get all existing models
+	existingModels, err := config.GetModels()
+	if err != nil {
+		return model.Model{}, err
 	}
 
 	// Single file option is only available with a given model name
@@ -64,34 +83,7 @@ func (ac AddController) getRequestedModel(args []string, authorizationKey string
 
 	// Add models passed in args
 	if len(args) == 1 {
-		name := args[0]
-		// Verify if model already exists in the project
-		exist := existingModels.ContainsByName(name)
-		if exist {
-			// Model already exists
-			return model.Model{}, fmt.Errorf("the following model already exist and will be ignored : %s", name)
-		}
-
-		// In case of single file, we don't need to check if the model is valid
-		if ac.SingleFile {
-			selectedModel = model.Model{
-				Name:            name,
-				IsDownloaded:    true,
-				Source:          model.CUSTOM,
-				AddToBinaryFile: true,
-			}
-			return selectedModel, nil
-		}
-
-		// Verify if the model is a valid hugging face model
-		hfModel, err := hfinterface.GetModelById(name, authorizationKey)
-		if err != nil {
-			// Model not found
-			return model.Model{}, fmt.Errorf("Model %s not valid : "+err.Error(), name)
-		}
-
-		// Map API response to model.Model
-		selectedModel = model.FromHuggingfaceModel(hfModel)
+		return ac.getModelByName(args[0], existingModels, authorizationKey)
 	} else {
 		// If no models entered by user or if user entered -s/--select
 		// Get selected tags
@@ -112,12 +104,179 @@ func (ac AddController) getRequestedModel(args []string, authorizationKey string
 	return selectedModel, nil
 }
 
+// getModelByName returns the model matching the given name
+func (ac AddController) getModelByName(name string, existingModels model.Models, authorizationKey string) (model.Model, error) {
+	// Single file models requested through a URL are named after it
+	if ac.SingleFile && model.IsURL(name) {
+		urlModel, err := model.FromSingleFileURL(name)
+		if err != nil {
+			return model.Model{}, err
+		}
+		if existingModels.ContainsByName(urlModel.Name) {
+			return model.Model{}, fmt.Errorf("the following model already exist and will be ignored : %s", urlModel.Name)
+		}
+		return urlModel, nil
+	}
+
+	// Verify if model already exists in the project
+	exist := existingModels.ContainsByName(name)
+	if exist {
+		// Model already exists
+		return model.Model{}, fmt.Errorf("the following model already exist and will be ignored : %s", name)
+	}
+
+	// In case of single file, we don't need to check if the model is valid
+	if ac.SingleFile {
+		return model.Model{
+			Name:            name,
+			Path:            name,
+			IsDownloaded:    true,
+			Source:          model.CUSTOM,
+			AddToBinaryFile: true,
+		}, nil
+	}
+
+	// Verify if the model is a valid hugging face model
+	ctx, stop := app.InterruptContext()
+	defer stop()
+	hfModel, err := hfinterface.GetModelById(ctx, name, authorizationKey)
+	if err != nil {
+		// The hub errors already tell what to do about them
+		var apiErr *huggingface.APIError
+		if errors.As(err, &apiErr) {
+			return model.Model{}, err
+		}
+		return model.Model{}, fmt.Errorf("Model %s not valid : "+err.Error(), name)
+	}
+
+	// Map API response to model.Model
+	return model.FromHuggingfaceModel(hfModel), nil
+}
+
 // processAdd processes the selected model and tries to add it
 func (ac AddController) processAdd(selectedModel model.Model, customArgs downloadermodel.Args) (warnings []string, err error) {
-	var updatedModel model.Model
+	updatedModel, warnings, valid, err := ac.prepareModel(selectedModel, customArgs)
+	if !valid || err != nil {
+		return warnings, err
+	}
+
+	return warnings, ac.saveModels(model.Models{updatedModel})
+}
+
+// processAddBatch processes the models requested through args and through the manifest file
+// A model failing to be added doesn't prevent the others from being processed
+func (ac AddController) processAddBatch(args []string, customArgs downloadermodel.Args) (result resultutil.ExecutionResult) {
+	err := config.GetViperConfig(config.FilePath)
+	if err != nil {
+		result.SetError(err)
+		return result
+	}
+
+	// Get all existing models
+	existingModels, err := config.GetModels()
+	if err != nil {
+		result.SetError(err)
+		return result
+	}
+
+	// Gather the requests : the models from args share the command flags
+	var requests []downloadermodel.Args
+	for _, name := range stringutil.SliceRemoveDuplicates(args) {
+		request := customArgs
+		request.ModelName = name
+		requests = append(requests, request)
+	}
+	if ac.ManifestPath != "" {
+		manifest, err := loadManifest(ac.ManifestPath)
+		if err != nil {
+			result.SetError(err)
+			return result
+		}
+		requests = append(requests, manifest.ToArgs(customArgs)...)
+	}
+
+	// Processing each requested model
+	var addedModels model.Models
+	var failedModels []string
+	for _, request := range requests {
+		// Model requested twice : only the first request is kept
+		if addedModels.ContainsByName(request.ModelName) || stringutil.SliceContainsItem(failedModels, request.ModelName) {
+			result.AddWarnings([]string{fmt.Sprintf("Model '%s' was requested several times, "+
+				"only the first request was processed", request.ModelName)})
+			continue
+		}
+
+		token, err := auth.ResolveToken(request.AccessToken, "")
+		if err != nil {
+			result.SetError(err)
+			return result
+		}
+		// The models added by the previous requests are checked as well, since different URLs may name the same model
+		selectedModel, err := ac.getModelByName(request.ModelName, append(existingModels, addedModels...), token.Value)
+		if err != nil {
+			result.AddWarnings([]string{err.Error()})
+			failedModels = append(failedModels, request.ModelName)
+			continue
+		}
+
+		updatedModel, warnings, valid, err := ac.prepareModel(selectedModel, request)
+		result.AddWarnings(warnings)
+		if err != nil {
+			result.AddWarnings([]string{err.Error()})
+			failedModels = append(failedModels, request.ModelName)
+			continue
+		}
+		if valid {
+			addedModels = append(addedModels, updatedModel)
+		}
+	}
+
+	// Adding all the models at once
+	if len(addedModels) > 0 {
+		err = ac.saveModels(addedModels)
+		if err != nil {
+			result.SetError(err)
+			return result
+		}
+		result.AddInfos([]string{fmt.Sprintf("The following model(s) were added : %s", addedModels.GetNames())})
+	}
+
+	// Displaying the models that failed
+	if len(failedModels) > 0 {
+		result.SetError(fmt.Errorf("the following model(s) couldn't be added : %s", failedModels))
+	}
+
+	return result
+}
+
+// prepareModel validates and downloads the selected model so that it can be added to the configuration file
+// The model isn't valid when it shouldn't be added, without it being an error
+func (ac AddController) prepareModel(selectedModel model.Model, customArgs downloadermodel.Args) (
+	updatedModel model.Model, warnings []string, valid bool, err error) {
 
 	// Download model is only available for model.Source == huggingface
 	if selectedModel.Source == model.HUGGING_FACE {
+		// Without the flag, the model is downloaded with the default access token, which isn't saved for the model
+		token, err := auth.ResolveToken(customArgs.AccessToken, "")
+		if err != nil {
+			return updatedModel, warnings, false, err
+		}
+		downloaderArgs := customArgs
+		downloaderArgs.AccessToken = token.Value
+
+		// Resolve the requested revision to its commit sha, the model staying pinned to it on update
+		if customArgs.Revision != "" {
+			ctx, stop := app.InterruptContext()
+			hfModel, err := hfinterface.GetModelByRevision(ctx, selectedModel.Name, customArgs.Revision, downloaderArgs.AccessToken)
+			stop()
+			if err != nil {
+				return updatedModel, warnings, false, fmt.Errorf("revision %s of model %s not valid : %s", customArgs.Revision, selectedModel.Name, err)
+			}
+			selectedModel.Revision = hfModel.Sha
+			selectedModel.Version = hfModel.LastModified
+			selectedModel.Pinned = true
+		}
+
 		// User choose if he wishes to install the model directly
 		message := fmt.Sprintf("Do you wish to directly download %s?", selectedModel.Name)
 		selectedModel.AddToBinaryFile = !customArgs.OnlyConfiguration && (ac.AuthorizeDownload || app.UI().AskForUsersConfirmation(message))
@@ -128,13 +287,23 @@ func (ac AddController) processAdd(selectedModel model.Model, customArgs downloa
 			warnings = append(warnings, warningMessage)
 		}
 		if !valid {
-			return warnings, err
+			return updatedModel, warnings, false, err
 		}
 
 		// Try to download model
-		updatedModel, warnings, err = ac.downloadModel(selectedModel, customArgs)
+		var downloadWarnings []string
+		updatedModel, downloadWarnings, err = ac.downloadModel(selectedModel, downloaderArgs)
+		warnings = append(warnings, downloadWarnings...)
 		if err != nil {
-			return warnings, err
+			return updatedModel, warnings, false, err
+		}
+
+		// Model only configured : removing the version downloaded beforehand, as confirmed during the validation
+		if !updatedModel.AddToBinaryFile {
+			modelPath := fileutil.PathJoin(app.DownloadDirectoryPath, updatedModel.Name)
+			if err = config.RemoveItemPhysically(modelPath); err != nil {
+				warnings = append(warnings, fmt.Sprintf("Failed to remove the downloaded version of %s : %s", updatedModel.Name, err))
+			}
 		}
 
 		// Save access token
@@ -145,44 +314,132 @@ func (ac AddController) processAdd(selectedModel model.Model, customArgs downloa
 			}
 		}
 	} else if selectedModel.Source == model.CUSTOM && ac.SingleFile {
+		updatedModel, warnings, err = ac.prepareSingleFileModel(selectedModel, customArgs)
+		if err != nil {
+			return updatedModel, warnings, false, err
+		}
+	} else {
+		return updatedModel, warnings, false, fmt.Errorf("model source %s is not supported", selectedModel.Source)
+	}
+
+	return updatedModel, warnings, true, nil
+}
+
+// prepareSingleFileModel configures a single file model
+// The module, class and pipeline tag are inferred from the file header when they aren't given
+func (ac AddController) prepareSingleFileModel(selectedModel model.Model, customArgs downloadermodel.Args) (
+	updatedModel model.Model, warnings []string, err error) {
 
-		// We need some information to create a single file model
-		if customArgs.ModelClass == "" {
-			return warnings, fmt.Errorf("model class is required for single file model")
+	if ac.PipelineTag != "" && !stringutil.SliceContainsItem(huggingface.AllTagsString(), ac.PipelineTag) {
+		return updatedModel, warnings, fmt.Errorf("invalid pipeline tag '%s', expected one of %s", ac.PipelineTag,
+			stringutil.SliceToArgsFormat(huggingface.AllTagsString()))
+	}
+
+	// Single file requested through a URL : downloading it unless already done
+	selectedModel.Sha256 = ac.Sha256
+	verified := false
+	if selectedModel.URL != "" {
+		exists, err := fileutil.IsExistingPath(selectedModel.Path)
+		if err != nil {
+			return updatedModel, warnings, err
 		}
-		if customArgs.ModelModule == "" {
-			return warnings, fmt.Errorf("model module is required for single file model")
-		} else if customArgs.ModelModule != string(huggingface.DIFFUSERS) {
-			return warnings, fmt.Errorf("currently only diffusers models are supported for single file model")
+		if !exists {
+			ctx, stop := app.InterruptContext()
+			err = selectedModel.DownloadSingleFile(ctx)
+			stop()
+			if err != nil {
+				return updatedModel, warnings, err
+			}
+			verified = true
+		}
+	}
+
+	// For a single file model to work, we need to check if the file exists
+	fi, err := os.Stat(selectedModel.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return updatedModel, warnings, fmt.Errorf("file %s does not exist", selectedModel.Path)
 		}
+		return updatedModel, warnings, fmt.Errorf("error while checking file %s: %s", selectedModel.Path, err)
+	}
+	if fi.IsDir() {
+		return updatedModel, warnings, fmt.Errorf("file %s is a directory", selectedModel.Path)
+	}
 
-		// For a single file model to work, we need to check if the file exists
-		fi, err := os.Stat(selectedModel.Path)
+	// Verify the checksum of the file when requested
+	if ac.Sha256 != "" && !verified {
+		sum, err := fileutil.FileSha256(selectedModel.Path)
 		if err != nil {
-			if os.IsNotExist(err) {
-				return warnings, fmt.Errorf("file %s does not exist", selectedModel.Path)
-			}
-			return warnings, fmt.Errorf("error while checking file %s: %s", selectedModel.Path, err)
+			return updatedModel, warnings, err
 		}
-		if fi.IsDir() {
-			return warnings, fmt.Errorf("file %s is a directory", selectedModel.Path)
+		if !strings.EqualFold(sum, ac.Sha256) {
+			return updatedModel, warnings, fmt.Errorf("checksum mismatch for %s : expected %s but got %s", selectedModel.Path, ac.Sha256, sum)
 		}
+	}
+
+	// Inferring what wasn't given from the file header
+	info, err := model.InferSingleFile(selectedModel.Path)
+	if err != nil {
+		warnings = append(warnings, fmt.Sprintf("Couldn't read the header of %s : %s", selectedModel.Path, err))
+	}
 
-		app.UI().Warning().Println("Please note that the file extension is not checked, it could lead to errors if the file is not a valid single file.")
+	// The given values always take precedence over the inferred ones
+	selectedModel.Module = huggingface.Module(customArgs.ModelModule)
+	if selectedModel.Module == "" {
+		selectedModel.Module = info.Module
+	}
+	if selectedModel.Module == "" {
+		return updatedModel, warnings, fmt.Errorf("model module is required for single file model")
+	} else if selectedModel.Module != huggingface.DIFFUSERS && selectedModel.Module != huggingface.TRANSFORMERS {
+		return updatedModel, warnings, fmt.Errorf("module %s is not supported for single file model", selectedModel.Module)
+	}
 
-		selectedModel.Class = customArgs.ModelClass
-		selectedModel.Module = huggingface.Module(customArgs.ModelModule)
-		selectedModel.PipelineTag = huggingface.TextToImage // FIXME: should not be hardcoded
-		updatedModel = selectedModel
-	} else {
-		return warnings, fmt.Errorf("model source %s is not supported", selectedModel.Source)
+	selectedModel.Class = customArgs.ModelClass
+	if selectedModel.Class == "" && info.Module == selectedModel.Module {
+		selectedModel.Class = info.Class
+	}
+	if selectedModel.Class == "" {
+		return updatedModel, warnings, fmt.Errorf("model class is required for single file model")
 	}
 
+	selectedModel.PipelineTag = huggingface.PipelineTag(ac.PipelineTag)
+	if selectedModel.PipelineTag == "" && info.Module == selectedModel.Module {
+		selectedModel.PipelineTag = info.PipelineTag
+	}
+	if selectedModel.PipelineTag == "" {
+		if selectedModel.Module == huggingface.DIFFUSERS {
+			selectedModel.PipelineTag = model.InferDiffusersPipelineTag(selectedModel.Class)
+		} else {
+			selectedModel.PipelineTag = model.InferTransformersPipelineTag(selectedModel.Class)
+		}
+		warnings = append(warnings, fmt.Sprintf("Pipeline tag of %s inferred as %s from its class, use --pipeline-tag to change it",
+			selectedModel.Name, selectedModel.PipelineTag))
+	}
+
+	// Transformers loads the single file and its tokenizer from their directory
+	if selectedModel.Module == huggingface.TRANSFORMERS {
+		tokenizerClass := customArgs.TokenizerClass
+		if tokenizerClass == "" {
+			tokenizerClass = "AutoTokenizer"
+		}
+		directory := fileutil.PathUniformize(filepath.Dir(selectedModel.Path))
+		selectedModel.Tokenizers = model.Tokenizers{{Class: tokenizerClass, Path: directory}}
+		if !selectedModel.IsGGUF() {
+			warnings = append(warnings, fmt.Sprintf("Transformers loads %s from %s : its config.json and tokenizer files must be stored alongside it",
+				selectedModel.Name, directory))
+		}
+	}
+
+	return selectedModel, warnings, nil
+}
+
+// saveModels adds the models to the configuration file and generates the python code
+func (ac AddController) saveModels(models model.Models) (err error) {
 	// Add models to configuration file
-	spinner := app.UI().StartSpinner("Adding model to configuration file...")
-	err = config.AddModels(model.Models{updatedModel})
+	spinner := app.UI().StartSpinner("Adding model(s) to configuration file...")
+	err = config.AddModels(models)
 	if err != nil {
-		spinner.Fail(fmt.Sprintf("Error while adding the model to the configuration file: %s", err))
+		spinner.Fail(fmt.Sprintf("Error while adding the model(s) to the configuration file: %s", err))
 	} else {
 		spinner.Success()
 	}
@@ -196,7 +453,15 @@ func (ac AddController) processAdd(selectedModel model.Model, customArgs downloa
 		spinner.Success()
 	}
 
-	return warnings, err
+	// Record what was resolved : the configuration remains usable even if it fails
+	spinner = app.UI().StartSpinner("Updating lockfile...")
+	if lockErr := lockfile.Refresh(); lockErr != nil {
+		spinner.Warning(fmt.Sprintf("Error while updating the lockfile: %s", lockErr))
+	} else {
+		spinner.Success()
+	}
+
+	return err
 }
 
 // downloadModel tries to download the selected model
@@ -209,6 +474,15 @@ func (ac AddController) downloadModel(selectedModel model.Model, downloaderArgs
 	if downloaderArgs.ModelModule == "" {
 		downloaderArgs.ModelModule = string(selectedModel.Module)
 	}
+	if selectedModel.Revision != "" {
+		// Pin the download to the resolved commit sha
+		downloaderArgs.Revision = selectedModel.Revision
+	}
+
+	// Keep the filters so that tidy and update download the same files
+	selectedModel.Include = downloaderArgs.Include
+	selectedModel.Exclude = downloaderArgs.Exclude
+	selectedModel.PreferFormat = downloaderArgs.PreferFormat
 
 	var success bool
 	if selectedModel.AddToBinaryFile {
@@ -228,7 +502,9 @@ func (ac AddController) downloadModel(selectedModel model.Model, downloaderArgs
 
 // getModelsList get list of models to display
 func (ac AddController) getModelsList(tags []string, existingModels model.Models, authorizationKey string) (model.Models, error) {
-	allModelsWithTags, err := hfinterface.GetModelsByMultiplePipelineTags(tags, authorizationKey)
+	ctx, stop := app.InterruptContext()
+	allModelsWithTags, err := hfinterface.GetModelsByMultiplePipelineTags(ctx, tags, authorizationKey)
+	stop()
 	// Map API responses to model.Models
 	var mappedModels model.Models
 	for _, huggingfaceModel := range allModelsWithTags {
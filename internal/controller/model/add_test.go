@@ -11,6 +11,8 @@ import (
 	"github.com/easy-model-fusion/emf-cli/test"
 	"github.com/easy-model-fusion/emf-cli/test/dmock"
 	"github.com/easy-model-fusion/emf-cli/test/mock"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 )
@@ -430,14 +432,14 @@ func TestGetRequestedModel_WithNoArgsAndNoTags(t *testing.T) {
 	test.AssertEqual(t, requestedModel.Name, "")
 }
 
-// Tests getRequestedModel with more than 1 argument
-func TestGetRequestedModel_WithTooManyArgs(t *testing.T) {
+// Tests processAddBatch with several models passed in arguments
+func TestProcessAddBatch_WithMultipleArgs(t *testing.T) {
 	// Init
-	ac := AddController{}
+	ac := AddController{AuthorizeDownload: true}
 	var existingModels model.Models
-	existingModels = append(existingModels, model.Model{Name: "model1"})
-	existingModels = append(existingModels, model.Model{Name: "model3"})
-	args := []string{"model2", "model4"}
+	existingModels = append(existingModels, model.Model{Name: "model1", PipelineTag: huggingface.TextToImage, Module: huggingface.DIFFUSERS, Source: model.HUGGING_FACE, Class: "test"})
+	existingModels = append(existingModels, model.Model{Name: "model3", PipelineTag: huggingface.TextToImage, Module: huggingface.DIFFUSERS, Source: model.HUGGING_FACE, Class: "test"})
+	args := []string{"model2", "model4", "model2"}
 
 	// Create full test suite with a configuration file
 	ts := test.TestSuite{}
@@ -446,11 +448,90 @@ func TestGetRequestedModel_WithTooManyArgs(t *testing.T) {
 	err := setupConfigFile(existingModels)
 	test.AssertEqual(t, err, nil, "No error expected on setting configuration file")
 
-	// Get requested model
-	_, err = ac.getRequestedModel(args, "")
+	// Create huggingface and downloader mocks
+	huggingfaceInterface := huggingface.MockHuggingFace{GetModelResult: huggingface.Model{LibraryName: huggingface.DIFFUSERS, PipelineTag: huggingface.TextToImage}}
+	app.SetHuggingFace(&huggingfaceInterface)
+	downloader := dmock.MockDownloader{DownloaderModel: downloadermodel.Model{Module: "diffusers", Class: "test"}}
+	app.SetDownloader(&downloader)
+
+	// Process add
+	result := ac.processAddBatch(args, downloadermodel.Args{})
+	models, err := config.GetModels()
+
+	// Assertions
+	test.AssertEqual(t, result.Error, nil)
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, len(models), 4)
+	test.AssertEqual(t, models.ContainsByName("model2"), true)
+	test.AssertEqual(t, models.ContainsByName("model4"), true)
+}
+
+// Tests processAddBatch with a failing model not preventing the others from being added
+func TestProcessAddBatch_WithFailingModel(t *testing.T) {
+	// Init
+	ac := AddController{AuthorizeDownload: true}
+	var existingModels model.Models
+	existingModels = append(existingModels, model.Model{Name: "model1", PipelineTag: huggingface.TextToImage, Module: huggingface.DIFFUSERS, Source: model.HUGGING_FACE, Class: "test"})
+	args := []string{"model1", "model2"}
+
+	// Create full test suite with a configuration file
+	ts := test.TestSuite{}
+	_ = ts.CreateFullTestSuite(t)
+	defer ts.CleanTestSuite(t)
+	err := setupConfigFile(existingModels)
+	test.AssertEqual(t, err, nil, "No error expected on setting configuration file")
+
+	// Create huggingface and downloader mocks
+	huggingfaceInterface := huggingface.MockHuggingFace{GetModelResult: huggingface.Model{LibraryName: huggingface.DIFFUSERS, PipelineTag: huggingface.TextToImage}}
+	app.SetHuggingFace(&huggingfaceInterface)
+	downloader := dmock.MockDownloader{DownloaderModel: downloadermodel.Model{Module: "diffusers", Class: "test"}}
+	app.SetDownloader(&downloader)
+
+	// Process add
+	result := ac.processAddBatch(args, downloadermodel.Args{})
+	models, err := config.GetModels()
+
+	// Assertions
+	test.AssertNotEqual(t, result.Error, nil)
+	test.AssertEqual(t, result.Error.Error(), "the following model(s) couldn't be added : [model1]")
+	test.AssertEqual(t, len(result.Warnings), 1)
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, len(models), 2)
+	test.AssertEqual(t, models.ContainsByName("model2"), true)
+}
+
+// Tests processAddBatch with models coming from a manifest file
+func TestProcessAddBatch_WithManifest(t *testing.T) {
+	// Init
+	ac := AddController{AuthorizeDownload: true, ManifestPath: "models.yaml"}
+	var existingModels model.Models
+	existingModels = append(existingModels, model.Model{Name: "model1", PipelineTag: huggingface.TextToImage, Module: huggingface.DIFFUSERS, Source: model.HUGGING_FACE, Class: "test"})
+
+	// Create full test suite with a configuration file
+	ts := test.TestSuite{}
+	_ = ts.CreateFullTestSuite(t)
+	defer ts.CleanTestSuite(t)
+	err := setupConfigFile(existingModels)
+	test.AssertEqual(t, err, nil, "No error expected on setting configuration file")
+	err = os.WriteFile("models.yaml", []byte("models:\n  - name: model2\n  - name: model3\n    only-configuration: true\n"), 0644)
+	test.AssertEqual(t, err, nil)
+
+	// Create huggingface and downloader mocks
+	huggingfaceInterface := huggingface.MockHuggingFace{GetModelResult: huggingface.Model{LibraryName: huggingface.DIFFUSERS, PipelineTag: huggingface.TextToImage}}
+	app.SetHuggingFace(&huggingfaceInterface)
+	downloader := dmock.MockDownloader{DownloaderModel: downloadermodel.Model{Module: "diffusers", Class: "test"}}
+	app.SetDownloader(&downloader)
+
+	// Process add
+	result := ac.processAddBatch([]string{}, downloadermodel.Args{})
+	models, err := config.GetModels()
 
 	// Assertions
-	test.AssertEqual(t, err.Error(), "you can enter only one model at a time")
+	test.AssertEqual(t, result.Error, nil)
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, len(models), 3)
+	test.AssertEqual(t, models.Map()["model2"].AddToBinaryFile, true)
+	test.AssertEqual(t, models.Map()["model3"].AddToBinaryFile, false)
 }
 
 // Tests getRequestedModel with invalid configuration path
@@ -472,7 +553,8 @@ func TestGetRequestedModel_WithInvalidConfigPath(t *testing.T) {
 func TestProcessAdd_SingleFile(t *testing.T) {
 	// Init
 	ac := AddController{
-		SingleFile: true,
+		SingleFile:  true,
+		PipelineTag: string(huggingface.TextToImage),
 	}
 	var existingModels model.Models
 	existingModels = append(existingModels, model.Model{Name: "model1", PipelineTag: huggingface.TextToImage, Module: huggingface.DIFFUSERS, Source: model.HUGGING_FACE, Class: "test"})
@@ -491,8 +573,8 @@ func TestProcessAdd_SingleFile(t *testing.T) {
 	err := setupConfigFile(existingModels)
 	test.AssertEqual(t, err, nil, "No error expected on setting configuration file")
 
-	// create a sample file
-	err = os.WriteFile("test.safetensors", []byte("test"), 0644)
+	// create a sample file with an empty safetensors header
+	err = os.WriteFile("test.safetensors", []byte{2, 0, 0, 0, 0, 0, 0, 0, '{', '}'}, 0644)
 	test.AssertEqual(t, err, nil)
 
 	// Process add
@@ -534,43 +616,110 @@ func TestProcessAdd_SingleFileMissingParams(t *testing.T) {
 	test.AssertEqual(t, err.Error(), "model source notexistingsource is not supported")
 
 	selectedModel.Source = model.CUSTOM
+	selectedModel.Path = "test.safetensors"
 
-	// test ModelClass missing
+	// test file not found
 	_, err = ac.processAdd(selectedModel, downloaderArgs)
 	test.AssertNotEqual(t, err, nil)
-	test.AssertEqual(t, err.Error(), "model class is required for single file model")
+	test.AssertEqual(t, err.Error(), "file test.safetensors does not exist")
+
+	// test file is folder
+	err = os.Mkdir("test.safetensors", 0755)
+	test.AssertEqual(t, err, nil)
+	_, err = ac.processAdd(selectedModel, downloaderArgs)
+	test.AssertNotEqual(t, err, nil)
+	test.AssertEqual(t, err.Error(), "file test.safetensors is a directory")
 
-	downloaderArgs.ModelClass = "test"
+	// create a file whose header can't be used to infer anything
+	selectedModel.Path = "test.bin"
+	err = os.WriteFile("test.bin", []byte("test"), 0644)
+	test.AssertEqual(t, err, nil)
 
 	// test ModelModule missing
 	_, err = ac.processAdd(selectedModel, downloaderArgs)
 	test.AssertNotEqual(t, err, nil)
 	test.AssertEqual(t, err.Error(), "model module is required for single file model")
 
-	downloaderArgs.ModelModule = string(huggingface.TRANSFORMERS)
+	downloaderArgs.ModelModule = "unknown"
 
 	// test wrong ModelModule
 	_, err = ac.processAdd(selectedModel, downloaderArgs)
 	test.AssertNotEqual(t, err, nil)
-	test.AssertEqual(t, err.Error(), "currently only diffusers models are supported for single file model")
+	test.AssertEqual(t, err.Error(), "module unknown is not supported for single file model")
 
-	downloaderArgs.ModelModule = string(huggingface.DIFFUSERS)
-	selectedModel.Path = "test.safetensors"
+	downloaderArgs.ModelModule = string(huggingface.TRANSFORMERS)
 
-	// test file not found
+	// test ModelClass missing
 	_, err = ac.processAdd(selectedModel, downloaderArgs)
 	test.AssertNotEqual(t, err, nil)
-	test.AssertEqual(t, err.Error(), "file test.safetensors does not exist")
+	test.AssertEqual(t, err.Error(), "model class is required for single file model")
 
-	// test file is folder
-	err = os.Mkdir("test.safetensors", 0755)
-	test.AssertEqual(t, err, nil)
+	// test wrong pipeline tag
+	ac.PipelineTag = "unknown"
 	_, err = ac.processAdd(selectedModel, downloaderArgs)
 	test.AssertNotEqual(t, err, nil)
-	test.AssertEqual(t, err.Error(), "file test.safetensors is a directory")
+}
+
+// Tests process add for a transformers GGUF single file
+func TestProcessAdd_SingleFileGGUF(t *testing.T) {
+	// Init
+	ac := AddController{
+		SingleFile: true,
+	}
+	selectedModel := model.Model{Name: "model.gguf", Path: "model.gguf", Source: model.CUSTOM, IsDownloaded: true}
+
+	// Create full test suite with a configuration file
+	ts := test.TestSuite{}
+	_ = ts.CreateFullTestSuite(t)
+	defer ts.CleanTestSuite(t)
+	err := setupConfigFile(model.Models{})
+	test.AssertEqual(t, err, nil, "No error expected on setting configuration file")
+	err = os.WriteFile("model.gguf", []byte("GGUF0000"), 0644)
+	test.AssertEqual(t, err, nil)
+
+	// Process add
+	warnings, err := ac.processAdd(selectedModel, downloadermodel.Args{})
+	test.AssertEqual(t, err, nil)
+	models, err := config.GetModels()
 
 	// Assertions
-	//Name: "model2", PipelineTag: huggingface.TextToImage, Module: huggingface.DIFFUSERS, Source: model.CUSTOM, Class: "test", Path: "test.safetensors"
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, len(warnings), 0)
+	test.AssertEqual(t, len(models), 1)
+	test.AssertEqual(t, models[0].Module, huggingface.TRANSFORMERS)
+	test.AssertEqual(t, models[0].Class, "AutoModelForCausalLM")
+	test.AssertEqual(t, models[0].PipelineTag, huggingface.TextGeneration)
+	test.AssertEqual(t, models[0].Tokenizers[0].Class, "AutoTokenizer")
+	test.AssertEqual(t, models[0].Tokenizers[0].Path, ".")
+}
+
+// Tests that the given pipeline tag takes precedence over the inferred one
+func TestProcessAdd_SingleFilePipelineTag(t *testing.T) {
+	// Init
+	ac := AddController{
+		SingleFile:  true,
+		PipelineTag: string(huggingface.ImageToImage),
+	}
+	selectedModel := model.Model{Name: "model.bin", Path: "model.bin", Source: model.CUSTOM, IsDownloaded: true}
+	downloaderArgs := downloadermodel.Args{ModelModule: string(huggingface.DIFFUSERS), ModelClass: "StableDiffusionPipeline"}
+
+	// Create full test suite with a configuration file
+	ts := test.TestSuite{}
+	_ = ts.CreateFullTestSuite(t)
+	defer ts.CleanTestSuite(t)
+	err := setupConfigFile(model.Models{})
+	test.AssertEqual(t, err, nil, "No error expected on setting configuration file")
+	err = os.WriteFile("model.bin", []byte("test"), 0644)
+	test.AssertEqual(t, err, nil)
+
+	// Process add
+	_, err = ac.processAdd(selectedModel, downloaderArgs)
+	test.AssertEqual(t, err, nil)
+	models, err := config.GetModels()
+
+	// Assertions
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, models[0].PipelineTag, huggingface.ImageToImage)
 }
 
 // Tests process add
@@ -776,3 +925,72 @@ func TestAddController_Run_WithInvalidModel(t *testing.T) {
 	test.AssertEqual(t, err, nil)
 	test.AssertEqual(t, len(models), 2)
 }
+
+// Tests process add for a single file requested through a URL
+func TestProcessAdd_SingleFileURL(t *testing.T) {
+	// Init
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("GGUF0000"))
+	}))
+	defer server.Close()
+	ac := AddController{
+		SingleFile: true,
+		Sha256:     "invalid",
+	}
+
+	// Create full test suite with a configuration file
+	ts := test.TestSuite{}
+	_ = ts.CreateFullTestSuite(t)
+	defer ts.CleanTestSuite(t)
+	err := setupConfigFile(model.Models{})
+	test.AssertEqual(t, err, nil, "No error expected on setting configuration file")
+	selectedModel, err := ac.getModelByName(server.URL+"/llm.gguf", model.Models{}, "")
+	test.AssertEqual(t, err, nil)
+
+	// Checksum mismatch
+	_, err = ac.processAdd(selectedModel, downloadermodel.Args{})
+	test.AssertNotEqual(t, err, nil)
+
+	// Process add
+	ac.Sha256 = ""
+	_, err = ac.processAdd(selectedModel, downloadermodel.Args{})
+	test.AssertEqual(t, err, nil)
+	models, err := config.GetModels()
+
+	// Assertions
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, len(models), 1)
+	test.AssertEqual(t, models[0].Name, "127.0.0.1/llm")
+	test.AssertEqual(t, models[0].URL, server.URL+"/llm.gguf")
+	test.AssertNotEqual(t, models[0].Sha256, "")
+	test.AssertEqual(t, models[0].Module, huggingface.TRANSFORMERS)
+}
+
+// Tests processAddBatch with different URLs naming the same single file model
+func TestProcessAddBatch_SingleFileURLSameName(t *testing.T) {
+	// Init
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("GGUF0000"))
+	}))
+	defer server.Close()
+	ac := AddController{SingleFile: true}
+
+	// Create full test suite with a configuration file
+	ts := test.TestSuite{}
+	_ = ts.CreateFullTestSuite(t)
+	defer ts.CleanTestSuite(t)
+	err := setupConfigFile(model.Models{})
+	test.AssertEqual(t, err, nil, "No error expected on setting configuration file")
+
+	// Process add
+	result := ac.processAddBatch([]string{server.URL + "/a/llm.gguf", server.URL + "/a/llm.gguf?version=2",
+		server.URL + "/b/llm.gguf"}, downloadermodel.Args{})
+	models, err := config.GetModels()
+
+	// Assertions
+	test.AssertNotEqual(t, result.Error, nil)
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, len(models), 2)
+	test.AssertEqual(t, models[0].Name, "127.0.0.1/a/llm")
+	test.AssertEqual(t, models[1].Name, "127.0.0.1/b/llm")
+}
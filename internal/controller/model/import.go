@@ -0,0 +1,147 @@
+package modelcontroller
+
+import (
+	"fmt"
+	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/easy-model-fusion/emf-cli/internal/config"
+	"github.com/easy-model-fusion/emf-cli/internal/model"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/fileutil"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/stringutil"
+	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
+	"os"
+	"path"
+	"strings"
+)
+
+type ImportController struct {
+	Name        string
+	PipelineTag string
+	Hardlink    bool
+}
+
+// Run runs the model import command
+func (ic ImportController) Run(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("you must enter exactly one directory to import")
+	}
+
+	// The name is used as the path of the model in the models directory
+	if err := validateModelName(ic.Name); err != nil {
+		return err
+	}
+	if ic.PipelineTag != "" && !stringutil.SliceContainsItem(huggingface.AllTagsString(), ic.PipelineTag) {
+		return fmt.Errorf("invalid pipeline tag '%s', expected one of %s", ic.PipelineTag,
+			stringutil.SliceToArgsFormat(huggingface.AllTagsString()))
+	}
+
+	// Load the configuration file
+	err := config.GetViperConfig(config.FilePath)
+	if err != nil {
+		return err
+	}
+
+	// Get all models from configuration file
+	existingModels, err := config.GetModels()
+	if err != nil {
+		return err
+	}
+	if existingModels.ContainsByName(ic.Name) {
+		return fmt.Errorf("model %s is already configured", ic.Name)
+	}
+
+	// Detect the model stored in the directory
+	localModel, err := model.FromLocalDirectory(ic.Name, args[0])
+	if err != nil {
+		return fmt.Errorf("couldn't import %s : %s", args[0], err)
+	}
+	if ic.PipelineTag != "" {
+		localModel.PipelineTag = huggingface.PipelineTag(ic.PipelineTag)
+	}
+
+	// Copy the model to the models directory
+	spinner := app.UI().StartSpinner(fmt.Sprintf("Importing model %s...", ic.Name))
+	importedModel, err := ic.importModel(localModel)
+	if err != nil {
+		spinner.Fail(fmt.Sprintf("Error while importing the model: %s", err))
+		return err
+	}
+	spinner.Success()
+
+	// Configure the model the same way as a model coming from huggingface
+	err = AddController{}.saveModels(model.Models{importedModel})
+	if err != nil {
+		return err
+	}
+
+	app.UI().Success().Printfln("Model %s imported as %s %s (%s)", ic.Name, importedModel.Module, importedModel.Class, importedModel.PipelineTag)
+	return nil
+}
+
+// validateModelName verifies that the name is made of two path segments (i.e. organization/model)
+// The name must stay inside the models directory : the segments '.' and '..' aren't allowed.
+func validateModelName(name string) error {
+	segments := strings.Split(name, "/")
+	valid := len(segments) == 2 && path.Clean(name) == name && !strings.Contains(name, "\\")
+	for _, segment := range segments {
+		if segment == "" || segment == "." || segment == ".." {
+			valid = false
+		}
+	}
+	if !valid {
+		return fmt.Errorf("invalid model name '%s', expected a name such as 'organization/model'", name)
+	}
+	return nil
+}
+
+// importModel copies the files of the local model to the models directory
+// Nothing is kept in the models directory if the copy fails
+func (ic ImportController) importModel(localModel model.Model) (importedModel model.Model, err error) {
+	importedModel = localModel
+	importedModel.Path = ""
+	importedModel.IsDownloaded = true
+	importedModel.AddToBinaryFile = true
+	importedModel.Tokenizers = nil
+	for _, tokenizer := range localModel.Tokenizers {
+		importedModel.Tokenizers = append(importedModel.Tokenizers, model.Tokenizer{Class: tokenizer.Class})
+	}
+	importedModel.UpdatePaths()
+
+	basePath := importedModel.GetBasePath()
+	exists, err := fileutil.IsExistingPath(basePath)
+	if err != nil {
+		return importedModel, err
+	}
+	if exists {
+		return importedModel, fmt.Errorf("%s already exists", basePath)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = os.RemoveAll(basePath)
+			_ = fileutil.DeleteDirectoryIfEmpty(fileutil.PathJoin(basePath, ".."))
+		}
+	}()
+
+	// Diffusers pipelines are stored as they are
+	if importedModel.Module == huggingface.DIFFUSERS {
+		return importedModel, fileutil.CopyDirectory(localModel.Path, importedModel.Path, ic.Hardlink, nil)
+	}
+
+	// Transformers models are saved flat : the subdirectories belong to the tokenizers
+	err = fileutil.CopyDirectory(localModel.Path, importedModel.Path, ic.Hardlink, func(relativePath string) bool {
+		return !strings.Contains(relativePath, "/")
+	})
+	if err != nil {
+		return importedModel, err
+	}
+	for i, tokenizer := range localModel.Tokenizers {
+		err = fileutil.CopyDirectory(tokenizer.Path, importedModel.Tokenizers[i].Path, ic.Hardlink, func(relativePath string) bool {
+			return !strings.Contains(relativePath, "/") && !model.IsWeightsFile(relativePath)
+		})
+		if err != nil {
+			return importedModel, err
+		}
+	}
+
+	return importedModel, nil
+}
@@ -0,0 +1,94 @@
+package modelcontroller
+
+import (
+	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/easy-model-fusion/emf-cli/internal/config"
+	"github.com/easy-model-fusion/emf-cli/internal/model"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/fileutil"
+	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
+	"github.com/easy-model-fusion/emf-cli/test"
+	"testing"
+)
+
+// TestImportController_Run_InvalidName tests that the name must contain an organization.
+func TestImportController_Run_InvalidName(t *testing.T) {
+	// Execute
+	err := ImportController{Name: "model"}.Run([]string{"directory"})
+
+	// Assert
+	test.AssertNotEqual(t, err, nil)
+}
+
+// TestValidateModelName tests that the names resolving outside the models directory are rejected.
+func TestValidateModelName(t *testing.T) {
+	test.AssertEqual(t, validateModelName("org/model"), nil)
+	test.AssertEqual(t, validateModelName("org/model.v1"), nil)
+	for _, name := range []string{"", "model", "/model", "org/", "org//model", "../x", "org/..", "./x", "org/.",
+		"org/model/weights", "org\\model", "..\\x/model"} {
+		test.AssertNotEqual(t, validateModelName(name), nil, name)
+	}
+}
+
+// TestImportController_Run_InvalidPipelineTag tests that an unknown pipeline tag returns an error.
+func TestImportController_Run_InvalidPipelineTag(t *testing.T) {
+	// Execute
+	err := ImportController{Name: "org/model", PipelineTag: "unknown"}.Run([]string{"directory"})
+
+	// Assert
+	test.AssertNotEqual(t, err, nil)
+}
+
+// TestImportController_Run_Transformers tests that a transformers model is copied and configured.
+func TestImportController_Run_Transformers(t *testing.T) {
+	// Init
+	ts := test.TestSuite{}
+	_ = ts.CreateFullTestSuite(t)
+	defer ts.CleanTestSuite(t)
+	err := setupConfigFile(model.Models{})
+	test.AssertEqual(t, err, nil)
+	source := t.TempDir()
+	test.CreateFiles(t, source, map[string]string{
+		"config.json":           `{"architectures": ["GPT2LMHeadModel"]}`,
+		"model.safetensors":     "weights",
+		"tokenizer_config.json": `{"tokenizer_class": "GPT2Tokenizer"}`,
+		"vocab.json":            "{}",
+	})
+
+	// Execute
+	err = ImportController{Name: "org/model", Hardlink: true}.Run([]string{source})
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	models, err := config.GetModels()
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, len(models), 1)
+	test.AssertEqual(t, models[0].Source, model.LOCAL)
+	test.AssertEqual(t, models[0].Module, huggingface.TRANSFORMERS)
+	test.AssertEqual(t, models[0].Class, "GPT2LMHeadModel")
+	test.AssertEqual(t, models[0].PipelineTag, huggingface.TextGeneration)
+	test.AssertEqual(t, models[0].Path, fileutil.PathJoin(app.DownloadDirectoryPath, "org/model/model"))
+	test.AssertEqual(t, models[0].Tokenizers[0].Path, fileutil.PathJoin(app.DownloadDirectoryPath, "org/model/GPT2Tokenizer"))
+
+	exists, _ := fileutil.IsExistingPath(fileutil.PathJoin(models[0].Path, "model.safetensors"))
+	test.AssertEqual(t, exists, true)
+	exists, _ = fileutil.IsExistingPath(fileutil.PathJoin(models[0].Tokenizers[0].Path, "vocab.json"))
+	test.AssertEqual(t, exists, true)
+	exists, _ = fileutil.IsExistingPath(fileutil.PathJoin(models[0].Tokenizers[0].Path, "model.safetensors"))
+	test.AssertEqual(t, exists, false)
+}
+
+// TestImportController_Run_AlreadyConfigured tests that a configured model can't be imported again.
+func TestImportController_Run_AlreadyConfigured(t *testing.T) {
+	// Init
+	ts := test.TestSuite{}
+	_ = ts.CreateFullTestSuite(t)
+	defer ts.CleanTestSuite(t)
+	err := setupConfigFile(model.Models{{Name: "org/model"}})
+	test.AssertEqual(t, err, nil)
+
+	// Execute
+	err = ImportController{Name: "org/model"}.Run([]string{t.TempDir()})
+
+	// Assert
+	test.AssertNotEqual(t, err, nil)
+}
@@ -0,0 +1,236 @@
+package modelcontroller
+
+import (
+	"fmt"
+	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/easy-model-fusion/emf-cli/internal/config"
+	"github.com/easy-model-fusion/emf-cli/internal/hfinterface"
+	"github.com/easy-model-fusion/emf-cli/internal/model"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/fileutil"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/outpututil"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/stringutil"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+type InfoController struct {
+	Output      string
+	AccessToken string
+}
+
+// InfoItem represents everything known about a configured model
+type InfoItem struct {
+	ListItem           `yaml:",inline"`
+	Path               string            `json:"path" yaml:"path"`
+	Options            map[string]string `json:"options" yaml:"options"`
+	BasePath           string            `json:"basePath" yaml:"basePath"`
+	DownloadedOnDevice bool              `json:"downloadedOnDevice" yaml:"downloadedOnDevice"`
+	MissingTokenizers  []string          `json:"missingTokenizers" yaml:"missingTokenizers"`
+	Files              []InfoFile        `json:"files" yaml:"files"`
+	AccessTokenKey     string            `json:"accessTokenKey" yaml:"accessTokenKey"`
+	AccessTokenValue   string            `json:"accessTokenValue" yaml:"accessTokenValue"`
+	Hub                *InfoHub          `json:"hub" yaml:"hub"`
+}
+
+// InfoFile represents a file of the model stored on the device
+type InfoFile struct {
+	Path string `json:"path" yaml:"path"`
+	Size int64  `json:"size" yaml:"size"`
+}
+
+// InfoHub represents the live state of the model on the Hugging Face Hub
+type InfoHub struct {
+	Name            string `json:"name" yaml:"name"`
+	PipelineTag     string `json:"pipelineTag" yaml:"pipelineTag"`
+	LibraryName     string `json:"libraryName" yaml:"libraryName"`
+	LastModified    string `json:"lastModified" yaml:"lastModified"`
+	UpdateAvailable bool   `json:"updateAvailable" yaml:"updateAvailable"`
+	Error           string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// Run runs the model info command
+func (ic InfoController) Run(args []string) error {
+	// Validate the output format before doing anything
+	format, err := outpututil.ParseFormat(ic.Output)
+	if err != nil {
+		return err
+	}
+
+	if len(args) != 1 {
+		return fmt.Errorf("you must enter exactly one model name")
+	}
+
+	// Load the configuration file
+	err = config.GetViperConfig(config.FilePath)
+	if err != nil {
+		return err
+	}
+
+	// Get all models from configuration file
+	models, err := config.GetModels()
+	if err != nil {
+		return err
+	}
+
+	// Find the requested model
+	configModel, exists := models.Map()[args[0]]
+	if !exists {
+		return fmt.Errorf("model %s is not configured", args[0])
+	}
+
+	// Gather the information
+	item, err := ic.buildInfoItem(configModel)
+	if err != nil {
+		return err
+	}
+
+	// Display the information
+	if format.IsStructured() {
+		return outpututil.Write(os.Stdout, format, item)
+	}
+	ic.display(item)
+	return nil
+}
+
+// buildInfoItem gathers the configuration, device and hub information of the model
+func (ic InfoController) buildInfoItem(configModel model.Model) (item InfoItem, err error) {
+	listItems := ListController{}.buildListItems(model.Models{configModel})
+	item.ListItem = listItems[0]
+	item.Path = configModel.Path
+	item.Options = configModel.Options
+	item.BasePath = configModel.GetBasePath()
+
+	// Device state
+	item.DownloadedOnDevice, err = configModel.DownloadedOnDevice(false)
+	if err != nil {
+		return item, err
+	}
+	item.MissingTokenizers = configModel.GetTokenizersNotDownloadedOnDevice().GetNames()
+	if item.MissingTokenizers == nil {
+		item.MissingTokenizers = []string{}
+	}
+	item.Files, err = getInfoFiles(item.BasePath)
+	if err != nil {
+		return item, err
+	}
+
+	// Access token
+	item.AccessTokenKey = configModel.AccessToken
+	if configModel.AccessToken != "" {
+		value, err := configModel.GetAccessToken()
+		if err != nil {
+			return item, err
+		}
+		item.AccessTokenValue = stringutil.MaskSecret(value)
+	}
+	accessToken, err := configModel.ResolveAccessToken(ic.AccessToken)
+	if err != nil {
+		return item, err
+	}
+
+	// Only the models coming from huggingface can be compared to the hub, which can't be reached offline
+	if configModel.Source != model.HUGGING_FACE || app.Offline() {
+		return item, nil
+	}
+
+	// Fetching model from huggingface : a failure is reported but doesn't prevent the display
+	item.Hub = &InfoHub{}
+	ctx, stop := app.InterruptContext()
+	defer stop()
+	huggingfaceModel, err := hfinterface.GetModelById(ctx, configModel.Name, accessToken)
+	if err != nil {
+		item.Hub.Error = err.Error()
+		return item, nil
+	}
+	item.Hub.Name = huggingfaceModel.Name
+	item.Hub.PipelineTag = string(huggingfaceModel.PipelineTag)
+	item.Hub.LibraryName = string(huggingfaceModel.LibraryName)
+	item.Hub.LastModified = huggingfaceModel.LastModified
+	item.Hub.UpdateAvailable = isNewerVersion(configModel.Version, huggingfaceModel.LastModified)
+
+	return item, nil
+}
+
+// getInfoFiles returns the files stored under the given path, sorted by path
+func getInfoFiles(path string) ([]InfoFile, error) {
+	files := []InfoFile{}
+	exists, err := fileutil.IsExistingPath(path)
+	if err != nil || !exists {
+		return files, err
+	}
+
+	sizes, err := fileutil.PathFilesSize(path)
+	if err != nil {
+		return files, err
+	}
+	for name, size := range sizes {
+		files = append(files, InfoFile{Path: name, Size: size})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+	return files, nil
+}
+
+// isNewerVersion returns true if the latest version is more recent than the current one
+func isNewerVersion(current, latest string) bool {
+	if latest == "" || current == latest {
+		return false
+	}
+	currentTime, errCurrent := time.Parse(time.RFC3339, current)
+	latestTime, errLatest := time.Parse(time.RFC3339, latest)
+	if errCurrent != nil || errLatest != nil {
+		// Versions can't be compared : considering any difference as a newer version
+		return true
+	}
+	return latestTime.After(currentTime)
+}
+
+// display displays the model information to the user
+func (ic InfoController) display(item InfoItem) {
+	data := [][]string{
+		{"Field", "Value"},
+		{"Name", item.Name},
+		{"Module", item.Module},
+		{"Class", item.Class},
+		{"Pipeline tag", item.PipelineTag},
+		{"Source", item.Source},
+		{"Version", item.Version},
+		{"Path", item.Path},
+		{"Options", strings.Join(stringutil.OptionsMapToSlice(item.Options), " ")},
+		{"Configured as downloaded", fmt.Sprint(item.IsDownloaded)},
+		{"Downloaded on device", fmt.Sprint(item.DownloadedOnDevice)},
+		{"Binary", fmt.Sprint(item.AddToBinaryFile)},
+		{"Tokenizers", strings.Join(item.Tokenizers, ", ")},
+		{"Missing tokenizers", strings.Join(item.MissingTokenizers, ", ")},
+		{"Size", fileutil.ReadableSize(item.Size)},
+		{"Access token key", item.AccessTokenKey},
+		{"Access token value", item.AccessTokenValue},
+	}
+	if item.Hub != nil {
+		if item.Hub.Error != "" {
+			data = append(data, []string{"Hub", item.Hub.Error})
+		} else {
+			data = append(data,
+				[]string{"Hub pipeline tag", item.Hub.PipelineTag},
+				[]string{"Hub library", item.Hub.LibraryName},
+				[]string{"Hub last modified", item.Hub.LastModified},
+				[]string{"Update available", fmt.Sprint(item.Hub.UpdateAvailable)},
+			)
+		}
+	}
+	app.UI().DisplayTable(data)
+
+	// Display the files stored on the device
+	if len(item.Files) == 0 {
+		app.UI().Info().Printfln("No file stored under %s", item.BasePath)
+		return
+	}
+	files := [][]string{{"File", "Size"}}
+	for _, file := range item.Files {
+		files = append(files, []string{file.Path, fileutil.ReadableSize(file.Size)})
+	}
+	app.UI().DisplayTable(files)
+}
@@ -0,0 +1,118 @@
+package modelcontroller
+
+import (
+	"errors"
+	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/easy-model-fusion/emf-cli/internal/model"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/fileutil"
+	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
+	"github.com/easy-model-fusion/emf-cli/test"
+	"os"
+	"testing"
+)
+
+// TestInfoController_BuildInfoItem_Success tests that the device and hub information are gathered.
+func TestInfoController_BuildInfoItem_Success(t *testing.T) {
+	// Init
+	ts := test.TestSuite{}
+	_ = ts.CreateFullTestSuite(t)
+	defer ts.CleanTestSuite(t)
+	app.SetHuggingFace(&huggingface.MockHuggingFace{GetModelResult: huggingface.Model{
+		LibraryName:  huggingface.DIFFUSERS,
+		LastModified: "2024-02-01T00:00:00.000Z",
+	}})
+	configModel := model.Model{
+		Name:    "org/model",
+		Module:  huggingface.DIFFUSERS,
+		Source:  model.HUGGING_FACE,
+		Version: "2024-01-01T00:00:00.000Z",
+	}
+	configModel.Path = configModel.GetBasePath()
+	err := os.MkdirAll(fileutil.PathJoin(configModel.Path, "unet"), os.ModePerm)
+	test.AssertEqual(t, err, nil)
+	err = os.WriteFile(fileutil.PathJoin(configModel.Path, "unet", "weights"), []byte("1234"), os.ModePerm)
+	test.AssertEqual(t, err, nil)
+
+	// Execute
+	item, err := InfoController{}.buildInfoItem(configModel)
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, item.DownloadedOnDevice, true)
+	test.AssertEqual(t, len(item.Files), 1)
+	test.AssertEqual(t, item.Files[0].Path, "unet/weights")
+	test.AssertEqual(t, item.Files[0].Size, int64(4))
+	test.AssertNotEqual(t, item.Hub, nil)
+	test.AssertEqual(t, item.Hub.Error, "")
+	test.AssertEqual(t, item.Hub.UpdateAvailable, true)
+}
+
+// TestInfoController_BuildInfoItem_HubError tests that a hub failure doesn't prevent the information gathering.
+func TestInfoController_BuildInfoItem_HubError(t *testing.T) {
+	// Init
+	app.SetHuggingFace(&huggingface.MockHuggingFace{Error: errors.New("not found")})
+	configModel := model.Model{Name: "org/missing", Source: model.HUGGING_FACE}
+
+	// Execute
+	item, err := InfoController{}.buildInfoItem(configModel)
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, item.DownloadedOnDevice, false)
+	test.AssertEqual(t, len(item.Files), 0)
+	test.AssertEqual(t, item.Hub.Error, "not found")
+}
+
+// TestInfoController_BuildInfoItem_Custom tests that the hub isn't queried for custom models.
+func TestInfoController_BuildInfoItem_Custom(t *testing.T) {
+	// Init
+	configModel := model.Model{Name: "custom/model", Source: model.CUSTOM}
+
+	// Execute
+	item, err := InfoController{}.buildInfoItem(configModel)
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, item.Hub == nil, true)
+}
+
+// TestInfoController_Run_NotConfigured tests that an error is returned for a model missing from the configuration.
+func TestInfoController_Run_NotConfigured(t *testing.T) {
+	// Init
+	ts := test.TestSuite{}
+	_ = ts.CreateFullTestSuite(t)
+	defer ts.CleanTestSuite(t)
+	err := setupConfigFile(model.Models{{Name: "model1"}})
+	test.AssertEqual(t, err, nil)
+
+	// Execute
+	err = InfoController{Output: "table"}.Run([]string{"model2"})
+
+	// Assert
+	test.AssertNotEqual(t, err, nil)
+}
+
+// TestInfoController_Run_Success tests that the information of a configured model is displayed.
+func TestInfoController_Run_Success(t *testing.T) {
+	// Init
+	ts := test.TestSuite{}
+	_ = ts.CreateFullTestSuite(t)
+	defer ts.CleanTestSuite(t)
+	err := setupConfigFile(model.Models{{Name: "model1", Source: model.CUSTOM}})
+	test.AssertEqual(t, err, nil)
+
+	// Execute
+	err = InfoController{Output: "yaml"}.Run([]string{"model1"})
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+}
+
+// TestIsNewerVersion tests the comparison of the model versions.
+func TestIsNewerVersion(t *testing.T) {
+	test.AssertEqual(t, isNewerVersion("2024-01-01T00:00:00.000Z", "2024-02-01T00:00:00.000Z"), true)
+	test.AssertEqual(t, isNewerVersion("2024-02-01T00:00:00.000Z", "2024-01-01T00:00:00.000Z"), false)
+	test.AssertEqual(t, isNewerVersion("2024-01-01T00:00:00.000Z", "2024-01-01T00:00:00.000Z"), false)
+	test.AssertEqual(t, isNewerVersion("", "2024-01-01T00:00:00.000Z"), true)
+	test.AssertEqual(t, isNewerVersion("2024-01-01T00:00:00.000Z", ""), false)
+}
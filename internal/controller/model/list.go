@@ -0,0 +1,169 @@
+package modelcontroller
+
+import (
+	"fmt"
+	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/easy-model-fusion/emf-cli/internal/config"
+	"github.com/easy-model-fusion/emf-cli/internal/model"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/fileutil"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/outpututil"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/stringutil"
+	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
+	"os"
+	"strings"
+)
+
+type ListController struct {
+	Output     string
+	Module     string
+	Source     string
+	Downloaded bool
+	Binary     bool
+}
+
+// ListItem represents a configured model as displayed by the list command
+type ListItem struct {
+	Name            string   `json:"name" yaml:"name"`
+	Module          string   `json:"module" yaml:"module"`
+	Class           string   `json:"class" yaml:"class"`
+	PipelineTag     string   `json:"pipelineTag" yaml:"pipelineTag"`
+	Source          string   `json:"source" yaml:"source"`
+	Version         string   `json:"version" yaml:"version"`
+	IsDownloaded    bool     `json:"isDownloaded" yaml:"isDownloaded"`
+	AddToBinaryFile bool     `json:"addToBinaryFile" yaml:"addToBinaryFile"`
+	Tokenizers      []string `json:"tokenizers" yaml:"tokenizers"`
+	Size            int64    `json:"size" yaml:"size"`
+}
+
+// Run runs the model list command
+func (lc ListController) Run(args []string) error {
+	// Validate the output format before doing anything
+	format, err := outpututil.ParseFormat(lc.Output)
+	if err != nil {
+		return err
+	}
+
+	// Load the configuration file
+	err = config.GetViperConfig(config.FilePath)
+	if err != nil {
+		return err
+	}
+
+	// Get all models from configuration file
+	models, err := config.GetModels()
+	if err != nil {
+		return err
+	}
+
+	// Keep the requested models only
+	models, err = lc.filterModels(models, args)
+	if err != nil {
+		return err
+	}
+
+	// Display the models
+	items := lc.buildListItems(models)
+	if format.IsStructured() {
+		return outpututil.Write(os.Stdout, format, items)
+	}
+	if len(items) == 0 {
+		app.UI().Info().Println("There is no models to be listed.")
+		return nil
+	}
+	app.UI().DisplayTable(lc.buildTable(items))
+	return nil
+}
+
+// filterModels applies the requested filters to the models
+func (lc ListController) filterModels(models model.Models, names []string) (model.Models, error) {
+	// Filter on the names passed in args
+	if len(names) > 0 {
+		models = models.FilterWithNames(stringutil.SliceRemoveDuplicates(names))
+	}
+
+	// Filter on the module
+	if lc.Module != "" {
+		if !stringutil.SliceContainsItem(huggingface.AllModulesString(), lc.Module) {
+			return nil, fmt.Errorf("invalid module '%s', expected one of %s", lc.Module,
+				stringutil.SliceToArgsFormat(huggingface.AllModulesString()))
+		}
+		models = models.FilterWithModule(huggingface.Module(lc.Module))
+	}
+
+	// Filter on the source
+	switch lc.Source {
+	case "":
+		// No filter
+	case model.HUGGING_FACE:
+		models = models.FilterWithSourceHuggingface()
+	case model.CUSTOM:
+		models = models.FilterWithSourceCustom()
+	case model.LOCAL:
+		models = models.FilterWithSourceLocal()
+	default:
+		return nil, fmt.Errorf("invalid source '%s', expected one of %s", lc.Source,
+			stringutil.SliceToArgsFormat([]string{model.HUGGING_FACE, model.CUSTOM, model.LOCAL}))
+	}
+
+	// Filter on the model's state
+	if lc.Downloaded {
+		models = models.FilterWithIsDownloadedTrue()
+	}
+	if lc.Binary {
+		models = models.FilterWithAddToBinaryFileTrue()
+	}
+
+	return models, nil
+}
+
+// buildListItems maps the models to the items to be displayed
+func (lc ListController) buildListItems(models model.Models) []ListItem {
+	items := []ListItem{}
+	for _, current := range models {
+		// The size can't be computed : considered as nothing on the device
+		size, err := current.SizeOnDevice()
+		if err != nil {
+			size = 0
+		}
+
+		tokenizers := current.Tokenizers.GetNames()
+		if tokenizers == nil {
+			tokenizers = []string{}
+		}
+
+		items = append(items, ListItem{
+			Name:            current.Name,
+			Module:          string(current.Module),
+			Class:           current.Class,
+			PipelineTag:     string(current.PipelineTag),
+			Source:          current.Source,
+			Version:         current.Version,
+			IsDownloaded:    current.IsDownloaded,
+			AddToBinaryFile: current.AddToBinaryFile,
+			Tokenizers:      tokenizers,
+			Size:            size,
+		})
+	}
+	return items
+}
+
+// buildTable builds the table rows (header included) for the items
+func (lc ListController) buildTable(items []ListItem) [][]string {
+	data := [][]string{{"Name", "Module", "Class", "Pipeline tag", "Source", "Version",
+		"Downloaded", "Binary", "Tokenizers", "Size"}}
+	for _, item := range items {
+		data = append(data, []string{
+			item.Name,
+			item.Module,
+			item.Class,
+			item.PipelineTag,
+			item.Source,
+			item.Version,
+			fmt.Sprint(item.IsDownloaded),
+			fmt.Sprint(item.AddToBinaryFile),
+			strings.Join(item.Tokenizers, ", "),
+			fileutil.ReadableSize(item.Size),
+		})
+	}
+	return data
+}
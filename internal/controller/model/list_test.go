@@ -0,0 +1,152 @@
+package modelcontroller
+
+import (
+	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/easy-model-fusion/emf-cli/internal/model"
+	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
+	"github.com/easy-model-fusion/emf-cli/test"
+	"github.com/easy-model-fusion/emf-cli/test/mock"
+	"testing"
+)
+
+// getListTestModels returns the models used by the list tests
+func getListTestModels() model.Models {
+	return model.Models{
+		{Name: "model1", Module: huggingface.DIFFUSERS, Source: model.HUGGING_FACE, IsDownloaded: true, AddToBinaryFile: true},
+		{Name: "model2", Module: huggingface.TRANSFORMERS, Source: model.HUGGING_FACE, IsDownloaded: false, AddToBinaryFile: true,
+			Tokenizers: model.Tokenizers{{Class: "tokenizer1"}}},
+		{Name: "model3", Module: huggingface.DIFFUSERS, Source: model.CUSTOM, IsDownloaded: true, AddToBinaryFile: false},
+	}
+}
+
+// TestListController_FilterModels_NoFilter tests that every model is kept when no filter is requested.
+func TestListController_FilterModels_NoFilter(t *testing.T) {
+	// Init
+	lc := ListController{}
+
+	// Execute
+	result, err := lc.filterModels(getListTestModels(), []string{})
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, len(result), 3)
+}
+
+// TestListController_FilterModels_Combined tests that the filters are cumulated.
+func TestListController_FilterModels_Combined(t *testing.T) {
+	// Init
+	lc := ListController{Module: string(huggingface.DIFFUSERS), Downloaded: true, Source: model.HUGGING_FACE}
+
+	// Execute
+	result, err := lc.filterModels(getListTestModels(), []string{})
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, len(result), 1)
+	test.AssertEqual(t, result[0].Name, "model1")
+}
+
+// TestListController_FilterModels_Names tests that only the requested names are kept.
+func TestListController_FilterModels_Names(t *testing.T) {
+	// Init
+	lc := ListController{Binary: true}
+
+	// Execute
+	result, err := lc.filterModels(getListTestModels(), []string{"model2", "model3", "model2"})
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, len(result), 1)
+	test.AssertEqual(t, result[0].Name, "model2")
+}
+
+// TestListController_FilterModels_InvalidSource tests that an unknown source returns an error.
+func TestListController_FilterModels_InvalidSource(t *testing.T) {
+	// Init
+	lc := ListController{Source: "unknown"}
+
+	// Execute
+	_, err := lc.filterModels(getListTestModels(), []string{})
+
+	// Assert
+	test.AssertNotEqual(t, err, nil)
+}
+
+// TestListController_FilterModels_InvalidModule tests that an unknown module returns an error.
+func TestListController_FilterModels_InvalidModule(t *testing.T) {
+	// Init
+	lc := ListController{Module: "unknown"}
+
+	// Execute
+	_, err := lc.filterModels(getListTestModels(), []string{})
+
+	// Assert
+	test.AssertNotEqual(t, err, nil)
+}
+
+// TestListController_BuildTable tests that the table contains a header and one row per model.
+func TestListController_BuildTable(t *testing.T) {
+	// Init
+	lc := ListController{}
+	items := lc.buildListItems(getListTestModels())
+
+	// Execute
+	data := lc.buildTable(items)
+
+	// Assert
+	test.AssertEqual(t, len(data), 4)
+	test.AssertEqual(t, data[0][0], "Name")
+	test.AssertEqual(t, data[2][0], "model2")
+	test.AssertEqual(t, data[2][8], "tokenizer1")
+	test.AssertEqual(t, data[2][9], "0 B")
+}
+
+// TestListController_Run_InvalidOutput tests that an unknown output format returns an error.
+func TestListController_Run_InvalidOutput(t *testing.T) {
+	// Init
+	lc := ListController{Output: "xml"}
+
+	// Execute
+	err := lc.Run([]string{})
+
+	// Assert
+	test.AssertNotEqual(t, err, nil)
+}
+
+// TestListController_Run_Success tests that the configured models are listed.
+func TestListController_Run_Success(t *testing.T) {
+	// Init
+	ts := test.TestSuite{}
+	_ = ts.CreateFullTestSuite(t)
+	defer ts.CleanTestSuite(t)
+	err := setupConfigFile(getListTestModels())
+	test.AssertEqual(t, err, nil)
+
+	// Execute
+	err = ListController{Output: "json"}.Run([]string{})
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+}
+
+// TestListController_Run_Table tests that the configured models are displayed as a table.
+func TestListController_Run_Table(t *testing.T) {
+	// Init
+	var tables [][][]string
+	app.SetUI(mock.MockUI{Tables: &tables})
+	ts := test.TestSuite{}
+	_ = ts.CreateFullTestSuite(t)
+	defer ts.CleanTestSuite(t)
+	err := setupConfigFile(getListTestModels())
+	test.AssertEqual(t, err, nil)
+
+	// Execute
+	err = ListController{Output: "table"}.Run([]string{"model3"})
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, len(tables), 1)
+	test.AssertEqual(t, len(tables[0]), 2)
+	test.AssertEqual(t, tables[0][0][0], "Name")
+	test.AssertEqual(t, tables[0][1][0], "model3")
+}
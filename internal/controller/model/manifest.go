@@ -0,0 +1,80 @@
+package modelcontroller
+
+import (
+	"fmt"
+	"github.com/easy-model-fusion/emf-cli/internal/downloader/model"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/stringutil"
+	"gopkg.in/yaml.v3"
+	"os"
+)
+
+// Manifest represents a file listing the models to be added at once
+type Manifest struct {
+	Models []ManifestModel `yaml:"models"`
+}
+
+// ManifestModel represents a model to be added along with its own settings
+type ManifestModel struct {
+	Name              string            `yaml:"name"`
+	Module            string            `yaml:"module"`
+	Class             string            `yaml:"class"`
+	Options           map[string]string `yaml:"options"`
+	Tokenizer         ManifestTokenizer `yaml:"tokenizer"`
+	OnlyConfiguration *bool             `yaml:"only-configuration"`
+}
+
+// ManifestTokenizer represents the settings of the tokenizer of a model to be added
+type ManifestTokenizer struct {
+	Class   string            `yaml:"class"`
+	Options map[string]string `yaml:"options"`
+}
+
+// loadManifest reads and validates the manifest file
+func loadManifest(path string) (manifest Manifest, err error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return manifest, fmt.Errorf("error while reading the manifest file %s: %s", path, err)
+	}
+
+	if err = yaml.Unmarshal(content, &manifest); err != nil {
+		return manifest, fmt.Errorf("error while parsing the manifest file %s: %s", path, err)
+	}
+
+	for index, current := range manifest.Models {
+		if current.Name == "" {
+			return manifest, fmt.Errorf("missing name for the model at position %d in the manifest file %s", index+1, path)
+		}
+	}
+
+	return manifest, nil
+}
+
+// ToArgs builds the downloader arguments for every model of the manifest
+// The settings of the manifest take precedence over those of the base arguments
+func (m Manifest) ToArgs(base downloadermodel.Args) []downloadermodel.Args {
+	var result []downloadermodel.Args
+	for _, current := range m.Models {
+		args := base
+		args.ModelName = current.Name
+		if current.Module != "" {
+			args.ModelModule = current.Module
+		}
+		if current.Class != "" {
+			args.ModelClass = current.Class
+		}
+		if len(current.Options) > 0 {
+			args.ModelOptions = stringutil.OptionsMapToSlice(current.Options)
+		}
+		if current.Tokenizer.Class != "" {
+			args.TokenizerClass = current.Tokenizer.Class
+		}
+		if len(current.Tokenizer.Options) > 0 {
+			args.TokenizerOptions = stringutil.OptionsMapToSlice(current.Tokenizer.Options)
+		}
+		if current.OnlyConfiguration != nil {
+			args.OnlyConfiguration = *current.OnlyConfiguration
+		}
+		result = append(result, args)
+	}
+	return result
+}
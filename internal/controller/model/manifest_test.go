@@ -0,0 +1,83 @@
+package modelcontroller
+
+import (
+	"github.com/easy-model-fusion/emf-cli/internal/downloader/model"
+	"github.com/easy-model-fusion/emf-cli/test"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestLoadManifest_Success tests that the manifest file is parsed.
+func TestLoadManifest_Success(t *testing.T) {
+	// Init
+	path := filepath.Join(t.TempDir(), "models.yaml")
+	content := `models:
+  - name: org/model1
+    module: transformers
+    class: AutoModelForCausalLM
+    options:
+      torch_dtype: torch.float16
+    tokenizer:
+      class: AutoTokenizer
+  - name: org/model2
+    only-configuration: true
+`
+	err := os.WriteFile(path, []byte(content), 0644)
+	test.AssertEqual(t, err, nil)
+
+	// Execute
+	manifest, err := loadManifest(path)
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, len(manifest.Models), 2)
+	test.AssertEqual(t, manifest.Models[0].Class, "AutoModelForCausalLM")
+	test.AssertEqual(t, manifest.Models[0].Tokenizer.Class, "AutoTokenizer")
+	test.AssertEqual(t, *manifest.Models[1].OnlyConfiguration, true)
+}
+
+// TestLoadManifest_MissingName tests that a model without name returns an error.
+func TestLoadManifest_MissingName(t *testing.T) {
+	// Init
+	path := filepath.Join(t.TempDir(), "models.yaml")
+	err := os.WriteFile(path, []byte("models:\n  - module: diffusers\n"), 0644)
+	test.AssertEqual(t, err, nil)
+
+	// Execute
+	_, err = loadManifest(path)
+
+	// Assert
+	test.AssertNotEqual(t, err, nil)
+}
+
+// TestLoadManifest_MissingFile tests that a missing manifest file returns an error.
+func TestLoadManifest_MissingFile(t *testing.T) {
+	_, err := loadManifest(filepath.Join(t.TempDir(), "missing.yaml"))
+	test.AssertNotEqual(t, err, nil)
+}
+
+// TestManifest_ToArgs tests that the manifest settings take precedence over the base arguments.
+func TestManifest_ToArgs(t *testing.T) {
+	// Init
+	onlyConfiguration := false
+	manifest := Manifest{Models: []ManifestModel{
+		{Name: "model1", Class: "Class1", Options: map[string]string{"key": "value"}, OnlyConfiguration: &onlyConfiguration},
+		{Name: "model2", Tokenizer: ManifestTokenizer{Class: "Tokenizer2"}},
+	}}
+	base := downloadermodel.Args{ModelClass: "BaseClass", DirectoryPath: "models", OnlyConfiguration: true}
+
+	// Execute
+	result := manifest.ToArgs(base)
+
+	// Assert
+	test.AssertEqual(t, len(result), 2)
+	test.AssertEqual(t, result[0].ModelName, "model1")
+	test.AssertEqual(t, result[0].ModelClass, "Class1")
+	test.AssertEqual(t, result[0].ModelOptions[0], "key=value")
+	test.AssertEqual(t, result[0].OnlyConfiguration, false)
+	test.AssertEqual(t, result[0].DirectoryPath, "models")
+	test.AssertEqual(t, result[1].ModelClass, "BaseClass")
+	test.AssertEqual(t, result[1].TokenizerClass, "Tokenizer2")
+	test.AssertEqual(t, result[1].OnlyConfiguration, true)
+}
@@ -0,0 +1,150 @@
+package modelcontroller
+
+import (
+	"errors"
+	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/easy-model-fusion/emf-cli/internal/config"
+	"github.com/easy-model-fusion/emf-cli/internal/model"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/outpututil"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/stringutil"
+	"os"
+)
+
+const (
+	OutdatedStatusOutdated = "outdated"
+	OutdatedStatusUpToDate = "up-to-date"
+	OutdatedStatusNotFound = "not-found"
+	// OutdatedStatusPinned is the status of the models pinned to their revision, which are only moved on demand
+	OutdatedStatusPinned = "pinned"
+	// OutdatedStatusUnknown is the status of the models whose downloaded commit sha wasn't recorded
+	OutdatedStatusUnknown = "unknown"
+	// OutdatedStatusUnreachable is the status of the models the hub refused to describe (i.e. gated or rate limited)
+	OutdatedStatusUnreachable = "unreachable"
+)
+
+type OutdatedController struct {
+	Output      string
+	AccessToken string
+}
+
+// OutdatedItem represents the update state of a configured model
+type OutdatedItem struct {
+	Name            string `json:"name" yaml:"name"`
+	CurrentVersion  string `json:"currentVersion" yaml:"currentVersion"`
+	LatestVersion   string `json:"latestVersion" yaml:"latestVersion"`
+	CurrentRevision string `json:"currentRevision" yaml:"currentRevision"`
+	LatestRevision  string `json:"latestRevision" yaml:"latestRevision"`
+	Status          string `json:"status" yaml:"status"`
+	Error           string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// Run runs the model outdated command
+// Nothing is downloaded : returns true if at least one model can be updated
+func (oc OutdatedController) Run(args []string) (pending bool, err error) {
+	// Validate the output format before doing anything
+	format, err := outpututil.ParseFormat(oc.Output)
+	if err != nil {
+		return false, err
+	}
+
+	// The latest versions can't be fetched offline
+	if app.Offline() {
+		return false, app.NewOfflineError("checking for model updates")
+	}
+
+	// Load the configuration file
+	err = config.GetViperConfig(config.FilePath)
+	if err != nil {
+		return false, err
+	}
+
+	// Get all models from configuration file
+	configModels, err := config.GetModels()
+	if err != nil {
+		return false, err
+	}
+
+	// Only the models coming from huggingface can be checked
+	hfModels := configModels.FilterWithSourceHuggingface()
+	if len(args) > 0 {
+		hfModels = hfModels.FilterWithNames(stringutil.SliceRemoveDuplicates(args))
+	}
+
+	// Check the latest version of every model
+	modelsToUpdate, notFoundModelNames, _, pinnedModelNames, failures := getUpdatableModels(hfModels.GetNames(), hfModels, oc.AccessToken, "")
+	items := buildOutdatedItems(hfModels, modelsToUpdate, notFoundModelNames, pinnedModelNames, failures)
+	pending = len(modelsToUpdate) > 0
+
+	// Display the report
+	if format.IsStructured() {
+		return pending, outpututil.Write(os.Stdout, format, items)
+	}
+	if len(items) == 0 {
+		app.UI().Info().Println("There is no models to be checked.")
+		return false, nil
+	}
+	app.UI().DisplayTable(buildOutdatedTable(items))
+	for _, item := range items {
+		if item.Error != "" {
+			app.UI().Warning().Printfln("Model %s couldn't be checked : %s", item.Name, item.Error)
+		}
+	}
+	if pending {
+		app.UI().Warning().Printfln("%d model(s) can be updated using '%s model update'", len(modelsToUpdate), app.Name)
+	} else {
+		app.UI().Success().Println("All models are up to date.")
+	}
+	return pending, nil
+}
+
+// buildOutdatedItems maps the checked models to the items to be displayed
+// The models failing to be fetched are reported as unreachable along with their error.
+func buildOutdatedItems(checkedModels model.Models, modelsToUpdate model.Models, notFoundModelNames []string,
+	pinnedModelNames []string, failures map[string]error) []OutdatedItem {
+	mapModelsToUpdate := modelsToUpdate.Map()
+	items := []OutdatedItem{}
+	for _, current := range checkedModels {
+		item := OutdatedItem{
+			Name:            current.Name,
+			CurrentVersion:  current.Version,
+			LatestVersion:   current.Version,
+			CurrentRevision: current.Revision,
+			LatestRevision:  current.Revision,
+			Status:          OutdatedStatusUpToDate,
+		}
+
+		if latest, exists := mapModelsToUpdate[current.Name]; exists {
+			item.LatestVersion = latest.Version
+			item.LatestRevision = latest.Revision
+			item.Status = OutdatedStatusOutdated
+		} else if stringutil.SliceContainsItem(notFoundModelNames, current.Name) {
+			item.LatestVersion = ""
+			item.LatestRevision = ""
+			item.Status = OutdatedStatusNotFound
+		} else if stringutil.SliceContainsItem(pinnedModelNames, current.Name) {
+			item.LatestVersion = ""
+			item.LatestRevision = ""
+			item.Status = OutdatedStatusPinned
+		} else if err, failed := failures[current.Name]; failed {
+			item.LatestVersion = ""
+			item.LatestRevision = ""
+			item.Status = OutdatedStatusUnreachable
+			if errors.Is(err, errUnknownVersion) {
+				item.Status = OutdatedStatusUnknown
+			}
+			item.Error = err.Error()
+		}
+
+		items = append(items, item)
+	}
+	return items
+}
+
+// buildOutdatedTable builds the table rows (header included) for the items
+func buildOutdatedTable(items []OutdatedItem) [][]string {
+	data := [][]string{{"Name", "Current version", "Latest version", "Status"}}
+	for _, item := range items {
+		data = append(data, []string{item.Name, item.CurrentVersion, item.LatestVersion, item.Status})
+	}
+	return data
+}
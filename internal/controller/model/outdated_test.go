@@ -0,0 +1,96 @@
+package modelcontroller
+
+import (
+	"errors"
+	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/easy-model-fusion/emf-cli/internal/model"
+	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
+	"github.com/easy-model-fusion/emf-cli/test"
+	"testing"
+)
+
+// TestBuildOutdatedItems tests that the status and versions of every model are reported.
+func TestBuildOutdatedItems(t *testing.T) {
+	// Init
+	checkedModels := model.Models{GetModel(1, "2021"), GetModel(2, "2022"), GetModel(3, "2020"), GetModel(4, "2020"),
+		GetModel(5, "2020"), GetModel(6, "2020")}
+	modelsToUpdate := model.Models{GetModel(1, "2022")}
+	failures := map[string]error{"model4": huggingface.ErrGated, "model6": errUnknownVersion}
+
+	// Execute
+	items := buildOutdatedItems(checkedModels, modelsToUpdate, []string{"model3"}, []string{"model5"}, failures)
+
+	// Assert
+	test.AssertEqual(t, len(items), 6)
+	test.AssertEqual(t, items[0].Status, OutdatedStatusOutdated)
+	test.AssertEqual(t, items[0].CurrentVersion, "2021")
+	test.AssertEqual(t, items[0].LatestVersion, "2022")
+	test.AssertEqual(t, items[1].Status, OutdatedStatusUpToDate)
+	test.AssertEqual(t, items[1].LatestVersion, "2022")
+	test.AssertEqual(t, items[2].Status, OutdatedStatusNotFound)
+	test.AssertEqual(t, items[2].LatestVersion, "")
+	test.AssertEqual(t, items[3].Status, OutdatedStatusUnreachable)
+	test.AssertEqual(t, items[3].Error, huggingface.ErrGated.Error())
+	test.AssertEqual(t, items[4].Status, OutdatedStatusPinned)
+	test.AssertEqual(t, items[4].LatestVersion, "")
+	test.AssertEqual(t, items[5].Status, OutdatedStatusUnknown)
+}
+
+// TestOutdatedController_Run_InvalidOutput tests that an unknown output format returns an error.
+func TestOutdatedController_Run_InvalidOutput(t *testing.T) {
+	// Execute
+	_, err := OutdatedController{Output: "xml"}.Run([]string{})
+
+	// Assert
+	test.AssertNotEqual(t, err, nil)
+}
+
+// TestOutdatedController_Run_Pending tests that pending updates are reported without downloading anything.
+func TestOutdatedController_Run_Pending(t *testing.T) {
+	// Init
+	ts := test.TestSuite{}
+	_ = ts.CreateFullTestSuite(t)
+	defer ts.CleanTestSuite(t)
+	err := setupConfigFile(model.Models{GetModel(1, "2021"), GetModel(2, "2022")})
+	test.AssertEqual(t, err, nil)
+	app.SetHuggingFace(&huggingface.MockHuggingFace{GetModelResult: huggingface.Model{LastModified: "2022", Sha: "sha2022", LibraryName: huggingface.TRANSFORMERS}})
+
+	// Execute
+	pending, err := OutdatedController{Output: "json"}.Run([]string{})
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, pending, true)
+}
+
+// TestOutdatedController_Run_UpToDate tests that no update is pending when every model is up to date.
+func TestOutdatedController_Run_UpToDate(t *testing.T) {
+	// Init
+	ts := test.TestSuite{}
+	_ = ts.CreateFullTestSuite(t)
+	defer ts.CleanTestSuite(t)
+	err := setupConfigFile(model.Models{GetModel(1, "2021"), GetModel(2, "2022")})
+	test.AssertEqual(t, err, nil)
+	app.SetHuggingFace(&huggingface.MockHuggingFace{GetModelResult: huggingface.Model{LastModified: "2022", Sha: "sha2022", LibraryName: huggingface.TRANSFORMERS}})
+
+	// Execute
+	pending, err := OutdatedController{Output: "table"}.Run([]string{"model2"})
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, pending, false)
+}
+
+// TestOutdatedController_Run_Offline tests that the models aren't reported as not found when offline.
+func TestOutdatedController_Run_Offline(t *testing.T) {
+	// Init
+	app.SetOffline(true)
+	defer app.SetOffline(false)
+
+	// Execute
+	_, err := OutdatedController{Output: "json"}.Run([]string{})
+
+	// Assert
+	var offlineErr *app.OfflineError
+	test.AssertEqual(t, errors.As(err, &offlineErr), true)
+}
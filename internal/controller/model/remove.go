@@ -1,12 +1,15 @@
 package modelcontroller
 
 import (
+	"fmt"
 	"github.com/easy-model-fusion/emf-cli/internal/app"
 	"github.com/easy-model-fusion/emf-cli/internal/config"
+	"github.com/easy-model-fusion/emf-cli/internal/lockfile"
 	"github.com/easy-model-fusion/emf-cli/internal/model"
 	"github.com/easy-model-fusion/emf-cli/internal/sdk"
 	"github.com/easy-model-fusion/emf-cli/internal/ui"
 	"github.com/easy-model-fusion/emf-cli/internal/utils/stringutil"
+	"strings"
 )
 
 // RunModelRemove runs the model remove command
@@ -78,6 +81,12 @@ func removeModels(models model.Models, selectedModels []string) (warning string,
 		info = "There is no models to be removed."
 	} else {
 		warning, info, err = config.RemoveModelsByNames(models, selectedModels)
+		if err == nil {
+			// The removed models mustn't remain locked
+			if lockErr := lockfile.Refresh(); lockErr != nil {
+				warning = strings.TrimSpace(fmt.Sprintf("%s\nError while updating the lockfile: %s", warning, lockErr))
+			}
+		}
 	}
 
 	return warning, info, err
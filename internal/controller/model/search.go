@@ -0,0 +1,138 @@
+package modelcontroller
+
+import (
+	"fmt"
+	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/easy-model-fusion/emf-cli/internal/auth"
+	"github.com/easy-model-fusion/emf-cli/internal/hfinterface"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/outpututil"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/stringutil"
+	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
+	"os"
+)
+
+type SearchController struct {
+	Output      string
+	Author      string
+	Tag         string
+	PipelineTag string
+	Library     string
+	Sort        string
+	Limit       int
+	AccessToken string
+}
+
+// SearchItem represents a model found on the hub as displayed by the search command
+type SearchItem struct {
+	Name         string `json:"name" yaml:"name"`
+	Library      string `json:"library" yaml:"library"`
+	PipelineTag  string `json:"pipelineTag" yaml:"pipelineTag"`
+	Downloads    int    `json:"downloads" yaml:"downloads"`
+	Likes        int    `json:"likes" yaml:"likes"`
+	LastModified string `json:"lastModified" yaml:"lastModified"`
+}
+
+// Run runs the model search command
+func (sc SearchController) Run(args []string) error {
+	// Validate the output format before doing anything
+	format, err := outpututil.ParseFormat(sc.Output)
+	if err != nil {
+		return err
+	}
+
+	// Build the query parameters
+	params, err := sc.buildSearchParams(args)
+	if err != nil {
+		return err
+	}
+
+	// Search the models on the hub, with the default access token unless given
+	token, err := auth.ResolveToken(sc.AccessToken, "")
+	if err != nil {
+		return err
+	}
+	ctx, stop := app.InterruptContext()
+	models, err := hfinterface.SearchModels(ctx, params, token.Value)
+	stop()
+	if err != nil {
+		return err
+	}
+
+	// Display the models
+	items := buildSearchItems(models)
+	if format.IsStructured() {
+		return outpututil.Write(os.Stdout, format, items)
+	}
+	if len(items) == 0 {
+		app.UI().Info().Println("No model matches the search.")
+		return nil
+	}
+	app.UI().DisplayTable(buildSearchTable(items))
+	return nil
+}
+
+// buildSearchParams validates the flags and maps them to the hub query parameters
+func (sc SearchController) buildSearchParams(args []string) (params huggingface.SearchParams, err error) {
+	if len(args) > 1 {
+		return params, fmt.Errorf("you can enter only one search query at a time")
+	}
+	if len(args) == 1 {
+		params.Search = args[0]
+	}
+
+	if sc.PipelineTag != "" && !stringutil.SliceContainsItem(huggingface.AllTagsString(), sc.PipelineTag) {
+		return params, fmt.Errorf("invalid pipeline tag '%s', expected one of %s", sc.PipelineTag,
+			stringutil.SliceToArgsFormat(huggingface.AllTagsString()))
+	}
+	if sc.Library != "" && !stringutil.SliceContainsItem(huggingface.AllModulesString(), sc.Library) {
+		return params, fmt.Errorf("invalid library '%s', expected one of %s", sc.Library,
+			stringutil.SliceToArgsFormat(huggingface.AllModulesString()))
+	}
+	if sc.Sort != "" && !stringutil.SliceContainsItem(huggingface.AllSortsString(), sc.Sort) {
+		return params, fmt.Errorf("invalid sort '%s', expected one of %s", sc.Sort,
+			stringutil.SliceToArgsFormat(huggingface.AllSortsString()))
+	}
+	if sc.Limit < 0 {
+		return params, fmt.Errorf("the limit must be a positive number")
+	}
+
+	params.Author = sc.Author
+	params.Tag = sc.Tag
+	params.PipelineTag = huggingface.PipelineTag(sc.PipelineTag)
+	params.Library = huggingface.Module(sc.Library)
+	params.Sort = huggingface.Sort(sc.Sort)
+	params.Limit = sc.Limit
+	return params, nil
+}
+
+// buildSearchItems maps the hub models to the items to be displayed
+func buildSearchItems(models huggingface.Models) []SearchItem {
+	items := []SearchItem{}
+	for _, current := range models {
+		items = append(items, SearchItem{
+			Name:         current.Name,
+			Library:      string(current.LibraryName),
+			PipelineTag:  string(current.PipelineTag),
+			Downloads:    current.Downloads,
+			Likes:        current.Likes,
+			LastModified: current.LastModified,
+		})
+	}
+	return items
+}
+
+// buildSearchTable builds the table rows (header included) for the items
+func buildSearchTable(items []SearchItem) [][]string {
+	data := [][]string{{"Name", "Library", "Pipeline tag", "Downloads", "Likes", "Last modified"}}
+	for _, item := range items {
+		data = append(data, []string{
+			item.Name,
+			item.Library,
+			item.PipelineTag,
+			fmt.Sprint(item.Downloads),
+			fmt.Sprint(item.Likes),
+			item.LastModified,
+		})
+	}
+	return data
+}
@@ -0,0 +1,91 @@
+package modelcontroller
+
+import (
+	"errors"
+	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
+	"github.com/easy-model-fusion/emf-cli/test"
+	"github.com/easy-model-fusion/emf-cli/test/mock"
+	"testing"
+)
+
+// TestSearchController_BuildSearchParams_Success tests that the flags are mapped to the query parameters.
+func TestSearchController_BuildSearchParams_Success(t *testing.T) {
+	// Init
+	sc := SearchController{Author: "org", Tag: "text-to-image", PipelineTag: "text-generation", Library: "diffusers", Sort: "likes", Limit: 5}
+
+	// Execute
+	params, err := sc.buildSearchParams([]string{"anime"})
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, params, huggingface.SearchParams{
+		Search:      "anime",
+		Author:      "org",
+		Tag:         "text-to-image",
+		PipelineTag: huggingface.TextGeneration,
+		Library:     huggingface.DIFFUSERS,
+		Sort:        huggingface.SortLikes,
+		Limit:       5,
+	})
+}
+
+// TestSearchController_BuildSearchParams_Invalid tests that invalid flags return an error.
+func TestSearchController_BuildSearchParams_Invalid(t *testing.T) {
+	_, err := SearchController{Library: "invalid"}.buildSearchParams([]string{})
+	test.AssertNotEqual(t, err, nil)
+	_, err = SearchController{PipelineTag: "invalid"}.buildSearchParams([]string{})
+	test.AssertNotEqual(t, err, nil)
+	_, err = SearchController{Sort: "invalid"}.buildSearchParams([]string{})
+	test.AssertNotEqual(t, err, nil)
+	_, err = SearchController{Limit: -1}.buildSearchParams([]string{})
+	test.AssertNotEqual(t, err, nil)
+	_, err = SearchController{}.buildSearchParams([]string{"query1", "query2"})
+	test.AssertNotEqual(t, err, nil)
+}
+
+// TestSearchController_Run_Success tests that the supported models found on the hub are displayed.
+func TestSearchController_Run_Success(t *testing.T) {
+	// Init
+	var tables [][][]string
+	app.SetUI(mock.MockUI{Tables: &tables})
+	app.SetHuggingFace(&huggingface.MockHuggingFace{GetModelsResult: huggingface.Models{
+		{Name: "model1", LibraryName: huggingface.DIFFUSERS},
+		{Name: "model2", LibraryName: "invalid"},
+	}})
+
+	// Execute
+	err := SearchController{Output: "table"}.Run([]string{"model"})
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, len(tables), 1)
+	test.AssertEqual(t, len(tables[0]), 2)
+	test.AssertEqual(t, tables[0][1][0], "model1")
+}
+
+// TestSearchController_Run_Failure tests that a hub failure is returned.
+func TestSearchController_Run_Failure(t *testing.T) {
+	// Init
+	app.SetHuggingFace(&huggingface.MockHuggingFace{Error: errors.New("hub error")})
+
+	// Execute
+	err := SearchController{Output: "json"}.Run([]string{"model"})
+
+	// Assert
+	test.AssertNotEqual(t, err, nil)
+}
+
+// TestBuildSearchTable tests that the table contains a header and one row per model.
+func TestBuildSearchTable(t *testing.T) {
+	// Init
+	items := buildSearchItems(huggingface.Models{{Name: "model1", LibraryName: huggingface.DIFFUSERS, Downloads: 10}})
+
+	// Execute
+	data := buildSearchTable(items)
+
+	// Assert
+	test.AssertEqual(t, len(data), 2)
+	test.AssertEqual(t, data[1][0], "model1")
+	test.AssertEqual(t, data[1][3], "10")
+}
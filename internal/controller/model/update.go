@@ -1,42 +1,82 @@
 package modelcontroller
 
 import (
+	"errors"
 	"fmt"
 	"github.com/easy-model-fusion/emf-cli/internal/app"
 	"github.com/easy-model-fusion/emf-cli/internal/config"
 	"github.com/easy-model-fusion/emf-cli/internal/hfinterface"
+	"github.com/easy-model-fusion/emf-cli/internal/lockfile"
 	"github.com/easy-model-fusion/emf-cli/internal/model"
+	"github.com/easy-model-fusion/emf-cli/internal/plan"
 	"github.com/easy-model-fusion/emf-cli/internal/sdk"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/outpututil"
 	"github.com/easy-model-fusion/emf-cli/internal/utils/resultutil"
 	"github.com/easy-model-fusion/emf-cli/internal/utils/stringutil"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/workerutil"
+	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
+	"slices"
 )
 
+// errUnknownVersion is reported for the models whose downloaded commit sha wasn't recorded
+var errUnknownVersion = errors.New("its current version is unknown, use --to to move it to a revision")
+
 // RunModelUpdate runs the model update command
-func RunModelUpdate(args []string, yes bool, accessToken string) {
+// The models are moved to the given revision if any, to the latest one otherwise
+// At most jobs models are downloaded at the same time.
+func RunModelUpdate(args []string, yes bool, accessToken string, revision string, jobs int) {
 	// Process update operation with given arguments
-	result := processUpdate(args, yes, accessToken)
+	result := processUpdate(args, yes, accessToken, revision, jobs)
 
 	// Display messages to user
 	result.Display("Operation succeeded.", "Operation failed.")
 }
 
 // processUpdate processes the update model operation
-func processUpdate(args []string, yes bool, accessToken string) (result resultutil.ExecutionResult) {
-	// Load the configuration file
-	err := config.GetViperConfig(config.FilePath)
-	if err != nil {
-		result.SetError(err)
+func processUpdate(args []string, yes bool, accessToken string, revision string, jobs int) (result resultutil.ExecutionResult) {
+	// Get the selected models available for an update
+	modelsToUpdate, selected, result := selectUpdatableModels(args, accessToken, revision)
+	if result.Error != nil {
 		return result
 	}
 
 	// Request an update suggestion of the client when needed
 	sdk.SendUpdateSuggestion()
 
+	// Verify if the user selected some models to update
+	if !selected {
+		result.AddInfos([]string{"There is no models to be updated."})
+		return result
+	}
+
+	// Processing filtered models for an update
+	warningMessages, err := updateModels(modelsToUpdate, yes, accessToken, jobs)
+	result.AddWarnings(warningMessages)
+	result.SetError(err)
+	return result
+}
+
+// selectUpdatableModels loads the configuration file and returns the requested models available for an update
+// The models are selected through the args or through a multiselect of the models already downloaded from huggingface.
+func selectUpdatableModels(args []string, accessToken string, revision string) (modelsToUpdate model.Models, selected bool, result resultutil.ExecutionResult) {
+	// The latest versions can't be fetched offline
+	if app.Offline() {
+		result.SetError(app.NewOfflineError("checking for model updates"))
+		return modelsToUpdate, false, result
+	}
+
+	// Load the configuration file
+	err := config.GetViperConfig(config.FilePath)
+	if err != nil {
+		result.SetError(err)
+		return modelsToUpdate, false, result
+	}
+
 	// Get all models from configuration file
 	configModels, err := config.GetModels()
 	if err != nil {
 		result.SetError(err)
-		return result
+		return modelsToUpdate, false, result
 	}
 
 	// Keep the downloaded models coming from huggingface (i.e. those that could potentially be updated)
@@ -53,44 +93,102 @@ func processUpdate(args []string, yes bool, accessToken string) (result resultut
 		// Remove all the duplicates
 		selectedModelNames = stringutil.SliceRemoveDuplicates(args)
 	}
+	if len(selectedModelNames) == 0 {
+		return modelsToUpdate, false, result
+	}
 
-	// Verify if the user selected some models to update
-	if len(selectedModelNames) > 0 {
-		var warning string
-		// Filter selected models to only keep those available for an update
-		modelsToUpdate, notFoundModelNames, upToDateModelNames := getUpdatableModels(selectedModelNames, hfModelsAvailable, accessToken)
-
-		// Indicate the models that couldn't be found
-		if len(notFoundModelNames) > 0 {
-			warning = fmt.Sprintf("The following models(s) couldn't be found "+
-				"and were ignored : %s", notFoundModelNames)
-			result.AddWarnings([]string{warning})
+	// Filter selected models to only keep those available for an update
+	modelsToUpdate, notFoundModelNames, upToDateModelNames, pinnedModelNames, failures :=
+		getUpdatableModels(selectedModelNames, hfModelsAvailable, accessToken, revision)
+
+	// Indicate the models that couldn't be found
+	if len(notFoundModelNames) > 0 {
+		result.AddWarnings([]string{fmt.Sprintf("The following models(s) couldn't be found "+
+			"and were ignored : %s", notFoundModelNames)})
+	}
+	// Indicate the models that couldn't be fetched (i.e. gated or rate limited)
+	for _, name := range selectedModelNames {
+		if err, failed := failures[name]; failed {
+			result.AddWarnings([]string{fmt.Sprintf("Model %s was ignored : %s", name, err)})
 		}
-		// Indicate the models that are already up-to-date
-		if len(upToDateModelNames) > 0 {
-			result.AddInfos([]string{fmt.Sprintf("The following model(s) are already up to date "+
-				"and were ignored : %s", upToDateModelNames)})
+	}
+	// Indicate the models pinned to their revision
+	if len(pinnedModelNames) > 0 {
+		result.AddInfos([]string{fmt.Sprintf("The following model(s) are pinned to their revision "+
+			"and were ignored, use --to to move them : %s", pinnedModelNames)})
+	}
+	// Indicate the models that are already up-to-date
+	if len(upToDateModelNames) > 0 {
+		result.AddInfos([]string{fmt.Sprintf("The following model(s) are already up to date "+
+			"and were ignored : %s", upToDateModelNames)})
+	}
+
+	return modelsToUpdate, true, result
+}
+
+// RunModelUpdatePlan displays what the model update command would change, without downloading or writing anything
+func RunModelUpdatePlan(args []string, yes bool, accessToken string, revision string, output string) error {
+	format, err := outpututil.ParseFormat(output)
+	if err != nil {
+		return err
+	}
+
+	result, err := buildUpdatePlan(args, yes, accessToken, revision)
+	if err != nil {
+		return err
+	}
+
+	return result.Display(format)
+}
+
+// buildUpdatePlan computes the downloads and the configuration changes of the update
+// Unless yes is set, the user is still asked to confirm every model and to select its tokenizers.
+func buildUpdatePlan(args []string, yes bool, accessToken string, revision string) (result plan.Plan, err error) {
+	result = plan.New()
+	modelsToUpdate, _, selection := selectUpdatableModels(args, accessToken, revision)
+	result.AddWarnings(selection.Warnings)
+	if selection.Error != nil {
+		return result, selection.Error
+	}
+
+	for _, current := range modelsToUpdate {
+		install, tokenizerNames, err := current.ConfirmUpdate(yes)
+		if err != nil {
+			return result, err
+		}
+		if !install {
+			continue
 		}
 
-		// Processing filtered models for an update
-		var warningMessages []string
-		warningMessages, err = updateModels(modelsToUpdate, yes, accessToken)
-		result.AddWarnings(warningMessages)
-		result.SetError(err)
-	} else {
-		result.AddInfos([]string{"There is no models to be updated."})
+		download := plan.Download{Model: current.Name, Revision: current.Revision, Tokenizers: tokenizerNames}
+		ctx, stop := app.InterruptContext()
+		download.EstimatedSize, err = current.EstimateDownloadSize(ctx, accessToken)
+		stop()
+		if err != nil {
+			result.AddWarnings([]string{fmt.Sprintf("The download size of '%s' couldn't be estimated : %s", current.Name, err)})
+		}
+		result.Downloads = append(result.Downloads, download)
+		result.Config = append(result.Config, plan.ConfigEntry{Model: current.Name, Action: plan.ConfigUpdate, Tokenizers: tokenizerNames})
 	}
 
-	return result
+	return result, nil
 }
 
-// getUpdatableModels returns the models available for an update
-func getUpdatableModels(modelNames []string, hfModelsAvailable model.Models, accessToken string) (
-	modelsToUpdate model.Models, notFoundModelNames, upToDateModelNames []string) {
+// getUpdatableModels returns the models available for an update to the given revision (the latest one by default)
+// The models pinned to their revision are only moved to a given revision.
+// The models that couldn't be fetched for another reason than not existing are reported through the failures,
+// as well as the models whose current version is unknown when no revision is given.
+func getUpdatableModels(modelNames []string, hfModelsAvailable model.Models, accessToken string, revision string) (
+	modelsToUpdate model.Models, notFoundModelNames, upToDateModelNames, pinnedModelNames []string, failures map[string]error) {
 
 	// Bind the downloaded models coming from huggingface to a map for faster lookup
 	// Used to check whether a model has already been downloaded
 	mapHfModelsAvailable := hfModelsAvailable.Map()
+	failures = make(map[string]error)
+
+	// The hub calls are stopped on interruption, the remaining models being reported as failures
+	ctx, stop := app.InterruptContext()
+	defer stop()
 
 	// Check which model can be updated
 	for _, name := range modelNames {
@@ -103,25 +201,41 @@ func getUpdatableModels(modelNames []string, hfModelsAvailable model.Models, acc
 			continue
 		}
 
-		// Fetching model from huggingface
-		token := configModel.AccessToken
-		if accessToken != "" {
-			token = accessToken
+		// A pinned model isn't updated to the latest revision
+		if configModel.Pinned && revision == "" {
+			pinnedModelNames = append(pinnedModelNames, name)
+			continue
 		}
-		huggingfaceModel, err := hfinterface.GetModelById(name, token)
+
+		// Fetching model from huggingface
+		token, err := configModel.ResolveAccessToken(accessToken)
 		if err != nil {
+			failures[name] = err
+			continue
+		}
+		huggingfaceModel, err := hfinterface.GetModelByRevision(ctx, name, revision, token)
+		if errors.Is(err, huggingface.ErrNotFound) {
 			// Model not found : nothing more to do here, skipping to the next one
 			notFoundModelNames = append(notFoundModelNames, name)
 			continue
+		} else if err != nil {
+			failures[name] = err
+			continue
 		}
 
 		// Fetching succeeded : processing the response
 		// Map API response to model.Model
 		modelMapped := model.FromHuggingfaceModel(huggingfaceModel)
 
-		if configModel.Version != modelMapped.Version {
-			// Model already configured but not up-to-date
+		same, known := configModel.IsSameVersion(modelMapped)
+		if !known && revision == "" {
+			// The downloaded commit sha wasn't recorded : whether the model is up-to-date can't be told
+			failures[name] = errUnknownVersion
+		} else if !same {
+			// Model already configured but not up-to-date, a given revision pinning the model to it
 			configModel.Version = modelMapped.Version
+			configModel.Revision = modelMapped.Revision
+			configModel.Pinned = revision != ""
 			modelsToUpdate = append(modelsToUpdate, configModel)
 		} else {
 			// Model already up-to-date, nothing more to do here
@@ -129,29 +243,62 @@ func getUpdatableModels(modelNames []string, hfModelsAvailable model.Models, acc
 		}
 	}
 
-	return modelsToUpdate, notFoundModelNames, upToDateModelNames
+	return modelsToUpdate, notFoundModelNames, upToDateModelNames, pinnedModelNames, failures
 }
 
-// updateModels updates the given models
-func updateModels(modelsToUpdate model.Models, yes bool, accessToken string) (warnings []string, err error) {
-	// Try to update all the given models
+// updateModels updates the given models, downloading at most jobs models at the same time
+func updateModels(modelsToUpdate model.Models, yes bool, accessToken string, jobs int) (warnings []string, err error) {
+	// The user is asked about every model before any download starts
 	var failedModels []string
-	var updatedModels model.Models
+	var confirmedModels model.Models
+	var confirmedTokenizerNames [][]string
 	for _, current := range modelsToUpdate {
-		var success bool
-		warnings, success, err = current.Update(yes, accessToken)
+		install, tokenizerNames, err := current.ConfirmUpdate(yes)
 		if err != nil {
 			return warnings, err
 		}
+		if !install {
+			failedModels = append(failedModels, current.Name)
+			continue
+		}
+		confirmedModels = append(confirmedModels, current)
+		confirmedTokenizerNames = append(confirmedTokenizerNames, tokenizerNames)
+	}
+
+	// Try to update all the confirmed models
+	results := make([]resultutil.ExecutionResult, len(confirmedModels))
+	successes := make([]bool, len(confirmedModels))
+	app.SetConcurrentDownloads(min(jobs, len(confirmedModels)) > 1)
+	defer app.SetConcurrentDownloads(false)
+	skipped := workerutil.Run(jobs, len(confirmedModels), func(index int) {
+		warningMessages, success, updateErr := confirmedModels[index].ExecuteUpdate(confirmedTokenizerNames[index], accessToken)
+		results[index].AddWarnings(warningMessages)
+		results[index].SetError(updateErr)
+		successes[index] = success
+	})
+	if len(skipped) > 0 {
+		err = errors.New("download cancelled manually")
+	}
 
-		if !success {
+	// Collecting the results in the models order, ignoring the models that were never processed
+	var updatedModels model.Models
+	for index, current := range confirmedModels {
+		if slices.Contains(skipped, index) {
+			continue
+		}
+		warnings = append(warnings, results[index].Warnings...)
+		if results[index].Error != nil {
+			if err == nil {
+				err = results[index].Error
+			}
+		} else if !successes[index] {
 			failedModels = append(failedModels, current.Name)
 		} else {
 			updatedModels = append(updatedModels, current)
 		}
 	}
 
-	// Update models' configuration
+	// Update models' configuration, including the models updated before a cancellation
 	if len(updatedModels) > 0 {
 		spinner := app.UI().StartSpinner("Updating configuration file...")
 		err := config.AddModels(updatedModels)
@@ -159,11 +306,19 @@ func updateModels(modelsToUpdate model.Models, yes bool, accessToken string) (wa
 			spinner.Fail(fmt.Sprintf("Error while updating the configuration file: %s", err))
 		} else {
 			spinner.Success()
+
+			// Record the new resolved revisions
+			spinner = app.UI().StartSpinner("Updating lockfile...")
+			if lockErr := lockfile.Refresh(); lockErr != nil {
+				spinner.Warning(fmt.Sprintf("Error while updating the lockfile: %s", lockErr))
+			} else {
+				spinner.Success()
+			}
 		}
 	}
 
 	// Displaying the downloads that failed
-	if len(failedModels) > 0 {
+	if len(failedModels) > 0 && err == nil {
 		err = fmt.Errorf("the following models(s) couldn't be downloaded : %s", failedModels)
 	}
 
@@ -1,11 +1,13 @@
 package modelcontroller
 
 import (
+	"errors"
 	"fmt"
 	"github.com/easy-model-fusion/emf-cli/internal/app"
 	"github.com/easy-model-fusion/emf-cli/internal/config"
 	downloadermodel "github.com/easy-model-fusion/emf-cli/internal/downloader/model"
 	"github.com/easy-model-fusion/emf-cli/internal/model"
+	"github.com/easy-model-fusion/emf-cli/internal/plan"
 	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
 	"github.com/easy-model-fusion/emf-cli/test"
 	"github.com/easy-model-fusion/emf-cli/test/dmock"
@@ -26,7 +28,7 @@ func TestRunModelUpdate_Success(t *testing.T) {
 	args = append(args, "model4")
 
 	// Create hugging face mock
-	huggingFaceInterface := huggingface.MockHuggingFace{GetModelResult: huggingface.Model{LastModified: "2022", LibraryName: huggingface.TRANSFORMERS}}
+	huggingFaceInterface := huggingface.MockHuggingFace{GetModelResult: huggingface.Model{LastModified: "2022", Sha: "sha2022", LibraryName: huggingface.TRANSFORMERS}}
 	app.SetHuggingFace(&huggingFaceInterface)
 
 	// Create Ui mock
@@ -45,7 +47,7 @@ func TestRunModelUpdate_Success(t *testing.T) {
 	test.AssertEqual(t, err, nil, "No error expected on setting configuration file")
 
 	// Process update
-	RunModelUpdate(args, false, "")
+	RunModelUpdate(args, false, "", "", 1)
 	updatedModels, err := config.GetModels()
 
 	// Assertions
@@ -69,7 +71,7 @@ func TestRunModelUpdate_Fail(t *testing.T) {
 	args = append(args, "model4")
 
 	// Create hugging face mock
-	huggingFaceInterface := huggingface.MockHuggingFace{GetModelResult: huggingface.Model{LastModified: "2022", LibraryName: huggingface.TRANSFORMERS}}
+	huggingFaceInterface := huggingface.MockHuggingFace{GetModelResult: huggingface.Model{LastModified: "2022", Sha: "sha2022", LibraryName: huggingface.TRANSFORMERS}}
 	app.SetHuggingFace(&huggingFaceInterface)
 
 	// Create Ui mock
@@ -88,7 +90,7 @@ func TestRunModelUpdate_Fail(t *testing.T) {
 	test.AssertEqual(t, err, nil, "No error expected on setting configuration file")
 
 	// Process update
-	RunModelUpdate(args, false, "")
+	RunModelUpdate(args, false, "", "", 1)
 	updatedModels, err := config.GetModels()
 
 	// Assertions
@@ -112,7 +114,7 @@ func TestProcessUpdate(t *testing.T) {
 	args = append(args, "model4")
 
 	// Create hugging face mock
-	huggingFaceInterface := huggingface.MockHuggingFace{GetModelResult: huggingface.Model{LastModified: "2022", LibraryName: huggingface.TRANSFORMERS}}
+	huggingFaceInterface := huggingface.MockHuggingFace{GetModelResult: huggingface.Model{LastModified: "2022", Sha: "sha2022", LibraryName: huggingface.TRANSFORMERS}}
 	app.SetHuggingFace(&huggingFaceInterface)
 
 	// Create Ui mock
@@ -131,7 +133,7 @@ func TestProcessUpdate(t *testing.T) {
 	test.AssertEqual(t, err, nil, "No error expected on setting configuration file")
 
 	// Process update
-	result := processUpdate(args, false, "")
+	result := processUpdate(args, false, "", "", 1)
 
 	// Assertions
 	test.AssertEqual(t, result.Error, nil, "No error expected")
@@ -139,6 +141,42 @@ func TestProcessUpdate(t *testing.T) {
 	test.AssertEqual(t, result.Infos[0], "The following model(s) are already up to date and were ignored : [model3]", "Information message expected")
 }
 
+// Tests buildUpdatePlan lists the models to update without downloading them
+func TestBuildUpdatePlan(t *testing.T) {
+	// Init
+	var models model.Models
+	models = append(models, GetModel(1, "2021"))
+	models = append(models, GetModel(2, "2022"))
+	models = append(models, GetModel(3, "2022"))
+	args := []string{"model1", "model3", "model4"}
+	huggingFaceInterface := huggingface.MockHuggingFace{GetModelResult: huggingface.Model{LastModified: "2022", Sha: "sha2022", LibraryName: huggingface.TRANSFORMERS,
+		Siblings: []huggingface.Sibling{{RFileName: "config.json", Size: 1}, {RFileName: "model.safetensors", Size: 10}}}}
+	app.SetHuggingFace(&huggingFaceInterface)
+	app.SetUI(mock.MockUI{UserConfirmationResult: true})
+	downloader := dmock.MockDownloader{DownloaderError: fmt.Errorf("no download expected")}
+	app.SetDownloader(&downloader)
+	ts := test.TestSuite{}
+	_ = ts.CreateFullTestSuite(t)
+	defer ts.CleanTestSuite(t)
+	err := setupConfigFile(models)
+	test.AssertEqual(t, err, nil, "No error expected on setting configuration file")
+
+	// Execute
+	result, err := buildUpdatePlan(args, true, "", "")
+
+	// Assertions
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, result.Warnings[0], "The following models(s) couldn't be found and were ignored : [model4]")
+	test.AssertEqual(t, len(result.Downloads), 1)
+	test.AssertEqual(t, result.Downloads[0].Model, "model1")
+	test.AssertEqual(t, result.Downloads[0].EstimatedSize, int64(11))
+	test.AssertEqual(t, result.Config[0].Action, plan.ConfigUpdate)
+	test.AssertEqual(t, downloader.Args.ModelName, "")
+	configModels, err := config.GetModels()
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, configModels[0].Version, "2021")
+}
+
 // Tests processUpdate with no args
 func TestProcessUpdate_WithNoArgs(t *testing.T) {
 	// Init
@@ -152,7 +190,7 @@ func TestProcessUpdate_WithNoArgs(t *testing.T) {
 	expectedSelections = append(expectedSelections, "model3")
 
 	// Create hugging face mock
-	huggingFaceInterface := huggingface.MockHuggingFace{GetModelResult: huggingface.Model{LastModified: "2022", LibraryName: huggingface.TRANSFORMERS}}
+	huggingFaceInterface := huggingface.MockHuggingFace{GetModelResult: huggingface.Model{LastModified: "2022", Sha: "sha2022", LibraryName: huggingface.TRANSFORMERS}}
 	app.SetHuggingFace(&huggingFaceInterface)
 
 	// Create Ui mock
@@ -171,7 +209,7 @@ func TestProcessUpdate_WithNoArgs(t *testing.T) {
 	test.AssertEqual(t, err, nil, "No error expected on setting configuration file")
 
 	// Process update
-	result := processUpdate(args, false, "")
+	result := processUpdate(args, false, "", "", 1)
 
 	// Assertions
 	test.AssertEqual(t, result.Error, nil, "No error expected")
@@ -190,7 +228,7 @@ func TestProcessUpdate_WithNoModelsSelected(t *testing.T) {
 	var expectedSelections []string
 
 	// Create hugging face mock
-	huggingFaceInterface := huggingface.MockHuggingFace{GetModelResult: huggingface.Model{LastModified: "2022", LibraryName: huggingface.TRANSFORMERS}}
+	huggingFaceInterface := huggingface.MockHuggingFace{GetModelResult: huggingface.Model{LastModified: "2022", Sha: "sha2022", LibraryName: huggingface.TRANSFORMERS}}
 	app.SetHuggingFace(&huggingFaceInterface)
 
 	// Create Ui mock
@@ -209,7 +247,7 @@ func TestProcessUpdate_WithNoModelsSelected(t *testing.T) {
 	test.AssertEqual(t, err, nil, "No error expected on setting configuration file")
 
 	// Process update
-	result := processUpdate(args, false, "")
+	result := processUpdate(args, false, "", "", 1)
 
 	// Assertions
 	test.AssertEqual(t, result.Error, nil, "No error expected")
@@ -227,7 +265,7 @@ func TestProcessUpdate_WithErrorOnLoadingConfigurationFile(t *testing.T) {
 	app.SetUI(ui)
 
 	// Process update
-	result := processUpdate(args, false, "")
+	result := processUpdate(args, false, "", "", 1)
 
 	// Assertions
 	test.AssertNotEqual(t, result.Error, nil, "An error is expected")
@@ -285,11 +323,11 @@ func TestGetUpdatableModels(t *testing.T) {
 	modelNames = append(modelNames, "model4")
 
 	// Create hugging face mock
-	huggingFaceInterface := huggingface.MockHuggingFace{GetModelResult: huggingface.Model{LastModified: "2022", LibraryName: huggingface.TRANSFORMERS}}
+	huggingFaceInterface := huggingface.MockHuggingFace{GetModelResult: huggingface.Model{LastModified: "2022", Sha: "sha2022", LibraryName: huggingface.TRANSFORMERS}}
 	app.SetHuggingFace(&huggingFaceInterface)
 
 	// get updatable models
-	modelsToUpdate, notFoundModelNames, upToDateModelNames := getUpdatableModels(modelNames, hfModelsAvailable, "")
+	modelsToUpdate, notFoundModelNames, upToDateModelNames, _, failures := getUpdatableModels(modelNames, hfModelsAvailable, "", "")
 
 	// Assertions
 	test.AssertEqual(t, len(modelsToUpdate), 1)
@@ -299,6 +337,88 @@ func TestGetUpdatableModels(t *testing.T) {
 	test.AssertEqual(t, len(upToDateModelNames), 2)
 	test.AssertEqual(t, upToDateModelNames[0], modelNames[1])
 	test.AssertEqual(t, upToDateModelNames[1], modelNames[2])
+	test.AssertEqual(t, len(failures), 0)
+}
+
+// Tests getUpdatableModels comparing the commit sha of the models
+func TestGetUpdatableModels_WithRevision(t *testing.T) {
+	// Initialize models
+	var hfModelsAvailable model.Models
+	model1 := GetModel(1, "2022")
+	model1.Revision = "sha1"
+	model2 := GetModel(2, "2021")
+	model2.Revision = "sha2"
+	hfModelsAvailable = append(hfModelsAvailable, model1, model2)
+	modelNames := []string{"model1", "model2"}
+
+	// Create hugging face mock
+	huggingFaceInterface := huggingface.MockHuggingFace{GetModelResult: huggingface.Model{LastModified: "2022", Sha: "sha2", LibraryName: huggingface.TRANSFORMERS}}
+	app.SetHuggingFace(&huggingFaceInterface)
+
+	// get updatable models to a given revision
+	modelsToUpdate, notFoundModelNames, upToDateModelNames, _, failures := getUpdatableModels(modelNames, hfModelsAvailable, "", "v2")
+
+	// Assertions
+	test.AssertEqual(t, len(modelsToUpdate), 1)
+	test.AssertEqual(t, modelsToUpdate[0].Name, "model1")
+	test.AssertEqual(t, modelsToUpdate[0].Revision, "sha2")
+	test.AssertEqual(t, len(notFoundModelNames), 0)
+	test.AssertEqual(t, len(upToDateModelNames), 1)
+	test.AssertEqual(t, upToDateModelNames[0], "model2")
+	test.AssertEqual(t, len(failures), 0)
+}
+
+// Tests getUpdatableModels only moving the pinned models to a given revision, which keeps them pinned
+func TestGetUpdatableModels_WithPinnedModel(t *testing.T) {
+	// Initialize models
+	model1 := GetModel(1, "2021")
+	model1.Pinned = true
+	hfModelsAvailable := model.Models{model1}
+	modelNames := []string{"model1"}
+
+	// Create hugging face mock
+	huggingFaceInterface := huggingface.MockHuggingFace{GetModelResult: huggingface.Model{LastModified: "2022", Sha: "sha2022", LibraryName: huggingface.TRANSFORMERS}}
+	app.SetHuggingFace(&huggingFaceInterface)
+
+	// get updatable models to the latest revision, then to a given revision
+	latestModels, _, _, pinnedModelNames, latestFailures := getUpdatableModels(modelNames, hfModelsAvailable, "", "")
+	revisionModels, _, _, revisionPinnedModelNames, revisionFailures := getUpdatableModels(modelNames, hfModelsAvailable, "", "v2")
+
+	// Assertions
+	test.AssertEqual(t, len(latestModels), 0)
+	test.AssertEqual(t, len(pinnedModelNames), 1)
+	test.AssertEqual(t, pinnedModelNames[0], "model1")
+	test.AssertEqual(t, len(latestFailures), 0)
+	test.AssertEqual(t, len(revisionModels), 1)
+	test.AssertEqual(t, revisionModels[0].Revision, "sha2022")
+	test.AssertEqual(t, revisionModels[0].Pinned, true)
+	test.AssertEqual(t, len(revisionPinnedModelNames), 0)
+	test.AssertEqual(t, len(revisionFailures), 0)
+}
+
+// Tests getUpdatableModels reporting the models whose commit sha is unknown, unless moved to a given revision
+func TestGetUpdatableModels_WithUnknownVersion(t *testing.T) {
+	// Initialize models
+	model1 := GetModel(1, "2022")
+	model1.Revision = ""
+	hfModelsAvailable := model.Models{model1}
+	modelNames := []string{"model1"}
+
+	// Create hugging face mock
+	huggingFaceInterface := huggingface.MockHuggingFace{GetModelResult: huggingface.Model{LastModified: "2022", Sha: "sha2022", LibraryName: huggingface.TRANSFORMERS}}
+	app.SetHuggingFace(&huggingFaceInterface)
+
+	// get updatable models to the latest revision, then to a given revision
+	latestModels, _, latestUpToDateModelNames, _, latestFailures := getUpdatableModels(modelNames, hfModelsAvailable, "", "")
+	revisionModels, _, _, _, revisionFailures := getUpdatableModels(modelNames, hfModelsAvailable, "", "v2")
+
+	// Assertions
+	test.AssertEqual(t, len(latestModels), 0)
+	test.AssertEqual(t, len(latestUpToDateModelNames), 0)
+	test.AssertEqual(t, errors.Is(latestFailures["model1"], errUnknownVersion), true)
+	test.AssertEqual(t, len(revisionModels), 1)
+	test.AssertEqual(t, revisionModels[0].Revision, "sha2022")
+	test.AssertEqual(t, len(revisionFailures), 0)
 }
 
 // Tests getUpdatableModels with model not found in hugging face
@@ -310,17 +430,38 @@ func TestGetUpdatableModels_WithModelNotFound(t *testing.T) {
 	modelNames = append(modelNames, "model1")
 
 	// Create hugging face mock
-	huggingFaceInterface := huggingface.MockHuggingFace{Error: fmt.Errorf("")}
+	huggingFaceInterface := huggingface.MockHuggingFace{Error: &huggingface.APIError{Err: huggingface.ErrNotFound}}
 	app.SetHuggingFace(&huggingFaceInterface)
 
 	// get updatable models
-	modelsToUpdate, notFoundModelNames, upToDateModelNames := getUpdatableModels(modelNames, hfModelsAvailable, "")
+	modelsToUpdate, notFoundModelNames, upToDateModelNames, _, failures := getUpdatableModels(modelNames, hfModelsAvailable, "", "")
 
 	// Assertions
 	test.AssertEqual(t, len(modelsToUpdate), 0)
 	test.AssertEqual(t, len(notFoundModelNames), 1)
 	test.AssertEqual(t, notFoundModelNames[0], modelNames[0])
 	test.AssertEqual(t, len(upToDateModelNames), 0)
+	test.AssertEqual(t, len(failures), 0)
+}
+
+// Tests getUpdatableModels with a model the hub refused to describe
+func TestGetUpdatableModels_WithModelUnreachable(t *testing.T) {
+	// Initialize models
+	hfModelsAvailable := model.Models{GetModel(1, "2021")}
+	modelNames := []string{"model1"}
+
+	// Create hugging face mock
+	huggingFaceInterface := huggingface.MockHuggingFace{Error: &huggingface.APIError{Err: huggingface.ErrRateLimited}}
+	app.SetHuggingFace(&huggingFaceInterface)
+
+	// get updatable models
+	modelsToUpdate, notFoundModelNames, upToDateModelNames, _, failures := getUpdatableModels(modelNames, hfModelsAvailable, "", "")
+
+	// Assertions
+	test.AssertEqual(t, len(modelsToUpdate), 0)
+	test.AssertEqual(t, len(notFoundModelNames), 0)
+	test.AssertEqual(t, len(upToDateModelNames), 0)
+	test.AssertEqual(t, errors.Is(failures["model1"], huggingface.ErrRateLimited), true)
 }
 
 // TestUpdateModels_Fail tests updateModels with succeeded update
@@ -338,7 +479,7 @@ func TestUpdateModels_Success(t *testing.T) {
 	app.SetDownloader(&downloader)
 
 	// Update models
-	_, err := updateModels(models, false, "")
+	_, err := updateModels(models, false, "", 1)
 
 	// Assertions
 	test.AssertEqual(t, nil, err)
@@ -366,7 +507,7 @@ func TestUpdateModels_SuccessWithConfigurationAdded(t *testing.T) {
 	app.SetDownloader(&downloader)
 
 	// Update models
-	_, err = updateModels(models, false, "")
+	_, err = updateModels(models, false, "", 1)
 
 	// Assertions
 	test.AssertEqual(t, nil, err)
@@ -383,7 +524,7 @@ func TestUpdateModels_Fail(t *testing.T) {
 	app.SetUI(ui)
 
 	// Update models
-	_, err := updateModels(models, false, "")
+	_, err := updateModels(models, false, "", 1)
 
 	// Assertions
 	test.AssertEqual(t, err.Error(), "the following models(s) couldn't be downloaded : [model1]")
@@ -397,5 +538,6 @@ func GetModel(id int, version string) model.Model {
 		Source:       model.HUGGING_FACE,
 		IsDownloaded: true,
 		Version:      version,
+		Revision:     "sha" + version,
 	}
 }
@@ -1,19 +1,39 @@
 package controller
 
 import (
+	"errors"
 	"fmt"
 	"github.com/easy-model-fusion/emf-cli/internal/app"
 	"github.com/easy-model-fusion/emf-cli/internal/config"
 	"github.com/easy-model-fusion/emf-cli/internal/downloader/model"
+	"github.com/easy-model-fusion/emf-cli/internal/lockfile"
 	"github.com/easy-model-fusion/emf-cli/internal/model"
+	"github.com/easy-model-fusion/emf-cli/internal/plan"
 	"github.com/easy-model-fusion/emf-cli/internal/sdk"
 	"github.com/easy-model-fusion/emf-cli/internal/utils/fileutil"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/outpututil"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/resultutil"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/workerutil"
 	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
+	"slices"
+	"strings"
 )
 
-type TidyController struct{}
+type TidyController struct {
+	// Frozen fails instead of updating the lockfile when the configuration file and the lockfile disagree
+	Frozen bool
+	// Jobs is the maximum number of models downloaded at the same time
+	Jobs int
+	// DryRun only displays what would be changed, using the Output format
+	DryRun bool
+	Output string
+}
 
 func (tc TidyController) RunTidy(yes bool, accessToken string) error {
+	if tc.DryRun {
+		return tc.runPlan(yes, accessToken)
+	}
+
 	// get all models from config file
 	err := config.GetViperConfig(config.FilePath)
 	if err != nil {
@@ -29,9 +49,19 @@ func (tc TidyController) RunTidy(yes bool, accessToken string) error {
 		return err
 	}
 
+	// Reproduce what was resolved in the lockfile
+	lock, warningMessages, err := tc.applyLock(models)
+	for _, warning := range warningMessages {
+		app.UI().Warning().Println(warning)
+	}
+	if err != nil {
+		app.UI().Error().Println(err.Error())
+		return err
+	}
+
 	// Tidy the models configured but not physically present on the device
 	app.UI().Info().Println("Verifying if all models are downloaded...")
-	warningMessages, err := tc.tidyModelsConfiguredButNotDownloaded(models, accessToken)
+	warningMessages, err = tc.tidyModelsConfiguredButNotDownloaded(models, accessToken)
 	if len(warningMessages) > 0 {
 		for _, warning := range warningMessages {
 			app.UI().Warning().Println(warning)
@@ -71,6 +101,85 @@ func (tc TidyController) RunTidy(yes bool, accessToken string) error {
 	}
 	spinner.Success()
 
+	// The downloaded files must be exactly the locked ones
+	if tc.Frozen {
+		spinner = app.UI().StartSpinner("Verifying the downloaded files against the lockfile...")
+		err = tc.verifyLockedFiles(models, lock)
+		if err != nil {
+			spinner.Fail(err.Error())
+			return err
+		}
+		spinner.Success()
+		return nil
+	}
+
+	// Record what was resolved
+	spinner = app.UI().StartSpinner("Updating lockfile...")
+	err = lockfile.Refresh()
+	if err != nil {
+		spinner.Fail(fmt.Sprintf("Error while updating the lockfile: %s", err))
+		return err
+	}
+	spinner.Success()
+
+	return nil
+}
+
+// applyLock pins the configured models to the revisions recorded in the lockfile
+// When frozen, the configuration file and the lockfile must agree
+func (tc TidyController) applyLock(models model.Models) (lock lockfile.Lock, warnings []string, err error) {
+	lock, exists, err := lockfile.Read()
+	if err != nil {
+		return lock, warnings, err
+	}
+	if !exists {
+		if tc.Frozen {
+			return lock, warnings, fmt.Errorf("lockfile %s is missing", lockfile.GetPath())
+		}
+		return lock, warnings, nil
+	}
+
+	differences := lock.Verify(models)
+	if len(differences) > 0 {
+		if tc.Frozen {
+			return lock, warnings, fmt.Errorf("the configuration file and the lockfile disagree :\n - %s",
+				strings.Join(differences, "\n - "))
+		}
+		for _, difference := range differences {
+			warnings = append(warnings, difference+" : the lockfile will be updated")
+		}
+	}
+
+	// Models configured without any revision are downloaded at their locked revision
+	mapLocked := lock.Map()
+	for i, current := range models {
+		if locked, exists := mapLocked[current.Name]; exists && current.Revision == "" {
+			models[i].Revision = locked.Revision
+		}
+	}
+
+	return lock, warnings, nil
+}
+
+// verifyLockedFiles verifies that the files of the downloaded models match the lockfile
+func (tc TidyController) verifyLockedFiles(models model.Models, lock lockfile.Lock) error {
+	var differences []string
+	mapLocked := lock.Map()
+	for _, current := range models.FilterWithIsDownloadedTrue() {
+		locked, exists := mapLocked[current.Name]
+		if !exists {
+			continue
+		}
+		modelDifferences, err := locked.VerifyFiles(current)
+		if err != nil {
+			return err
+		}
+		differences = append(differences, modelDifferences...)
+	}
+
+	if len(differences) > 0 {
+		return fmt.Errorf("the downloaded files don't match the lockfile :\n - %s", strings.Join(differences, "\n - "))
+	}
 	return nil
 }
 
@@ -79,29 +188,41 @@ func (tc TidyController) tidyModelsConfiguredButNotDownloaded(models model.Model
 	// filter the models that should be added to binary
 	models = models.FilterWithIsDownloadedOrAddToBinaryFileTrue()
 
-	// Search for the models that need to be downloaded
+	// Tidying the configured but not downloaded models and also processing their tokenizers
+	results := make([]resultutil.ExecutionResult, len(models))
+	successes := make([]bool, len(models))
+	cleans := make([]bool, len(models))
+	app.SetConcurrentDownloads(min(tc.Jobs, len(models)) > 1)
+	defer app.SetConcurrentDownloads(false)
+	skipped := workerutil.Run(tc.Jobs, len(models), func(index int) {
+		warningMessages, success, clean, tidyErr := models[index].TidyConfiguredModel(accessToken)
+		results[index].AddWarnings(warningMessages)
+		results[index].SetError(tidyErr)
+		successes[index] = success
+		cleans[index] = clean
+	})
+
+	if len(skipped) > 0 {
+		err = errors.New("download cancelled manually")
+	}
+
+	// Collecting the results in the models order, ignoring the models that were never processed
 	var downloadedModels model.Models
 	var failedModels []string
-
-	// Tidying the configured but not downloaded models and also processing their tokenizers
-	for _, current := range models {
-		var (
-			success, clean  bool
-			warningMessages []string
-		)
-		warningMessages, success, clean, err = current.TidyConfiguredModel(accessToken)
-		warnings = append(warnings, warningMessages...)
-		if err != nil {
-			return warnings, err
+	for index, current := range models {
+		if slices.Contains(skipped, index) {
+			continue
 		}
-
-		if !success {
+		warnings = append(warnings, results[index].Warnings...)
+		if results[index].Error != nil {
+			if err == nil {
+				err = results[index].Error
+			}
+		} else if !successes[index] {
 			failedModels = append(failedModels, current.Name)
-		} else if !clean {
+		} else if !cleans[index] {
 			downloadedModels = append(downloadedModels, current)
 		}
-
-		continue
 	}
 
 	// Displaying the downloads that failed
@@ -110,11 +231,11 @@ func (tc TidyController) tidyModelsConfiguredButNotDownloaded(models model.Model
 	}
 
 	if len(downloadedModels) > 0 {
-		// Add models to configuration file
+		// Add models to configuration file, including the models downloaded before a cancellation
 		spinner := app.UI().StartSpinner("Writing models to configuration file...")
-		err := config.AddModels(downloadedModels)
-		if err != nil {
-			spinner.Fail(fmt.Sprintf("Error while writing the models to the configuration file: %s", err))
+		configErr := config.AddModels(downloadedModels)
+		if configErr != nil {
+			spinner.Fail(fmt.Sprintf("Error while writing the models to the configuration file: %s", configErr))
 		} else {
 			spinner.Success()
 		}
@@ -125,8 +246,10 @@ func (tc TidyController) tidyModelsConfiguredButNotDownloaded(models model.Model
 // tidyModelsDownloadedButNotConfigured configuring the downloaded models that aren't configured in the configuration file
 // and then asks the user if he wants to delete them or add them to the configuration file
 func (tc TidyController) tidyModelsDownloadedButNotConfigured(configModels model.Models, yes bool, accessToken string) (warnings []string, err error) {
-	// Get the list of downloaded models
-	downloadedModels := model.BuildModelsFromDevice(accessToken)
+	// Get the list of downloaded models, the hub calls being stopped on interruption
+	ctx, stop := app.InterruptContext()
+	downloadedModels := model.BuildModelsFromDevice(ctx, accessToken)
+	stop()
 
 	// Building map for faster lookup
 	mapConfigModels := configModels.Map()
@@ -138,8 +261,13 @@ func (tc TidyController) tidyModelsDownloadedButNotConfigured(configModels model
 		// Checking if the downloaded model is already configured
 		configModel, configured := mapConfigModels[current.Name]
 
-		// Try to get model configuration
-		if current.Module != "" {
+		// Try to get model configuration, the configured class being kept when offline
+		if current.Module != "" && app.Offline() {
+			current.Class = configModel.Class
+			if current.Class == "" {
+				current.Class = current.GetModuleAutoPipelineClassName()
+			}
+		} else if current.Module != "" {
 			downloaderArgs := downloadermodel.Args{
 				ModelName:     current.Name,
 				ModelModule:   string(current.Module),
@@ -254,3 +382,164 @@ func (tc TidyController) regenerateCode(models model.Models) error {
 
 	return nil
 }
+
+// runPlan displays what tidy would change, without downloading, removing or writing anything
+func (tc TidyController) runPlan(yes bool, accessToken string) error {
+	format, err := outpututil.ParseFormat(tc.Output)
+	if err != nil {
+		app.UI().Error().Println(err.Error())
+		return err
+	}
+
+	result, err := tc.buildPlan(yes, accessToken)
+	if err != nil {
+		app.UI().Error().Println(err.Error())
+		return err
+	}
+
+	return result.Display(format)
+}
+
+// buildPlan computes the changes tidy would make
+// Unless yes is set, the user is still asked whether the downloaded models that aren't configured should be kept.
+func (tc TidyController) buildPlan(yes bool, accessToken string) (result plan.Plan, err error) {
+	result = plan.New()
+	if err = config.GetViperConfig(config.FilePath); err != nil {
+		return result, err
+	}
+
+	models, err := config.GetModels()
+	if err != nil {
+		return result, err
+	}
+
+	// Reproduce what was resolved in the lockfile
+	_, warnings, err := tc.applyLock(models)
+	result.AddWarnings(warnings)
+	if err != nil {
+		return result, err
+	}
+
+	// Models configured but not physically present on the device
+	if err = tc.planModelsConfiguredButNotDownloaded(models, accessToken, &result); err != nil {
+		return result, err
+	}
+
+	// Models physically present on the device but not configured
+	plannedModels := tc.planModelsDownloadedButNotConfigured(models, yes, accessToken, &result)
+
+	// Code generated for the configuration file as it would be
+	result.CodeChanged, err = config.ModelsPythonCodeChanged(plannedModels)
+	if err != nil {
+		result.AddWarnings([]string{fmt.Sprintf("The python code couldn't be generated : %s", err)})
+	}
+	return result, nil
+}
+
+// planModelsConfiguredButNotDownloaded adds the missing models and tokenizers to the downloads of the plan
+// The paths of the models are set to where they would be downloaded.
+func (tc TidyController) planModelsConfiguredButNotDownloaded(models model.Models, accessToken string, result *plan.Plan) error {
+	for index := range models {
+		if !models[index].IsDownloaded && !models[index].AddToBinaryFile {
+			continue
+		}
+
+		// Check if model is physically present on the device
+		models[index].UpdatePaths()
+		current := models[index]
+		downloaded, err := current.DownloadedOnDevice(false)
+		if err != nil {
+			return err
+		}
+		missingTokenizers := current.GetTokenizersNotDownloadedOnDevice()
+		if downloaded && len(missingTokenizers) == 0 {
+			continue
+		}
+
+		// Imported models can't be downloaded : they must be imported again
+		if current.Source == model.LOCAL && current.URL == "" {
+			result.AddWarnings([]string{fmt.Sprintf("Model '%s' was imported from a local directory and must be imported again", current.Name)})
+			continue
+		}
+
+		download := plan.Download{
+			Model:      current.Name,
+			Revision:   current.Revision,
+			URL:        current.URL,
+			Tokenizers: missingTokenizers.GetNames(),
+		}
+		if !downloaded && current.URL == "" {
+			ctx, stop := app.InterruptContext()
+			download.EstimatedSize, err = current.EstimateDownloadSize(ctx, accessToken)
+			stop()
+			if err != nil {
+				result.AddWarnings([]string{fmt.Sprintf("The download size of '%s' couldn't be estimated : %s", current.Name, err)})
+			}
+		}
+		result.Downloads = append(result.Downloads, download)
+		result.Config = append(result.Config, plan.ConfigEntry{Model: current.Name, Action: plan.ConfigUpdate})
+	}
+	return nil
+}
+
+// planModelsDownloadedButNotConfigured adds the downloaded models and tokenizers that aren't configured to the plan
+// They are configured or removed depending on the user's choice, the configured models being returned as they would be.
+// The class of the models isn't resolved by the downloader : the default class of their module is used instead.
+func (tc TidyController) planModelsDownloadedButNotConfigured(configModels model.Models, yes bool, accessToken string, result *plan.Plan) (plannedModels model.Models) {
+	plannedModels = append(plannedModels, configModels...)
+	indexes := make(map[string]int)
+	for index, current := range plannedModels {
+		indexes[current.Name] = index
+	}
+
+	ctx, stop := app.InterruptContext()
+	downloadedModels := model.BuildModelsFromDevice(ctx, accessToken)
+	stop()
+	for _, current := range downloadedModels {
+
+		// Model not configured
+		index, configured := indexes[current.Name]
+		if !configured {
+			configure := yes || app.UI().AskForUsersConfirmation(fmt.Sprintf("Model '%s' wasn't found in your "+
+				"configuration file. Confirm to configure, otherwise it will be removed.", current.Name))
+			if configure {
+				if current.Module != "" && current.Class == "" {
+					current.Class = current.GetModuleAutoPipelineClassName()
+				}
+				result.Config = append(result.Config, plan.ConfigEntry{Model: current.Name, Action: plan.ConfigAdd,
+					Tokenizers: current.Tokenizers.GetNames()})
+				plannedModels = append(plannedModels, current)
+			} else {
+				result.Deletions = append(result.Deletions, plan.Deletion{Model: current.Name,
+					Path: fileutil.PathJoin(app.DownloadDirectoryPath, current.Name)})
+			}
+			continue
+		}
+
+		// Only transformers have tokenizers
+		if current.Module != huggingface.TRANSFORMERS {
+			continue
+		}
+		mapConfigModelTokenizers := plannedModels[index].Tokenizers.Map()
+		var modelTokenizersToConfigure model.Tokenizers
+		for _, tokenizer := range current.Tokenizers {
+			if _, configured = mapConfigModelTokenizers[tokenizer.Class]; configured {
+				continue
+			}
+			configure := yes || app.UI().AskForUsersConfirmation(fmt.Sprintf("Tokenizer '%s' for model '%s' wasn't found in your "+
+				"configuration file. Confirm to configure, otherwise it will be removed.", tokenizer.Class, current.Name))
+			if configure {
+				modelTokenizersToConfigure = append(modelTokenizersToConfigure, tokenizer)
+			} else {
+				result.Deletions = append(result.Deletions, plan.Deletion{Model: current.Name, Tokenizer: tokenizer.Class,
+					Path: fileutil.PathJoin(app.DownloadDirectoryPath, tokenizer.Path)})
+			}
+		}
+		if len(modelTokenizersToConfigure) > 0 {
+			result.Config = append(result.Config, plan.ConfigEntry{Model: current.Name, Action: plan.ConfigUpdate,
+				Tokenizers: modelTokenizersToConfigure.GetNames()})
+			plannedModels[index].Tokenizers = append(plannedModels[index].Tokenizers, modelTokenizersToConfigure...)
+		}
+	}
+	return plannedModels
+}
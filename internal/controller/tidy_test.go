@@ -1,11 +1,15 @@
 package controller
 
 import (
+	"errors"
 	"fmt"
 	"github.com/easy-model-fusion/emf-cli/internal/app"
 	"github.com/easy-model-fusion/emf-cli/internal/config"
 	"github.com/easy-model-fusion/emf-cli/internal/downloader/model"
+	"github.com/easy-model-fusion/emf-cli/internal/lockfile"
 	"github.com/easy-model-fusion/emf-cli/internal/model"
+	"github.com/easy-model-fusion/emf-cli/internal/plan"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/fileutil"
 	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
 	"github.com/easy-model-fusion/emf-cli/test"
 	"github.com/easy-model-fusion/emf-cli/test/dmock"
@@ -76,6 +80,41 @@ func TestTidyModelsConfiguredButNotDownloaded_Success(t *testing.T) {
 	test.AssertEqual(t, len(warnings), 0)
 }
 
+// Tests tidyModelsConfiguredButNotDownloaded with concurrent downloads
+func TestTidyModelsConfiguredButNotDownloaded_Jobs(t *testing.T) {
+	// Init
+	var existingModels model.Models
+	for _, name := range []string{"model1", "model2", "model3", "model4"} {
+		existingModels = append(existingModels, model.Model{
+			Name:         name,
+			Module:       huggingface.DIFFUSERS,
+			Class:        "test",
+			IsDownloaded: true,
+		})
+	}
+
+	// Create full test suite with a configuration file
+	ts := test.TestSuite{}
+	_ = ts.CreateFullTestSuite(t)
+	defer ts.CleanTestSuite(t)
+	err := config.GetViperConfig(".")
+	test.AssertEqual(t, err, nil, "No error expected on loading configuration file")
+
+	// Create Downloader mock
+	downloader := dmock.MockDownloader{DownloaderError: errors.New("download failed")}
+	app.SetDownloader(&downloader)
+
+	// Execute
+	tidyController := TidyController{Jobs: 3}
+	warnings, err := tidyController.tidyModelsConfiguredButNotDownloaded(existingModels, "")
+
+	// Assert : the results are collected in the models order
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, len(warnings), 1)
+	test.AssertEqual(t, warnings[0], "The following models(s) couldn't be downloaded : [model1 model2 model3 model4]")
+	test.AssertEqual(t, len(downloader.Calls), 4)
+}
+
 // Tests tidyModelsConfiguredButNotDownloaded with no configuration file loaded
 func TestTidyModelsConfiguredButNotDownloaded_SuccessWithNoConfFile(t *testing.T) {
 	// Init
@@ -387,3 +426,135 @@ func TestRunTidy_WithNoConfigurationFile(t *testing.T) {
 	err := tidyController.RunTidy(true, "")
 	test.AssertNotEqual(t, err, nil, "An error expected on synchronizing models")
 }
+
+// Tests applyLock when the lockfile is missing
+func TestApplyLock_Missing(t *testing.T) {
+	// Init
+	ts := test.TestSuite{}
+	_ = ts.CreateFullTestSuite(t)
+	defer ts.CleanTestSuite(t)
+	config.FilePath = "."
+	models := model.Models{{Name: "model1/name", Revision: "sha1"}}
+
+	// Execute
+	_, _, errFrozen := TidyController{Frozen: true}.applyLock(models)
+	_, _, err := TidyController{}.applyLock(models)
+
+	// Assertions
+	test.AssertNotEqual(t, errFrozen, nil)
+	test.AssertEqual(t, err, nil)
+}
+
+// Tests applyLock when the configuration file and the lockfile disagree
+func TestApplyLock_Disagree(t *testing.T) {
+	// Init
+	ts := test.TestSuite{}
+	_ = ts.CreateFullTestSuite(t)
+	defer ts.CleanTestSuite(t)
+	config.FilePath = "."
+	err := lockfile.Write(lockfile.Lock{Models: []lockfile.Model{{Name: "model1/name", Revision: "sha0"}}})
+	test.AssertEqual(t, err, nil)
+	models := model.Models{{Name: "model1/name", Revision: "sha1"}}
+
+	// Execute
+	_, _, errFrozen := TidyController{Frozen: true}.applyLock(models)
+	_, warnings, err := TidyController{}.applyLock(models)
+
+	// Assertions
+	test.AssertNotEqual(t, errFrozen, nil)
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, len(warnings), 1)
+	test.AssertEqual(t, models[0].Revision, "sha1")
+}
+
+// Tests that applyLock pins the models configured without any revision
+func TestApplyLock_PinRevision(t *testing.T) {
+	// Init
+	ts := test.TestSuite{}
+	_ = ts.CreateFullTestSuite(t)
+	defer ts.CleanTestSuite(t)
+	config.FilePath = "."
+	err := lockfile.Write(lockfile.Lock{Models: []lockfile.Model{{Name: "model1/name", Revision: "sha1"}}})
+	test.AssertEqual(t, err, nil)
+	models := model.Models{{Name: "model1/name"}}
+
+	// Execute
+	_, _, err = TidyController{Frozen: true}.applyLock(models)
+
+	// Assertions
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, models[0].Revision, "sha1")
+}
+
+// tidyPlanModels returns the configured models used by the plan tests
+func tidyPlanModels() model.Models {
+	return model.Models{
+		{Name: "model1/name", Module: huggingface.DIFFUSERS, Class: "test", PipelineTag: huggingface.TextToImage, IsDownloaded: true},
+		{Name: "model2/name", Module: huggingface.DIFFUSERS, Class: "test", PipelineTag: huggingface.TextToImage, IsDownloaded: true},
+		{Name: "model5/name", Module: huggingface.DIFFUSERS, Class: "test", PipelineTag: huggingface.TextToImage, AddToBinaryFile: true},
+		{Name: "model4/name", Module: huggingface.TRANSFORMERS, Class: "test", PipelineTag: huggingface.TextToImage, IsDownloaded: true,
+			Tokenizers: model.Tokenizers{{Class: "tokenizer", Path: "models/model4/name/tokenizer"}, {Class: "tokenizer2", Path: "invalid/Path"}}},
+	}
+}
+
+// Tests buildPlan lists the changes without applying them
+func TestBuildPlan(t *testing.T) {
+	// Init
+	ts := test.TestSuite{}
+	_ = ts.CreateModelsFolderFullTestSuite(t)
+	defer ts.CleanTestSuite(t)
+	err := setupConfigFile(tidyPlanModels())
+	test.AssertEqual(t, err, nil, "No error expected on setting configuration file")
+	app.SetHuggingFace(&huggingface.MockHuggingFace{GetModelResult: huggingface.Model{LibraryName: huggingface.DIFFUSERS,
+		Siblings: []huggingface.Sibling{{RFileName: "model_index.json", Size: 1}, {RFileName: "unet/model.safetensors", Size: 10}}}})
+	downloader := dmock.MockDownloader{DownloaderError: fmt.Errorf("")}
+	app.SetDownloader(&downloader)
+
+	// Execute
+	result, err := TidyController{DryRun: true}.buildPlan(true, "")
+
+	// Assertions
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, len(result.Downloads), 2)
+	test.AssertEqual(t, result.Downloads[0].Model, "model5/name")
+	test.AssertEqual(t, result.Downloads[0].EstimatedSize, int64(11))
+	test.AssertEqual(t, result.Downloads[1].Model, "model4/name")
+	test.AssertEqual(t, result.Downloads[1].EstimatedSize, int64(0))
+	test.AssertEqual(t, result.Downloads[1].Tokenizers[0], "tokenizer2")
+	test.AssertEqual(t, len(result.Deletions), 0)
+	test.AssertEqual(t, result.Config[len(result.Config)-1].Model, "model3/name")
+	test.AssertEqual(t, result.Config[len(result.Config)-1].Action, plan.ConfigAdd)
+	test.AssertEqual(t, result.CodeChanged, true)
+	test.AssertEqual(t, len(result.Warnings), 0)
+
+	// Nothing was changed
+	models, err := config.GetModels()
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, len(models), 4)
+	content, err := os.ReadFile(config.ModelsPythonCodePath())
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, len(content), 0)
+}
+
+// Tests buildPlan lists the models the user chose not to configure as deletions, without removing them
+func TestBuildPlan_WithNoConfirmation(t *testing.T) {
+	// Init
+	ts := test.TestSuite{}
+	_ = ts.CreateModelsFolderFullTestSuite(t)
+	defer ts.CleanTestSuite(t)
+	err := setupConfigFile(tidyPlanModels())
+	test.AssertEqual(t, err, nil, "No error expected on setting configuration file")
+	app.SetHuggingFace(&huggingface.MockHuggingFace{GetModelResult: huggingface.Model{LibraryName: huggingface.DIFFUSERS}})
+	app.SetUI(&mock.MockUI{UserConfirmationResult: false})
+
+	// Execute
+	result, err := TidyController{DryRun: true}.buildPlan(false, "")
+
+	// Assertions
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, len(result.Deletions), 1)
+	test.AssertEqual(t, result.Deletions[0].Model, "model3/name")
+	exists, err := fileutil.IsExistingPath(result.Deletions[0].Path)
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, exists, true)
+}
@@ -114,8 +114,11 @@ func (ic UpdateTokenizerController) processUpdateTokenizer(args []string) (warni
 	for _, tokenizer := range updateTokenizers {
 
 		downloaderArgs := downloadermodel.Args{
-			ModelName:   modelToUse.Name,
-			ModelModule: string(modelToUse.Module),
+			ModelName:    modelToUse.Name,
+			ModelModule:  string(modelToUse.Module),
+			Include:      modelToUse.Include,
+			Exclude:      modelToUse.Exclude,
+			PreferFormat: modelToUse.PreferFormat,
 		}
 		downloaderArgs.OnlyConfiguration = !modelToUse.IsDownloaded
 
@@ -2,24 +2,37 @@ package downloader
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"github.com/easy-model-fusion/emf-cli/internal/downloader/model"
 	"github.com/easy-model-fusion/emf-cli/internal/utils/python"
+	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
 )
 
 type Downloader interface {
 	Execute(downloaderArgs downloadermodel.Args, python python.Python, ctx context.Context) (downloadermodel.Model, error)
 }
 
-type scriptDownloader struct{}
+type scriptDownloader struct {
+	env []string
+}
 
 // NewScriptDownloader initialize a new script downloader
-func NewScriptDownloader() Downloader {
-	return &scriptDownloader{}
+// The environment variables (KEY=value) are added to the environment of the script.
+func NewScriptDownloader(env ...string) Downloader {
+	return &scriptDownloader{env: env}
+}
+
+// HubEnv returns the environment variables making the downloader script reach the hub through the proxy, if any
+func HubEnv(endpoint, proxyUrl string) (env []string) {
+	env = append(env, huggingface.EndpointEnv+"="+endpoint)
+	if proxyUrl != "" {
+		env = append(env, huggingface.ProxyEnv+"="+proxyUrl)
+	}
+	return env
 }
 
 // Execute runs the downloader script and handles the result
+// The script writes one event per line, the last one being the result holding the downloaded model.
 func (downloader *scriptDownloader) Execute(downloaderArgs downloadermodel.Args, python python.Python, ctx context.Context) (downloadermodel.Model, error) {
 
 	// Check arguments validity
@@ -28,27 +41,38 @@ func (downloader *scriptDownloader) Execute(downloaderArgs downloadermodel.Args,
 		return downloadermodel.Model{}, fmt.Errorf("arguments provided are invalid : %s", err)
 	}
 
-	// Building args for the python script
+	// Building args for the python script, the secrets being passed through its environment
 	args := downloaderArgs.ToPython()
+	env := append(append([]string{}, downloader.env...), downloaderArgs.ToPythonEnv()...)
 
-	// Run the script to download the model
-	scriptModel, err, _ := python.ExecuteScript(".venv", downloadermodel.ScriptPath, args, ctx)
+	// Run the script to download the model, forwarding its events as they come
+	var model downloadermodel.Model
+	var received bool
+	var parseErr error
+	err, _ = python.ExecuteScriptStream(".venv", downloadermodel.ScriptPath, args, env, ctx, func(line []byte) {
+		event, err := downloadermodel.ParseEvent(line)
+		if err != nil {
+			parseErr = err
+			return
+		}
+		if event.Type == downloadermodel.EventResult && event.Model != nil {
+			model = *event.Model
+			received = true
+		}
+		downloaderArgs.Events.Send(event)
+	})
 
 	// An error occurred while running the script
 	if err != nil {
 		return downloadermodel.Model{}, err
 	}
 
-	// No data was returned by the script
-	if scriptModel == nil {
-		return downloadermodel.Model{IsEmpty: true}, fmt.Errorf("the script didn't return any data")
+	// No model was returned by the script
+	if !received && parseErr != nil {
+		return downloadermodel.Model{}, fmt.Errorf("failed to process the script return data : %s", parseErr)
 	}
-
-	// Unmarshall JSON response
-	var model downloadermodel.Model
-	err = json.Unmarshal(scriptModel, &model)
-	if err != nil {
-		return downloadermodel.Model{}, fmt.Errorf("failed to process the script return data : %s", err)
+	if !received {
+		return downloadermodel.Model{IsEmpty: true}, fmt.Errorf("the script didn't return any data")
 	}
 
 	// Download was successful
@@ -8,6 +8,7 @@ import (
 	"github.com/easy-model-fusion/emf-cli/test"
 	"github.com/easy-model-fusion/emf-cli/test/mock"
 	"os"
+	"reflect"
 	"testing"
 )
 
@@ -101,7 +102,41 @@ func TestExecute_Success(t *testing.T) {
 	test.AssertEqual(t, result.IsEmpty, false)
 }
 
+// TestExecute_Events tests that the events of the script are forwarded until the result.
+func TestExecute_Events(t *testing.T) {
+	// Mock python script to send events
+	pythonInterface.(*mock.MockPython).ScriptResult = []byte(`{"event": "file-started", "file": "config.json", "total": 2}
+{"event": "progress", "file": "config.json", "downloaded": 2}
+{"event": "file-done", "file": "config.json"}
+{"event": "warning", "message": "warning"}
+{"event": "result", "model": {"class": "Class"}}`)
+	pythonInterface.(*mock.MockPython).ExecuteScriptError = nil
+
+	// Init
+	var events []downloadermodel.EventType
+	args := downloadermodel.Args{ModelName: "ModelName", ModelModule: "ModelModule", Events: func(event downloadermodel.Event) {
+		events = append(events, event.Type)
+	}}
+
+	// Execute
+	result, err := downloaderInterface.Execute(args, pythonInterface, context.Background())
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, result.Class, "Class")
+	test.AssertEqual(t, len(events), 5)
+	test.AssertEqual(t, events[0], downloadermodel.EventFileStarted)
+	test.AssertEqual(t, events[4], downloadermodel.EventResult)
+}
+
 // TestNewScriptDownloader tests NewScriptDownloader
 func TestNewScriptDownloader(t *testing.T) {
-	test.AssertEqual(t, &scriptDownloader{}, NewScriptDownloader())
+	test.AssertEqual(t, reflect.DeepEqual(&scriptDownloader{}, NewScriptDownloader()), true)
+}
+
+// TestHubEnv tests that the proxy is only passed to the script when set
+func TestHubEnv(t *testing.T) {
+	test.AssertEqual(t, reflect.DeepEqual(HubEnv("https://mirror", ""), []string{"HF_ENDPOINT=https://mirror"}), true)
+	test.AssertEqual(t, reflect.DeepEqual(HubEnv("https://mirror", "http://proxy:3128"),
+		[]string{"HF_ENDPOINT=https://mirror", "HTTPS_PROXY=http://proxy:3128"}), true)
 }
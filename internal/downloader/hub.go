@@ -0,0 +1,479 @@
+package downloader
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/easy-model-fusion/emf-cli/internal/downloader/model"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/fileutil"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/httputil"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/python"
+	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// Files describing the repositories on the hub
+const (
+	hubDiffusersConfigFileName    = "model_index.json"
+	hubTransformersConfigFileName = "config.json"
+	hubTokenizerConfigFileName    = "tokenizer_config.json"
+	hubDefaultRevision            = "main"
+	hubDefaultTokenizerClass      = "AutoTokenizer"
+	hubTransformersModelDirectory = "model"
+)
+
+// hubConfigExtensions are the extensions of the non weights files needed to load a model
+var hubConfigExtensions = []string{".json", ".txt", ".model", ".tiktoken"}
+
+// hubWeightsExtensions are the extensions of the weights files loadable by the python libraries, by order of preference
+var hubWeightsExtensions = []string{".safetensors", ".bin"}
+
+// hubIgnoredWeightsExtensions are the extensions of the weights files never loaded by the python libraries
+var hubIgnoredWeightsExtensions = []string{".pt", ".pth", ".ckpt", ".gguf", ".h5", ".msgpack", ".onnx", ".ot", ".tflite"}
+
+// hubTokenizerFileNames are the files saved by save_pretrained for a transformers tokenizer
+var hubTokenizerFileNames = []string{hubTokenizerConfigFileName, "tokenizer.json", "special_tokens_map.json",
+	"added_tokens.json", "vocab.json", "vocab.txt", "merges.txt", "chat_template.jinja"}
+
+// hubConfig represents the fields of the transformers configuration files used to resolve the classes
+type hubConfig struct {
+	Architectures  []string `json:"architectures"`
+	TokenizerClass string   `json:"tokenizer_class"`
+}
+
+type hubDownloader struct {
+	huggingFace huggingface.HuggingFace
+	baseUrl     string
+	client      *http.Client
+	fallback    Downloader
+}
+
+// NewHubDownloader initialize a new downloader fetching the files from the hugging face hub, through the proxy if any
+// The url is the one of the hub api (huggingface.BaseUrl), the files being served by its parent url.
+// Only the connection of the file downloads is bounded by the timeouts, since they can take a long time.
+func NewHubDownloader(apiUrl, proxyUrl string, timeouts huggingface.Timeouts) Downloader {
+	baseUrl := strings.TrimSuffix(strings.TrimSuffix(apiUrl, "/"), "/api")
+	return &hubDownloader{
+		huggingFace: huggingface.NewHuggingFace(apiUrl, proxyUrl, timeouts),
+		baseUrl:     baseUrl,
+		client:      huggingface.NewClient(proxyUrl, huggingface.Timeouts{Connect: timeouts.Connect}),
+		fallback:    NewScriptDownloader(HubEnv(baseUrl, proxyUrl)...),
+	}
+}
+
+// Execute downloads the model from the hub, falling back to the downloader script when python is needed
+func (downloader *hubDownloader) Execute(downloaderArgs downloadermodel.Args, python python.Python, ctx context.Context) (downloadermodel.Model, error) {
+
+	// Check arguments validity
+	err := downloaderArgs.Validate()
+	if err != nil {
+		return downloadermodel.Model{}, fmt.Errorf("arguments provided are invalid : %s", err)
+	}
+
+	// Options are interpreted by the python libraries
+	if requiresScript(downloaderArgs) {
+		return downloader.fallback.Execute(downloaderArgs, python, ctx)
+	}
+
+	// List the files of the repository at the requested revision
+	revision := downloaderArgs.Revision
+	if revision == "" {
+		revision = hubDefaultRevision
+	}
+	hubModel, err := downloader.huggingFace.GetModelByRevision(ctx, downloaderArgs.ModelName, revision, downloaderArgs.AccessToken)
+	if err != nil {
+		return downloadermodel.Model{}, err
+	}
+	if hubModel.Sha != "" {
+		revision = hubModel.Sha
+	}
+	var files []string
+	for _, sibling := range hubModel.Siblings {
+		files = append(files, sibling.RFileName)
+	}
+
+	// Tokenizers only exist for transformers
+	var model downloadermodel.Model
+	var resolved bool
+	if downloaderArgs.SkipModel || huggingface.Module(downloaderArgs.ModelModule) == huggingface.TRANSFORMERS {
+		model, resolved, err = downloader.executeTransformers(ctx, downloaderArgs, revision, files)
+	} else {
+		model, resolved, err = downloader.executeDiffusers(ctx, downloaderArgs, revision, files)
+	}
+	if err != nil {
+		return downloadermodel.Model{}, err
+	}
+
+	// The classes couldn't be resolved from the configuration files
+	if !resolved {
+		return downloader.fallback.Execute(downloaderArgs, python, ctx)
+	}
+
+	// Download was successful
+	model.Revision = revision
+	downloaderArgs.Events.Send(downloadermodel.Event{Type: downloadermodel.EventResult, Model: &model})
+	return model, nil
+}
+
+// requiresScript returns true if the download can't be achieved without the python libraries
+func requiresScript(downloaderArgs downloadermodel.Args) bool {
+	if len(downloaderArgs.ModelOptions) > 0 || len(downloaderArgs.TokenizerOptions) > 0 {
+		return true
+	}
+	if downloaderArgs.SkipModel {
+		return false
+	}
+	module := huggingface.Module(downloaderArgs.ModelModule)
+	return module != huggingface.DIFFUSERS && module != huggingface.TRANSFORMERS
+}
+
+// executeDiffusers downloads the pipeline components listed by the repository model index
+func (downloader *hubDownloader) executeDiffusers(ctx context.Context, downloaderArgs downloadermodel.Args, revision string, files []string) (model downloadermodel.Model, resolved bool, err error) {
+	if !containsFile(files, hubDiffusersConfigFileName) {
+		return model, false, nil
+	}
+
+	// The model index maps every component to its subdirectory
+	var index map[string]json.RawMessage
+	if err = downloader.getJson(ctx, downloaderArgs, revision, hubDiffusersConfigFileName, &index); err != nil {
+		return model, false, err
+	}
+
+	model.Module = string(huggingface.DIFFUSERS)
+	model.Class = downloaderArgs.ModelClass
+	if model.Class == "" {
+		_ = json.Unmarshal(index["_class_name"], &model.Class)
+	}
+	if model.Class == "" {
+		return model, false, nil
+	}
+	if downloaderArgs.OnlyConfiguration {
+		return model, true, nil
+	}
+
+	var components []string
+	for key, value := range index {
+		var component []*string
+		if strings.HasPrefix(key, "_") || json.Unmarshal(value, &component) != nil || len(component) != 2 || component[0] == nil {
+			continue
+		}
+		components = append(components, key)
+	}
+
+	// Only the model index and the components are needed by the pipeline
+	selected := []string{hubDiffusersConfigFileName}
+	for _, component := range components {
+		var componentFiles []string
+		for _, file := range files {
+			if path.Dir(file) == component {
+				componentFiles = append(componentFiles, file)
+			}
+		}
+		selected = append(selected, selectFiles(componentFiles, downloaderArgs.PreferFormat)...)
+	}
+
+	model.Path = fileutil.PathJoin(downloaderArgs.DirectoryPath, downloaderArgs.ModelName)
+	return model, true, downloader.downloadFiles(ctx, downloaderArgs, revision, selected, model.Path)
+}
+
+// executeTransformers downloads the model and its tokenizer into separate directories
+func (downloader *hubDownloader) executeTransformers(ctx context.Context, downloaderArgs downloadermodel.Args, revision string, files []string) (model downloadermodel.Model, resolved bool, err error) {
+	basePath := fileutil.PathJoin(downloaderArgs.DirectoryPath, downloaderArgs.ModelName)
+
+	// Only the files at the root of the repository are loaded by from_pretrained
+	var modelFiles, tokenizerFiles []string
+	for _, file := range selectFiles(files, downloaderArgs.PreferFormat) {
+		if strings.Contains(file, "/") {
+			continue
+		}
+		if isTokenizerFile(file) {
+			tokenizerFiles = append(tokenizerFiles, file)
+		} else {
+			modelFiles = append(modelFiles, file)
+		}
+	}
+
+	if !downloaderArgs.SkipModel {
+		model.Module = string(huggingface.TRANSFORMERS)
+		model.Class = downloaderArgs.ModelClass
+		if model.Class == "" {
+			if !containsFile(files, hubTransformersConfigFileName) {
+				return model, false, nil
+			}
+			var config hubConfig
+			if err = downloader.getJson(ctx, downloaderArgs, revision, hubTransformersConfigFileName, &config); err != nil {
+				return model, false, err
+			}
+			if len(config.Architectures) == 0 {
+				return model, false, nil
+			}
+			model.Class = config.Architectures[0]
+		}
+	}
+
+	if !downloaderArgs.SkipTokenizer && len(tokenizerFiles) > 0 {
+		model.Tokenizer.Class = downloaderArgs.TokenizerClass
+		if model.Tokenizer.Class == "" && containsFile(files, hubTokenizerConfigFileName) {
+			var config hubConfig
+			if err = downloader.getJson(ctx, downloaderArgs, revision, hubTokenizerConfigFileName, &config); err != nil {
+				return model, false, err
+			}
+			model.Tokenizer.Class = config.TokenizerClass
+		}
+		if model.Tokenizer.Class == "" {
+			model.Tokenizer.Class = hubDefaultTokenizerClass
+		}
+	} else if !downloaderArgs.SkipTokenizer && downloaderArgs.TokenizerClass != "" {
+		return model, false, fmt.Errorf("no tokenizer found in %s", downloaderArgs.ModelName)
+	}
+
+	if downloaderArgs.OnlyConfiguration {
+		return model, true, nil
+	}
+
+	if !downloaderArgs.SkipModel {
+		model.Path = fileutil.PathJoin(basePath, hubTransformersModelDirectory)
+		if err = downloader.downloadFiles(ctx, downloaderArgs, revision, modelFiles, model.Path); err != nil {
+			return model, true, err
+		}
+	}
+	if model.Tokenizer.Class != "" {
+		model.Tokenizer.Path = fileutil.PathJoin(basePath, model.Tokenizer.Class)
+		if err = downloader.downloadFiles(ctx, downloaderArgs, revision, tokenizerFiles, model.Tokenizer.Path); err != nil {
+			return model, true, err
+		}
+	}
+
+	return model, true, nil
+}
+
+// EstimateSize returns the size of the files of the repository that would be downloaded for the arguments
+// Every component of a diffusers repository is counted, so the size can only be an estimate.
+func EstimateSize(siblings []huggingface.Sibling, downloaderArgs downloadermodel.Args) (size int64) {
+	sizes := make(map[string]int64)
+	var files []string
+	for _, sibling := range siblings {
+		// Only the files at the root of the repository are loaded by transformers
+		if huggingface.Module(downloaderArgs.ModelModule) == huggingface.TRANSFORMERS && strings.Contains(sibling.RFileName, "/") {
+			continue
+		}
+		sizes[sibling.RFileName] = sibling.Size
+		files = append(files, sibling.RFileName)
+	}
+
+	files = filterFiles(selectFiles(files, downloaderArgs.PreferFormat), downloaderArgs.Include, downloaderArgs.Exclude)
+	for _, file := range files {
+		size += sizes[file]
+	}
+	return size
+}
+
+// selectFiles keeps the configuration files and the weights in the preferred format, without variants
+// The requested format is preferred over the default order, other formats being used when it isn't available.
+func selectFiles(files []string, preferFormat string) (selected []string) {
+	extensions := hubWeightsExtensions
+	if preferFormat != "" {
+		extensions = append([]string{"." + preferFormat}, hubWeightsExtensions...)
+	}
+
+	// Weights are stored in a single format per directory
+	formats := make(map[string]string)
+	for _, extension := range extensions {
+		for _, file := range files {
+			directory := path.Dir(file)
+			if _, found := formats[directory]; !found && weightsExtension(file) == extension && !isVariant(file) {
+				formats[directory] = extension
+			}
+		}
+	}
+
+	for _, file := range files {
+		extension := weightsExtension(file)
+		switch {
+		case extension != "":
+			if extension == formats[path.Dir(file)] && !isVariant(file) {
+				selected = append(selected, file)
+			}
+		case containsExtension(hubIgnoredWeightsExtensions, file):
+			continue
+		case containsExtension(hubConfigExtensions, file):
+			selected = append(selected, file)
+		}
+	}
+	return selected
+}
+
+// filterFiles keeps the files matching at least one of the included patterns, if any, and none of the excluded ones
+func filterFiles(files []string, include []string, exclude []string) (filtered []string) {
+	for _, file := range files {
+		if (len(include) == 0 || matchesAny(include, file)) && !matchesAny(exclude, file) {
+			filtered = append(filtered, file)
+		}
+	}
+	return filtered
+}
+
+// matchesAny returns true if the file path, or its name for patterns without directories, matches one of the patterns
+func matchesAny(patterns []string, file string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, file); matched {
+			return true
+		}
+		if !strings.Contains(pattern, "/") {
+			if matched, _ := path.Match(pattern, path.Base(file)); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// weightsExtension returns the extension of the loadable weights file or of its index, empty for any other file
+func weightsExtension(file string) string {
+	file = strings.TrimSuffix(file, ".index.json")
+	for _, extension := range hubWeightsExtensions {
+		if strings.HasSuffix(file, extension) {
+			return extension
+		}
+	}
+	return ""
+}
+
+// isVariant returns true if the weights file is a variant (e.g. diffusion_pytorch_model.fp16.safetensors)
+func isVariant(file string) bool {
+	extension := weightsExtension(file)
+	stem := strings.TrimSuffix(strings.TrimSuffix(path.Base(file), ".index.json"), extension)
+	return extension != "" && strings.Contains(stem, ".")
+}
+
+// isTokenizerFile returns true if the file belongs to a transformers tokenizer
+func isTokenizerFile(file string) bool {
+	for _, name := range hubTokenizerFileNames {
+		if file == name {
+			return true
+		}
+	}
+	return path.Ext(file) == ".model" || path.Ext(file) == ".tiktoken"
+}
+
+// containsExtension returns true if the file has one of the extensions
+func containsExtension(extensions []string, file string) bool {
+	for _, extension := range extensions {
+		if strings.EqualFold(path.Ext(file), extension) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsFile returns true if the repository contains the file
+func containsFile(files []string, file string) bool {
+	for _, current := range files {
+		if current == file {
+			return true
+		}
+	}
+	return false
+}
+
+// getJson fetches a JSON file of the repository
+func (downloader *hubDownloader) getJson(ctx context.Context, downloaderArgs downloadermodel.Args, revision, file string, target interface{}) error {
+	response, err := downloader.get(ctx, downloaderArgs, revision, file)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = response.Body.Close() }()
+	content, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(content, target); err != nil {
+		return fmt.Errorf("error reading %s : %s", file, err)
+	}
+	return nil
+}
+
+// downloadFiles downloads the files of the repository into the directory, honouring the requested patterns
+// When resuming an interrupted download of the same revision, the files already downloaded are kept.
+func (downloader *hubDownloader) downloadFiles(ctx context.Context, downloaderArgs downloadermodel.Args, revision string, files []string, directory string) error {
+	files = filterFiles(files, downloaderArgs.Include, downloaderArgs.Exclude)
+	resuming, err := httputil.HasPartialDownloads(directory, downloader.fileUrl(downloaderArgs, revision, ""))
+	if err != nil {
+		return err
+	}
+
+	header := http.Header{}
+	if downloaderArgs.AccessToken != "" {
+		header.Set("Authorization", "Bearer "+downloaderArgs.AccessToken)
+	}
+	for _, file := range files {
+		destination := fileutil.PathJoin(directory, file)
+		if resuming {
+			if exists, err := fileutil.IsExistingPath(destination); err != nil {
+				return err
+			} else if exists {
+				continue
+			}
+		}
+
+		download := httputil.Download{
+			URL:    downloader.fileUrl(downloaderArgs, revision, file),
+			Path:   destination,
+			Header: header,
+			Client: downloader.client,
+			Progress: func(total, downloaded int64) io.Writer {
+				downloaderArgs.Events.Send(downloadermodel.Event{Type: downloadermodel.EventFileStarted, File: file, Total: total, Downloaded: downloaded})
+				return &eventWriter{events: downloaderArgs.Events, file: file, downloaded: downloaded}
+			},
+		}
+		if _, err = download.Run(ctx); err != nil {
+			return err
+		}
+		downloaderArgs.Events.Send(downloadermodel.Event{Type: downloadermodel.EventFileDone, File: file})
+	}
+	return nil
+}
+
+// eventWriter sends a progress event for every chunk of a file written
+type eventWriter struct {
+	events     downloadermodel.EventHandler
+	file       string
+	downloaded int64
+}
+
+// Write sends the size downloaded so far
+func (w *eventWriter) Write(data []byte) (int, error) {
+	w.downloaded += int64(len(data))
+	w.events.Send(downloadermodel.Event{Type: downloadermodel.EventProgress, File: w.file, Downloaded: w.downloaded})
+	return len(data), nil
+}
+
+// fileUrl returns the url of a file of the repository at the given revision
+func (downloader *hubDownloader) fileUrl(downloaderArgs downloadermodel.Args, revision, file string) string {
+	return downloader.baseUrl + "/" + downloaderArgs.ModelName + "/resolve/" + url.PathEscape(revision) + "/" + file
+}
+
+// get requests a file of the repository at the given revision
+func (downloader *hubDownloader) get(ctx context.Context, downloaderArgs downloadermodel.Args, revision, file string) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, downloader.fileUrl(downloaderArgs, revision, file), nil)
+	if err != nil {
+		return nil, err
+	}
+	if downloaderArgs.AccessToken != "" {
+		request.Header.Set("Authorization", "Bearer "+downloaderArgs.AccessToken)
+	}
+
+	response, err := downloader.client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("error downloading %s : %s", file, err)
+	}
+	if response.StatusCode != http.StatusOK {
+		_ = response.Body.Close()
+		return nil, fmt.Errorf("error downloading %s : %s", file, response.Status)
+	}
+	return response, nil
+}
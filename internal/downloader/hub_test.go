@@ -0,0 +1,310 @@
+package downloader
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/easy-model-fusion/emf-cli/internal/downloader/model"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/fileutil"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/httputil"
+	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
+	"github.com/easy-model-fusion/emf-cli/test"
+	"github.com/easy-model-fusion/emf-cli/test/mock"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+const hubTestModelName = "org/name"
+const hubTestSha = "0123456789abcdef"
+
+// newHubStandIn serves the files of a single repository through the hub api and resolve endpoints
+func newHubStandIn(t *testing.T, files map[string]string) *httptest.Server {
+	revisionPrefix := "/api/models/" + hubTestModelName + "/revision/"
+	resolvePrefix := "/" + hubTestModelName + "/resolve/" + hubTestSha + "/"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasPrefix(r.URL.Path, revisionPrefix):
+			var siblings []map[string]string
+			for file := range files {
+				siblings = append(siblings, map[string]string{"rfilename": file})
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"modelId": hubTestModelName, "sha": hubTestSha, "siblings": siblings})
+		case strings.HasPrefix(r.URL.Path, resolvePrefix):
+			content, found := files[strings.TrimPrefix(r.URL.Path, resolvePrefix)]
+			if !found {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write([]byte(content))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// assertFiles asserts which files were written to the directory
+func assertFiles(t *testing.T, directory string, present []string, absent []string) {
+	t.Helper()
+	for _, file := range present {
+		exists, err := fileutil.IsExistingPath(fileutil.PathJoin(directory, file))
+		test.AssertEqual(t, err, nil)
+		test.AssertEqual(t, exists, true, file+" should have been downloaded")
+	}
+	for _, file := range absent {
+		exists, err := fileutil.IsExistingPath(fileutil.PathJoin(directory, file))
+		test.AssertEqual(t, err, nil)
+		test.AssertEqual(t, exists, false, file+" shouldn't have been downloaded")
+	}
+}
+
+// TestHubExecute_Diffusers tests that a diffusers pipeline is downloaded with its components only.
+func TestHubExecute_Diffusers(t *testing.T) {
+	// Init
+	server := newHubStandIn(t, map[string]string{
+		"model_index.json": `{"_class_name": "StableDiffusionPipeline", "_diffusers_version": "0.27.0",
+			"unet": ["diffusers", "UNet2DConditionModel"], "safety_checker": [null, null]}`,
+		"unet/config.json":                              "{}",
+		"unet/diffusion_pytorch_model.safetensors":      "weights",
+		"unet/diffusion_pytorch_model.fp16.safetensors": "weights",
+		"unet/diffusion_pytorch_model.bin":              "weights",
+		"safety_checker/config.json":                    "{}",
+		"v1-5-pruned.ckpt":                              "weights",
+		"README.md":                                     "readme",
+	})
+	directory := t.TempDir()
+	var events []downloadermodel.Event
+	args := downloadermodel.Args{ModelName: hubTestModelName, ModelModule: "diffusers", DirectoryPath: directory,
+		Events: func(event downloadermodel.Event) { events = append(events, event) }}
+
+	// Execute
+	result, err := NewHubDownloader(server.URL+"/api", "", huggingface.DefaultTimeouts).Execute(args, &mock.MockPython{}, context.Background())
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, result.Path, fileutil.PathJoin(directory, hubTestModelName))
+	test.AssertEqual(t, result.Module, "diffusers")
+	test.AssertEqual(t, result.Class, "StableDiffusionPipeline")
+	test.AssertEqual(t, result.Revision, hubTestSha)
+	test.AssertEqual(t, result.Tokenizer.Empty(), true)
+	assertFiles(t, result.Path,
+		[]string{"model_index.json", "unet/config.json", "unet/diffusion_pytorch_model.safetensors"},
+		[]string{"unet/diffusion_pytorch_model.fp16.safetensors", "unet/diffusion_pytorch_model.bin",
+			"safety_checker/config.json", "v1-5-pruned.ckpt", "README.md"})
+	test.AssertEqual(t, events[0].Type, downloadermodel.EventFileStarted)
+	test.AssertEqual(t, events[0].File, "model_index.json")
+	test.AssertEqual(t, events[len(events)-1].Type, downloadermodel.EventResult)
+	test.AssertEqual(t, events[len(events)-1].Model.Class, "StableDiffusionPipeline")
+}
+
+// TestHubExecute_Transformers tests that a transformers model and its tokenizer are downloaded separately.
+func TestHubExecute_Transformers(t *testing.T) {
+	// Init
+	server := newHubStandIn(t, map[string]string{
+		"config.json":            `{"architectures": ["GPT2LMHeadModel"]}`,
+		"generation_config.json": "{}",
+		"model.safetensors":      "weights",
+		"pytorch_model.bin":      "weights",
+		"tf_model.h5":            "weights",
+		"onnx/model.onnx":        "weights",
+		"tokenizer_config.json":  `{"tokenizer_class": "GPT2Tokenizer"}`,
+		"vocab.json":             "{}",
+		"merges.txt":             "merges",
+	})
+	directory := t.TempDir()
+	args := downloadermodel.Args{ModelName: hubTestModelName, ModelModule: "transformers", DirectoryPath: directory}
+
+	// Execute
+	result, err := NewHubDownloader(server.URL+"/api", "", huggingface.DefaultTimeouts).Execute(args, &mock.MockPython{}, context.Background())
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, result.Path, fileutil.PathJoin(directory, hubTestModelName, "model"))
+	test.AssertEqual(t, result.Module, "transformers")
+	test.AssertEqual(t, result.Class, "GPT2LMHeadModel")
+	test.AssertEqual(t, result.Revision, hubTestSha)
+	test.AssertEqual(t, result.Tokenizer.Class, "GPT2Tokenizer")
+	test.AssertEqual(t, result.Tokenizer.Path, fileutil.PathJoin(directory, hubTestModelName, "GPT2Tokenizer"))
+	assertFiles(t, result.Path,
+		[]string{"config.json", "generation_config.json", "model.safetensors"},
+		[]string{"pytorch_model.bin", "tf_model.h5", "onnx/model.onnx", "tokenizer_config.json", "vocab.json"})
+	assertFiles(t, result.Tokenizer.Path,
+		[]string{"tokenizer_config.json", "vocab.json", "merges.txt"},
+		[]string{"config.json", "model.safetensors"})
+}
+
+// TestHubExecute_Resume tests that the files downloaded before an interruption are kept.
+func TestHubExecute_Resume(t *testing.T) {
+	// Init
+	server := newHubStandIn(t, map[string]string{
+		"config.json":       `{"architectures": ["BertModel"]}`,
+		"model.safetensors": "weights",
+	})
+	directory := t.TempDir()
+	modelPath := fileutil.PathJoin(directory, hubTestModelName, "model")
+	_ = os.MkdirAll(modelPath, os.ModePerm)
+	_ = os.WriteFile(fileutil.PathJoin(modelPath, "model.safetensors"), []byte("kept"), os.ModePerm)
+	_ = os.WriteFile(fileutil.PathJoin(modelPath, "config.json"+httputil.PartSuffix), []byte("{"), os.ModePerm)
+	manifest := `{"url": "` + server.URL + "/" + hubTestModelName + "/resolve/" + hubTestSha + `/config.json", "size": 1}`
+	_ = os.WriteFile(fileutil.PathJoin(modelPath, "config.json"+httputil.ManifestSuffix), []byte(manifest), os.ModePerm)
+	args := downloadermodel.Args{ModelName: hubTestModelName, ModelModule: "transformers", DirectoryPath: directory, SkipTokenizer: true}
+
+	// Execute
+	_, err := NewHubDownloader(server.URL+"/api", "", huggingface.DefaultTimeouts).Execute(args, &mock.MockPython{}, context.Background())
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	content, _ := os.ReadFile(fileutil.PathJoin(modelPath, "model.safetensors"))
+	test.AssertEqual(t, string(content), "kept")
+	content, _ = os.ReadFile(fileutil.PathJoin(modelPath, "config.json"))
+	test.AssertEqual(t, string(content), `{"architectures": ["BertModel"]}`)
+	assertFiles(t, modelPath, nil, []string{"config.json" + httputil.PartSuffix, "config.json" + httputil.ManifestSuffix})
+}
+
+// TestHubExecute_OnlyConfiguration tests that the classes are resolved without downloading anything.
+func TestHubExecute_OnlyConfiguration(t *testing.T) {
+	// Init
+	server := newHubStandIn(t, map[string]string{
+		"config.json":       `{"architectures": ["BertModel"]}`,
+		"model.safetensors": "weights",
+		"vocab.txt":         "vocab",
+	})
+	directory := t.TempDir()
+	args := downloadermodel.Args{ModelName: hubTestModelName, ModelModule: "transformers", DirectoryPath: directory, OnlyConfiguration: true}
+
+	// Execute
+	result, err := NewHubDownloader(server.URL+"/api", "", huggingface.DefaultTimeouts).Execute(args, &mock.MockPython{}, context.Background())
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, result.Path, "")
+	test.AssertEqual(t, result.Class, "BertModel")
+	test.AssertEqual(t, result.Tokenizer.Class, "AutoTokenizer")
+	assertFiles(t, directory, nil, []string{hubTestModelName})
+}
+
+// TestHubExecute_FallbackOptions tests that the script is used when options must be interpreted by python.
+func TestHubExecute_FallbackOptions(t *testing.T) {
+	// Init
+	server := newHubStandIn(t, map[string]string{"config.json": `{"architectures": ["BertModel"]}`})
+	python := &mock.MockPython{ScriptResult: []byte(`{"class": "ScriptClass"}`)}
+	args := downloadermodel.Args{ModelName: hubTestModelName, ModelModule: "transformers", ModelOptions: []string{"torch_dtype=torch.float16"}}
+
+	// Execute
+	result, err := NewHubDownloader(server.URL+"/api", "", huggingface.DefaultTimeouts).Execute(args, python, context.Background())
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, result.Class, "ScriptClass")
+}
+
+// TestHubExecute_FallbackUnresolved tests that the script is used when the class can't be resolved.
+func TestHubExecute_FallbackUnresolved(t *testing.T) {
+	// Init
+	server := newHubStandIn(t, map[string]string{"config.json": "{}"})
+	python := &mock.MockPython{ScriptResult: []byte(`{"class": "ScriptClass"}`)}
+	args := downloadermodel.Args{ModelName: hubTestModelName, ModelModule: "transformers", DirectoryPath: t.TempDir()}
+
+	// Execute
+	result, err := NewHubDownloader(server.URL+"/api", "", huggingface.DefaultTimeouts).Execute(args, python, context.Background())
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, result.Class, "ScriptClass")
+}
+
+// TestHubExecute_NotFound tests that an error is returned when the repository doesn't exist.
+func TestHubExecute_NotFound(t *testing.T) {
+	// Init
+	server := newHubStandIn(t, nil)
+	args := downloadermodel.Args{ModelName: "org/unknown", ModelModule: "transformers", DirectoryPath: t.TempDir()}
+
+	// Execute
+	_, err := NewHubDownloader(server.URL+"/api", "", huggingface.DefaultTimeouts).Execute(args, &mock.MockPython{}, context.Background())
+
+	// Assert
+	test.AssertNotEqual(t, err, nil)
+}
+
+// TestSelectFiles tests that only the preferred weights format is kept, without variants.
+func TestSelectFiles(t *testing.T) {
+	// Init
+	files := []string{"config.json", "model.bin", "model.bin.index.json", "model-00001-of-00002.bin",
+		"text_encoder/model.safetensors", "text_encoder/model.bin", "text_encoder/model.fp16.safetensors", "README.md"}
+
+	// Execute
+	selected := selectFiles(files, "")
+
+	// Assert
+	test.AssertEqual(t, strings.Join(selected, ","),
+		"config.json,model.bin,model.bin.index.json,model-00001-of-00002.bin,text_encoder/model.safetensors")
+}
+
+// TestSelectFiles_PreferFormat tests that the requested weights format is preferred when available.
+func TestSelectFiles_PreferFormat(t *testing.T) {
+	// Init
+	files := []string{"config.json", "model.safetensors", "model.bin", "unet/model.safetensors"}
+
+	// Execute
+	selected := selectFiles(files, downloadermodel.FormatBin)
+
+	// Assert
+	test.AssertEqual(t, strings.Join(selected, ","), "config.json,model.bin,unet/model.safetensors")
+}
+
+// TestEstimateSize tests that only the files that would be downloaded are counted.
+func TestEstimateSize(t *testing.T) {
+	// Init
+	siblings := []huggingface.Sibling{
+		{RFileName: "config.json", Size: 1},
+		{RFileName: "model.safetensors", Size: 10},
+		{RFileName: "pytorch_model.bin", Size: 100},
+		{RFileName: "onnx/model.onnx", Size: 1000},
+	}
+	args := downloadermodel.Args{ModelName: hubTestModelName, ModelModule: "transformers", Exclude: []string{"*.json"}}
+
+	// Execute
+	size := EstimateSize(siblings, args)
+
+	// Assert
+	test.AssertEqual(t, size, int64(10))
+}
+
+// TestFilterFiles tests that the files are filtered by path or by name.
+func TestFilterFiles(t *testing.T) {
+	// Init
+	files := []string{"config.json", "model.safetensors", "unet/config.json", "unet/model.safetensors", "vae/model.safetensors"}
+
+	// Execute
+	filtered := filterFiles(files, []string{"*.json", "unet/*"}, []string{"vae/*", "unet/config.json"})
+
+	// Assert
+	test.AssertEqual(t, strings.Join(filtered, ","), "config.json,unet/model.safetensors")
+}
+
+// TestHubExecute_Filters tests that the include and exclude patterns are honoured.
+func TestHubExecute_Filters(t *testing.T) {
+	// Init
+	server := newHubStandIn(t, map[string]string{
+		"config.json":            `{"architectures": ["BertModel"]}`,
+		"generation_config.json": "{}",
+		"model.safetensors":      "weights",
+		"pytorch_model.bin":      "weights",
+	})
+	directory := t.TempDir()
+	args := downloadermodel.Args{ModelName: hubTestModelName, ModelModule: "transformers", DirectoryPath: directory,
+		SkipTokenizer: true, Exclude: []string{"generation_*"}, PreferFormat: downloadermodel.FormatBin}
+
+	// Execute
+	result, err := NewHubDownloader(server.URL+"/api", "", huggingface.DefaultTimeouts).Execute(args, &mock.MockPython{}, context.Background())
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	assertFiles(t, result.Path,
+		[]string{"config.json", "pytorch_model.bin"},
+		[]string{"generation_config.json", "model.safetensors"})
+}
@@ -7,6 +7,7 @@ type Model struct {
 	Class     string            `json:"class"`
 	Options   map[string]string `json:"options"`
 	Tokenizer Tokenizer         `json:"tokenizer"`
+	Revision  string            `json:"revision"`
 	IsEmpty   bool
 }
 
@@ -30,6 +31,12 @@ type Args struct {
 	OnlyConfiguration bool
 	DirectoryPath     string
 	AccessToken       string
+	Revision          string
+	Include           []string
+	Exclude           []string
+	PreferFormat      string
+	// Events receives the progress of the download, it isn't sent to the script
+	Events EventHandler
 }
 
 // Constants related to the downloader script python arguments.
@@ -50,4 +57,17 @@ const (
 	EmfClient          = "emf-client"
 	OnlyConfiguration  = "only-configuration"
 	AccessToken        = "access-token"
+	Revision           = "revision"
+	Include            = "include"
+	Exclude            = "exclude"
+	PreferFormat       = "prefer-format"
 )
+
+// Weights formats that can be preferred when a repository provides several of them.
+const (
+	FormatSafetensors = "safetensors"
+	FormatBin         = "bin"
+)
+
+// Formats lists the weights formats that can be preferred
+var Formats = []string{FormatSafetensors, FormatBin}
@@ -0,0 +1,61 @@
+package downloadermodel
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// EventType is the type of event sent by the downloaders
+type EventType string
+
+// Events sent by the downloaders, one JSON object per line for the downloader script
+const (
+	EventProgress    EventType = "progress"
+	EventFileStarted EventType = "file-started"
+	EventFileDone    EventType = "file-done"
+	EventWarning     EventType = "warning"
+	EventResult      EventType = "result"
+)
+
+// Event represents an event sent by a downloader
+// Total and Downloaded are sizes in bytes, Downloaded being cumulative for the file.
+type Event struct {
+	Type       EventType `json:"event"`
+	File       string    `json:"file,omitempty"`
+	Total      int64     `json:"total,omitempty"`
+	Downloaded int64     `json:"downloaded,omitempty"`
+	Message    string    `json:"message,omitempty"`
+	Model      *Model    `json:"model,omitempty"`
+}
+
+// EventHandler handles the events sent by a downloader
+type EventHandler func(event Event)
+
+// ParseEvent parses a line of the downloader script output
+// A line without event type is the model returned by scripts predating the events : it is read as a result event.
+func ParseEvent(line []byte) (event Event, err error) {
+	var fields map[string]json.RawMessage
+	if err = json.Unmarshal(line, &fields); err != nil {
+		return event, err
+	}
+	if _, found := fields["event"]; found {
+		err = json.Unmarshal(line, &event)
+		if err == nil && event.Type == "" {
+			err = errors.New("missing event type")
+		}
+		return event, err
+	}
+
+	var model Model
+	if err = json.Unmarshal(line, &model); err != nil {
+		return event, err
+	}
+	return Event{Type: EventResult, Model: &model}, nil
+}
+
+// Send sends the event to the handler, if any
+func (handler EventHandler) Send(event Event) {
+	if handler != nil {
+		handler(event)
+	}
+}
@@ -0,0 +1,52 @@
+package downloadermodel
+
+import (
+	"github.com/easy-model-fusion/emf-cli/test"
+	"testing"
+)
+
+// TestParseEvent_Progress tests that a progress event is parsed.
+func TestParseEvent_Progress(t *testing.T) {
+	// Execute
+	event, err := ParseEvent([]byte(`{"event": "progress", "file": "model.safetensors", "downloaded": 42}`))
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, event.Type, EventProgress)
+	test.AssertEqual(t, event.File, "model.safetensors")
+	test.AssertEqual(t, event.Downloaded, int64(42))
+}
+
+// TestParseEvent_Result tests that a result event holds the downloaded model.
+func TestParseEvent_Result(t *testing.T) {
+	// Execute
+	event, err := ParseEvent([]byte(`{"event": "result", "model": {"path": "models/org/name", "class": "Class"}}`))
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, event.Type, EventResult)
+	test.AssertEqual(t, event.Model.Path, "models/org/name")
+	test.AssertEqual(t, event.Model.Class, "Class")
+}
+
+// TestParseEvent_Legacy tests that the model returned by older scripts is read as a result event.
+func TestParseEvent_Legacy(t *testing.T) {
+	// Execute
+	event, err := ParseEvent([]byte(`{"path": "models/org/name", "module": "diffusers"}`))
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, event.Type, EventResult)
+	test.AssertEqual(t, event.Model.Module, "diffusers")
+}
+
+// TestParseEvent_Invalid tests that lines which aren't events are rejected.
+func TestParseEvent_Invalid(t *testing.T) {
+	// Execute
+	_, errText := ParseEvent([]byte("Downloading..."))
+	_, errType := ParseEvent([]byte(`{"event": ""}`))
+
+	// Assert
+	test.AssertNotEqual(t, errText, nil)
+	test.AssertNotEqual(t, errType, nil)
+}
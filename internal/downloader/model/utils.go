@@ -2,8 +2,12 @@ package downloadermodel
 
 import (
 	"errors"
+	"fmt"
 	"github.com/easy-model-fusion/emf-cli/internal/utils/stringutil"
+	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
 	"github.com/spf13/cobra"
+	"path"
+	"strings"
 )
 
 // Empty checks if a Model is empty.
@@ -29,6 +33,16 @@ func (a *Args) Validate() error {
 		return errors.New("missing module for the model")
 	}
 
+	// Filters validity
+	for _, pattern := range append(append([]string{}, a.Include...), a.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid file pattern %s", pattern)
+		}
+	}
+	if a.PreferFormat != "" && !stringutil.SliceContainsItem(Formats, a.PreferFormat) {
+		return fmt.Errorf("unknown weights format %s, expected one of %s", a.PreferFormat, strings.Join(Formats, ", "))
+	}
+
 	return nil
 }
 
@@ -40,6 +54,10 @@ func (a *Args) ToCobra(cmd *cobra.Command) {
 	cmd.Flags().StringSliceVarP(&a.ModelOptions, ModelOptions, "o", []string{}, "List of model options")
 	cmd.Flags().StringVarP(&a.ModelModule, ModelModule, "m", "", "Python module used for download")
 	cmd.Flags().StringVarP(&a.DirectoryPath, Path, "p", "", "Downloaded Model directory path")
+	cmd.Flags().StringVarP(&a.Revision, Revision, "r", "", "Revision of the model to download (commit sha, branch or tag)")
+	cmd.Flags().StringSliceVar(&a.Include, Include, []string{}, "Only download the files matching these glob patterns")
+	cmd.Flags().StringSliceVar(&a.Exclude, Exclude, []string{}, "Never download the files matching these glob patterns")
+	cmd.Flags().StringVar(&a.PreferFormat, PreferFormat, "", "Weights format to download when several are available (safetensors, bin)")
 
 	// Optional for the tokenizer
 	cmd.Flags().StringVarP(&a.TokenizerClass, TokenizerClass, "t", "", "Tokenizer class (only for transformers)")
@@ -61,7 +79,7 @@ func (a *Args) ToCobraTokenizer(cmd *cobra.Command) {
 	cmd.Flags().StringArrayVarP(&a.TokenizerOptions, TokenizerOptions, "o", []string{}, "List of tokenizer options (only for transformers)")
 }
 
-// ToPython builds the arguments for running the python script.
+// ToPython builds the arguments for running the python script, the access token being left to ToPythonEnv.
 // Pre-condition : certain that the user authorized the overwriting when downloading the model.
 func (a *Args) ToPython() []string {
 
@@ -75,6 +93,18 @@ func (a *Args) ToPython() []string {
 	if a.ModelModule != "" {
 		cmdArgs = append(cmdArgs, TagPrefix+ModelModule, a.ModelModule)
 	}
+	if a.Revision != "" {
+		cmdArgs = append(cmdArgs, TagPrefix+Revision, a.Revision)
+	}
+	if len(a.Include) != 0 {
+		cmdArgs = append(cmdArgs, append([]string{TagPrefix + Include}, a.Include...)...)
+	}
+	if len(a.Exclude) != 0 {
+		cmdArgs = append(cmdArgs, append([]string{TagPrefix + Exclude}, a.Exclude...)...)
+	}
+	if a.PreferFormat != "" {
+		cmdArgs = append(cmdArgs, TagPrefix+PreferFormat, a.PreferFormat)
+	}
 	if len(a.ModelOptions) != 0 {
 		var options []string
 		for _, modelOption := range a.ModelOptions {
@@ -107,10 +137,15 @@ func (a *Args) ToPython() []string {
 	if a.OnlyConfiguration {
 		cmdArgs = append(cmdArgs, TagPrefix+OnlyConfiguration)
 	}
+	return cmdArgs
+}
 
-	// Access token
-	if a.AccessToken != "" {
-		cmdArgs = append(cmdArgs, TagPrefix+AccessToken, a.AccessToken)
+// ToPythonEnv builds the environment variables (KEY=value) for running the python script.
+// The access token is handed through HF_TOKEN, read by the hub library, rather than through the arguments
+// which any user of the machine can see (i.e. ps).
+func (a *Args) ToPythonEnv() []string {
+	if a.AccessToken == "" {
+		return nil
 	}
-	return cmdArgs
+	return []string{huggingface.TokenEnv + "=" + a.AccessToken}
 }
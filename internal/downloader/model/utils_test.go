@@ -98,6 +98,30 @@ func TestValidate_MissingModuleAndSkipTrue(t *testing.T) {
 	test.AssertEqual(t, result, nil)
 }
 
+// TestValidate_InvalidPattern tests the ArgsValidate function to return an error on a malformed glob pattern.
+func TestValidate_InvalidPattern(t *testing.T) {
+	// Init
+	args := Args{ModelName: "present", ModelModule: "present", Exclude: []string{"[*.bin"}}
+
+	// Execute
+	result := args.Validate()
+
+	// Assert
+	test.AssertNotEqual(t, result, nil)
+}
+
+// TestValidate_UnknownFormat tests the ArgsValidate function to return an error on an unknown weights format.
+func TestValidate_UnknownFormat(t *testing.T) {
+	// Init
+	args := Args{ModelName: "present", ModelModule: "present", PreferFormat: "onnx"}
+
+	// Execute
+	result := args.Validate()
+
+	// Assert
+	test.AssertNotEqual(t, result, nil)
+}
+
 // TestValidate_Success tests the ArgsValidate function to succeed.
 func TestValidate_Success(t *testing.T) {
 	// Init
@@ -126,6 +150,10 @@ func TestToCobra(t *testing.T) {
 	test.AssertNotEqual(t, cmd.Flags().Lookup(ModelOptions), nil)
 	test.AssertNotEqual(t, cmd.Flags().Lookup(TokenizerClass), nil)
 	test.AssertNotEqual(t, cmd.Flags().Lookup(TokenizerOptions), nil)
+	test.AssertNotEqual(t, cmd.Flags().Lookup(Revision), nil)
+	test.AssertNotEqual(t, cmd.Flags().Lookup(Include), nil)
+	test.AssertNotEqual(t, cmd.Flags().Lookup(Exclude), nil)
+	test.AssertNotEqual(t, cmd.Flags().Lookup(PreferFormat), nil)
 	test.AssertNotEqual(t, cmd.Flags().Lookup(Overwrite), nil)
 	test.AssertNotEqual(t, cmd.Flags().Lookup(Skip), nil)
 
@@ -137,6 +165,7 @@ func TestToCobra(t *testing.T) {
 	test.AssertEqual(t, len(args.TokenizerOptions), 0)
 	test.AssertEqual(t, args.SkipModel, false)
 	test.AssertEqual(t, args.SkipTokenizer, false)
+	test.AssertEqual(t, args.Revision, "")
 }
 
 // TestToCobraTokenizer tests the ArgsGetForCobra.
@@ -157,6 +186,23 @@ func TestToCobraTokenizer(t *testing.T) {
 	test.AssertEqual(t, len(args.TokenizerOptions), 0)
 }
 
+// TestToPythonEnv tests that the access token is handed to the script through its environment.
+func TestToPythonEnv(t *testing.T) {
+	// Init
+	args := Args{ModelName: "model", AccessToken: "hf_token"}
+
+	// Execute
+	env := args.ToPythonEnv()
+
+	// Assert
+	test.AssertEqual(t, len(env), 1)
+	test.AssertEqual(t, env[0], "HF_TOKEN=hf_token")
+	for _, arg := range args.ToPython() {
+		test.AssertNotEqual(t, arg, "hf_token", "The access token shouldn't be on the command line.")
+	}
+	test.AssertEqual(t, len((&Args{}).ToPythonEnv()), 0)
+}
+
 // TestToPython tests the ArgsProcessForPython.
 func TestToPython(t *testing.T) {
 	// Init
@@ -172,19 +218,26 @@ func TestToPython(t *testing.T) {
 		SkipTokenizer:     true,
 		OnlyConfiguration: true,
 		AccessToken:       "token",
+		Revision:          "main",
+		Include:           []string{"*.json", "*.safetensors"},
+		Exclude:           []string{"*.bin"},
+		PreferFormat:      FormatSafetensors,
 	}
 	expected := []string{
 		TagPrefix + EmfClient, TagPrefix + Overwrite,
 		"/path/to/download", "model",
 		TagPrefix + ModelClass, "class",
 		TagPrefix + ModelModule, "module",
+		TagPrefix + Revision, "main",
+		TagPrefix + Include, "*.json", "*.safetensors",
+		TagPrefix + Exclude, "*.bin",
+		TagPrefix + PreferFormat, "safetensors",
 		TagPrefix + ModelOptions, "opt1=val1", "opt2=val2",
 		TagPrefix + TokenizerClass, "tokenizer",
 		TagPrefix + TokenizerOptions, "tok_opt1=val1",
 		TagPrefix + Skip, "tokenizer",
 		TagPrefix + Skip, "model",
 		TagPrefix + OnlyConfiguration,
-		TagPrefix + AccessToken, "token",
 	}
 
 	// Execute
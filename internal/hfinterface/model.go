@@ -1,43 +1,101 @@
 package hfinterface
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/easy-model-fusion/emf-cli/internal/config"
 	"github.com/easy-model-fusion/emf-cli/internal/utils/stringutil"
 	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
+	"net"
 )
 
 // GetModelsByPipelineTag from hugging face api by pipeline tag
-func GetModelsByPipelineTag(tag huggingface.PipelineTag, limit int, authorizationKey string) (huggingface.Models, error) {
+func GetModelsByPipelineTag(ctx context.Context, tag huggingface.PipelineTag, limit int, authorizationKey string) (huggingface.Models, error) {
 	// Get models from api
-	models, err := app.H().GetModelsByPipelineTag(tag, limit, authorizationKey)
+	models, err := app.H().GetModelsByPipelineTag(ctx, tag, limit, authorizationKey)
+
+	// Filter models on compatible modules
+	return getModelsByModules(models), err
+}
+
+// SearchModels from hugging face api using the given query parameters
+func SearchModels(ctx context.Context, params huggingface.SearchParams, authorizationKey string) (huggingface.Models, error) {
+	// Get models from api
+	models, err := app.H().SearchModels(ctx, params, authorizationKey)
 
 	// Filter models on compatible modules
 	return getModelsByModules(models), err
 }
 
 // GetModelById from hugging face api by id
-func GetModelById(id string, authorizationKey string) (huggingface.Model, error) {
+func GetModelById(ctx context.Context, id string, authorizationKey string) (huggingface.Model, error) {
 	// Get model from api
-	model, err := app.H().GetModelById(id, authorizationKey)
+	model, err := app.H().GetModelById(ctx, id, authorizationKey)
 	if err != nil {
-		return huggingface.Model{}, err
+		return huggingface.Model{}, explainError(id, err)
 	}
 
-	// Verify if the library is compatible
+	return validateModelLibrary(model)
+}
+
+// GetModelByRevision from hugging face api by id at the given revision, the latest one being used by default
+func GetModelByRevision(ctx context.Context, id string, revision string, authorizationKey string) (huggingface.Model, error) {
+	if revision == "" {
+		return GetModelById(ctx, id, authorizationKey)
+	}
+
+	// Get model from api
+	model, err := app.H().GetModelByRevision(ctx, id, revision, authorizationKey)
+	if err != nil {
+		return huggingface.Model{}, explainError(id, err)
+	}
+
+	return validateModelLibrary(model)
+}
+
+// explainError wraps the hub errors with what the user can do about them
+func explainError(id string, err error) error {
+	switch {
+	case errors.Is(err, huggingface.ErrNotFound):
+		return fmt.Errorf("model %s doesn't exist on the hub, check its name and revision : %w", id, err)
+	case errors.Is(err, huggingface.ErrGated):
+		return fmt.Errorf("model %s is gated, request its access on %s/%s then provide an access token : %w",
+			id, config.GetHubSettings().Endpoint.Value, id, err)
+	case errors.Is(err, huggingface.ErrUnauthorized):
+		return fmt.Errorf("model %s requires a valid access token, check the one provided : %w", id, err)
+	case errors.Is(err, huggingface.ErrRateLimited):
+		if wait := huggingface.RetryAfter(err); wait > 0 {
+			return fmt.Errorf("the hub rate limit was reached while fetching %s, retry in %s : %w", id, wait, err)
+		}
+		return fmt.Errorf("the hub rate limit was reached while fetching %s, retry later : %w", id, err)
+	case errors.Is(err, context.Canceled):
+		return fmt.Errorf("fetching %s was interrupted : %w", id, err)
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return fmt.Errorf("the hub didn't answer in time while fetching %s, check your connection or raise %s : %w",
+			id, config.HuggingFaceTimeoutKey, err)
+	}
+	return err
+}
+
+// validateModelLibrary verifies if the library of the model is compatible
+func validateModelLibrary(model huggingface.Model) (huggingface.Model, error) {
 	modules := huggingface.AllModulesString()
 	if !stringutil.SliceContainsItem(modules, string(model.LibraryName)) {
 		return huggingface.Model{}, fmt.Errorf("downloading models from %s library is not allowed", model.LibraryName)
 	}
 
-	return model, err
+	return model, nil
 }
 
 // GetModelsByMultiplePipelineTags get the list of models with given types
-func GetModelsByMultiplePipelineTags(tags []string, authorizationKey string) (allModelsWithTags huggingface.Models, err error) {
+func GetModelsByMultiplePipelineTags(ctx context.Context, tags []string, authorizationKey string) (allModelsWithTags huggingface.Models, err error) {
 	// Get list of models with current tags
 	for _, tag := range tags {
-		huggingfaceModels, err := GetModelsByPipelineTag(huggingface.PipelineTag(tag), 0, authorizationKey)
+		huggingfaceModels, err := GetModelsByPipelineTag(ctx, huggingface.PipelineTag(tag), 0, authorizationKey)
 		if err != nil {
 			return huggingface.Models{}, fmt.Errorf("error while calling api endpoint")
 		}
@@ -1,10 +1,14 @@
 package hfinterface
 
 import (
+	"context"
+	"errors"
 	"github.com/easy-model-fusion/emf-cli/internal/app"
 	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
 	"github.com/easy-model-fusion/emf-cli/test"
+	"strings"
 	"testing"
+	"time"
 )
 
 // Tests GetModelById with valid module
@@ -14,7 +18,7 @@ func TestGetModelById_WithValidModule(t *testing.T) {
 	app.SetHuggingFace(&huggingfaceInterface)
 
 	// Get model by id
-	fetchedModel, err := GetModelById("test", "")
+	fetchedModel, err := GetModelById(context.Background(), "test", "")
 
 	// Assertions
 	test.AssertEqual(t, err, nil)
@@ -28,7 +32,7 @@ func TestGetModelById_WithInvalidModule(t *testing.T) {
 	app.SetHuggingFace(&huggingfaceInterface)
 
 	// Get model by id
-	_, err := GetModelById("test", "")
+	_, err := GetModelById(context.Background(), "test", "")
 
 	// Assertions
 	test.AssertEqual(t, err.Error(), "downloading models from test library is not allowed")
@@ -51,7 +55,7 @@ func TestGetModelsByPipelineTag(t *testing.T) {
 	app.SetHuggingFace(&huggingfaceInterface)
 
 	// Get models by pipeline tag
-	fetchedModels, err := GetModelsByPipelineTag(huggingface.TextToImage, 0, "")
+	fetchedModels, err := GetModelsByPipelineTag(context.Background(), huggingface.TextToImage, 0, "")
 
 	// Assertions
 	test.AssertEqual(t, err, nil)
@@ -75,7 +79,7 @@ func TestGetModelsByMultiplePipelineTags(t *testing.T) {
 	app.SetHuggingFace(&huggingfaceInterface)
 
 	// Get models by multiple pipeline tags
-	fetchedModels, err := GetModelsByMultiplePipelineTags([]string{"tag1", "tag2"}, "")
+	fetchedModels, err := GetModelsByMultiplePipelineTags(context.Background(), []string{"tag1", "tag2"}, "")
 
 	// Assertions
 	test.AssertEqual(t, err, nil)
@@ -105,3 +109,74 @@ func Test_getModelsByModules(t *testing.T) {
 	test.AssertEqual(t, filteredModels[3].Name, "model4")
 	test.AssertEqual(t, filteredModels[4].Name, "model7")
 }
+
+// Tests SearchModels
+func TestSearchModels(t *testing.T) {
+	// Init
+	var hfModels huggingface.Models
+	hfModels = append(hfModels, huggingface.Model{Name: "model1", LibraryName: huggingface.TRANSFORMERS})
+	hfModels = append(hfModels, huggingface.Model{Name: "model2", LibraryName: "invalid"})
+	hfModels = append(hfModels, huggingface.Model{Name: "model3", LibraryName: huggingface.DIFFUSERS})
+
+	// Create huggingface mock
+	huggingfaceInterface := huggingface.MockHuggingFace{GetModelsResult: hfModels}
+	app.SetHuggingFace(&huggingfaceInterface)
+
+	// Search models
+	fetchedModels, err := SearchModels(context.Background(), huggingface.SearchParams{Search: "model"}, "")
+
+	// Assertions
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, len(fetchedModels), 2)
+	test.AssertEqual(t, fetchedModels[0].Name, "model1")
+	test.AssertEqual(t, fetchedModels[1].Name, "model3")
+}
+
+// Tests GetModelByRevision with valid module
+func TestGetModelByRevision_WithValidModule(t *testing.T) {
+	// Create huggingface mock
+	huggingfaceInterface := huggingface.MockHuggingFace{GetModelResult: huggingface.Model{LibraryName: huggingface.DIFFUSERS, Sha: "abc"}}
+	app.SetHuggingFace(&huggingfaceInterface)
+
+	// Get model by revision
+	fetchedModel, err := GetModelByRevision(context.Background(), "test", "v1", "")
+
+	// Assertions
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, fetchedModel.Name, "test")
+	test.AssertEqual(t, fetchedModel.Sha, "abc")
+}
+
+// Tests GetModelByRevision with invalid module
+func TestGetModelByRevision_WithInvalidModule(t *testing.T) {
+	// Create huggingface mock
+	huggingfaceInterface := huggingface.MockHuggingFace{GetModelResult: huggingface.Model{LibraryName: "test"}}
+	app.SetHuggingFace(&huggingfaceInterface)
+
+	// Get model by revision
+	_, err := GetModelByRevision(context.Background(), "test", "", "")
+
+	// Assertions
+	test.AssertEqual(t, err.Error(), "downloading models from test library is not allowed")
+}
+
+// Tests GetModelById with the errors returned by the hub
+func TestGetModelById_WithHubErrors(t *testing.T) {
+	for _, hubErr := range []error{huggingface.ErrNotFound, huggingface.ErrGated, huggingface.ErrUnauthorized, huggingface.ErrRateLimited, context.Canceled} {
+		// Create huggingface mock
+		huggingfaceInterface := huggingface.MockHuggingFace{Error: &huggingface.APIError{Err: hubErr, RetryAfter: time.Minute}}
+		app.SetHuggingFace(&huggingfaceInterface)
+
+		// Get model by id
+		_, err := GetModelById(context.Background(), "org/model", "")
+
+		// Assertions
+		test.AssertEqual(t, errors.Is(err, hubErr), true)
+		test.AssertEqual(t, strings.Contains(err.Error(), "org/model"), true)
+	}
+
+	// The rate limit tells how long to wait
+	app.SetHuggingFace(&huggingface.MockHuggingFace{Error: &huggingface.APIError{Err: huggingface.ErrRateLimited, RetryAfter: time.Minute}})
+	_, err := GetModelById(context.Background(), "org/model", "")
+	test.AssertEqual(t, strings.Contains(err.Error(), "retry in 1m0s"), true)
+}
@@ -0,0 +1,22 @@
+package hfinterface
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/easy-model-fusion/emf-cli/internal/config"
+	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
+)
+
+// WhoAmI returns the owner of the access token from hugging face api, validating the token
+func WhoAmI(ctx context.Context, authorizationKey string) (huggingface.User, error) {
+	user, err := app.H().WhoAmI(ctx, authorizationKey)
+	if errors.Is(err, huggingface.ErrUnauthorized) {
+		return huggingface.User{}, fmt.Errorf("the access token is invalid or revoked, create a new one on %s/settings/tokens : %w",
+			config.GetHubSettings().Endpoint.Value, err)
+	} else if err != nil {
+		return huggingface.User{}, fmt.Errorf("the access token couldn't be validated : %w", err)
+	}
+	return user, nil
+}
@@ -0,0 +1,363 @@
+// Package lockfile
+// This file contains the project lockfile which records exactly what was resolved for the configured models.
+//
+// The configuration file describes what the user asked for, while the lockfile describes what was resolved :
+// - the commit sha of every model
+// - the files downloaded for every model and tokenizer, with their size and sha256
+// - the sdk tag
+// - the python packages installed in the virtual environment
+//
+// The lockfile is generated next to the configuration file and should be committed along with it,
+// so that the install and tidy commands can reproduce the exact same project.
+package lockfile
+
+import (
+	"fmt"
+	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/easy-model-fusion/emf-cli/internal/config"
+	"github.com/easy-model-fusion/emf-cli/internal/model"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/fileutil"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/python"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+	"os"
+	"sort"
+	"strings"
+)
+
+const FileName = "emf.lock"
+const formatVersion = 1
+
+// packagingTools are the packages shipped with any virtual environment, which are neither locked nor installed from the lockfile
+var packagingTools = map[string]bool{"pip": true, "setuptools": true, "wheel": true}
+
+// Lock represents the content of the lockfile
+type Lock struct {
+	Version  int               `yaml:"version"`
+	SdkTag   string            `yaml:"sdk-tag"`
+	Packages map[string]string `yaml:"packages"`
+	Models   []Model           `yaml:"models"`
+}
+
+// Model represents what was resolved for a configured model
+type Model struct {
+	Name       string      `yaml:"name"`
+	Revision   string      `yaml:"revision"`
+	Files      []File      `yaml:"files"`
+	Tokenizers []Tokenizer `yaml:"tokenizers,omitempty"`
+}
+
+// Tokenizer represents what was resolved for a configured tokenizer
+type Tokenizer struct {
+	Class string `yaml:"class"`
+	Files []File `yaml:"files"`
+}
+
+// File represents a file downloaded on the device
+type File struct {
+	Path   string `yaml:"path"`
+	Size   int64  `yaml:"size"`
+	Sha256 string `yaml:"sha256"`
+}
+
+// GetPath returns the path to the lockfile, next to the configuration file
+func GetPath() string {
+	return fileutil.PathJoin(config.FilePath, FileName)
+}
+
+// GetVenvPath returns the path to the virtual environment whose packages are locked, next to the configuration file
+func GetVenvPath() string {
+	return fileutil.PathJoin(config.FilePath, ".venv")
+}
+
+// Read reads the lockfile, returning false if it doesn't exist
+func Read() (lock Lock, exists bool, err error) {
+	content, err := os.ReadFile(GetPath())
+	if os.IsNotExist(err) {
+		return lock, false, nil
+	} else if err != nil {
+		return lock, false, err
+	}
+
+	if err = yaml.Unmarshal(content, &lock); err != nil {
+		return lock, true, fmt.Errorf("error reading lockfile : %s", err)
+	}
+	return lock, true, nil
+}
+
+// Write writes the lockfile
+func Write(lock Lock) error {
+	content, err := yaml.Marshal(lock)
+	if err != nil {
+		return err
+	}
+	header := []byte("# This file is generated by " + app.Name + ", do not edit it manually.\n")
+	return os.WriteFile(GetPath(), append(header, content...), 0644)
+}
+
+// Refresh regenerates the lockfile from the configured models and the device
+// Make sure the config is loaded before calling this function
+func Refresh() error {
+	models, err := config.GetModels()
+	if err != nil {
+		return err
+	}
+
+	previous, _, err := Read()
+	if err != nil {
+		return err
+	}
+
+	lock, err := Build(models, previous)
+	if err != nil {
+		return err
+	}
+
+	return Write(lock)
+}
+
+// Build builds the lock of the given models
+// The checksums of the previous lock are reused for unchanged files, so that they don't have to be computed again
+func Build(models model.Models, previous Lock) (lock Lock, err error) {
+	lock.Version = formatVersion
+	lock.SdkTag = viper.GetString("sdk-tag")
+	installed, err := python.GetInstalledPackages(GetVenvPath())
+	if err != nil {
+		return lock, err
+	}
+	lock.Packages = make(map[string]string)
+	for name, version := range installed {
+		if !packagingTools[name] {
+			lock.Packages[name] = version
+		}
+	}
+
+	mapPrevious := previous.Map()
+	lock.Models = []Model{}
+	for _, current := range models {
+		// A model configured without any revision stays pinned to its locked one
+		// Checksums can only be reused if the revision didn't change
+		var previousModel Model
+		revision := current.Revision
+		if found, exists := mapPrevious[current.Name]; exists {
+			if revision == "" {
+				revision = found.Revision
+			}
+			if found.Revision == revision {
+				previousModel = found
+			}
+		}
+
+		lockedModel := Model{Name: current.Name, Revision: revision, Files: []File{}}
+		if current.IsDownloaded {
+			// Transformers store their tokenizers within the base path : only the model path is locked for the model itself
+			current.UpdatePaths()
+			lockedModel.Files, err = buildFiles(current.Path, previousModel.Files)
+			if err != nil {
+				return lock, err
+			}
+
+			mapPreviousTokenizers := previousModel.TokenizersMap()
+			for _, tokenizer := range lockedTokenizers(current) {
+				files, err := buildFiles(tokenizer.Path, mapPreviousTokenizers[tokenizer.Class].Files)
+				if err != nil {
+					return lock, err
+				}
+				lockedModel.Tokenizers = append(lockedModel.Tokenizers, Tokenizer{Class: tokenizer.Class, Files: files})
+			}
+		}
+		lock.Models = append(lock.Models, lockedModel)
+	}
+
+	return lock, nil
+}
+
+// lockedTokenizers returns the tokenizers whose files are locked
+// The tokenizer of a single file is loaded from the directory of the file, among files it doesn't own : it isn't locked.
+func lockedTokenizers(current model.Model) model.Tokenizers {
+	if current.Source == model.CUSTOM {
+		return nil
+	}
+	return current.Tokenizers
+}
+
+// buildFiles builds the lock of the files stored under the given path, sorted by path
+func buildFiles(path string, previous []File) ([]File, error) {
+	files := []File{}
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return files, nil
+	} else if err != nil {
+		return files, err
+	}
+
+	sizes, err := fileutil.PathFilesSize(path)
+	if err != nil {
+		return files, err
+	}
+
+	mapPrevious := make(map[string]File)
+	for _, file := range previous {
+		mapPrevious[file.Path] = file
+	}
+
+	for name, size := range sizes {
+		// Unchanged file : reusing its checksum
+		if found, exists := mapPrevious[name]; exists && found.Size == size && found.Sha256 != "" {
+			files = append(files, found)
+			continue
+		}
+
+		filePath := path
+		if info.IsDir() {
+			filePath = fileutil.PathJoin(path, name)
+		}
+		sum, err := fileutil.FileSha256(filePath)
+		if err != nil {
+			return files, err
+		}
+		files = append(files, File{Path: name, Size: size, Sha256: sum})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Path < files[j].Path
+	})
+	return files, nil
+}
+
+// Map creates a map from the locked models for faster lookup.
+func (l Lock) Map() map[string]Model {
+	result := make(map[string]Model)
+	for _, current := range l.Models {
+		result[current.Name] = current
+	}
+	return result
+}
+
+// TokenizersMap creates a map from the locked tokenizers for faster lookup.
+func (m Model) TokenizersMap() map[string]Tokenizer {
+	result := make(map[string]Tokenizer)
+	for _, current := range m.Tokenizers {
+		result[current.Class] = current
+	}
+	return result
+}
+
+// Verify returns the differences between the configured models and the lock
+func (l Lock) Verify(models model.Models) (differences []string) {
+	mapLocked := l.Map()
+	for _, current := range models {
+		locked, exists := mapLocked[current.Name]
+		if !exists {
+			differences = append(differences, fmt.Sprintf("model '%s' is configured but not locked", current.Name))
+			continue
+		}
+		// A model configured without any revision is resolved by the lock
+		if current.Revision != "" && current.Revision != locked.Revision {
+			differences = append(differences, fmt.Sprintf("model '%s' is configured at revision '%s' but locked at revision '%s'",
+				current.Name, current.Revision, locked.Revision))
+		}
+	}
+
+	mapConfigured := models.Map()
+	for _, locked := range l.Models {
+		if _, exists := mapConfigured[locked.Name]; !exists {
+			differences = append(differences, fmt.Sprintf("model '%s' is locked but not configured", locked.Name))
+		}
+	}
+
+	return differences
+}
+
+// VerifyFiles returns the differences between the files locked for the model and those on the device
+func (m Model) VerifyFiles(current model.Model) (differences []string, err error) {
+	current.UpdatePaths()
+	files, err := buildFiles(current.Path, nil)
+	if err != nil {
+		return differences, err
+	}
+	differences = append(differences, compareFiles(current.Name, m.Files, files)...)
+
+	mapLockedTokenizers := m.TokenizersMap()
+	for _, tokenizer := range lockedTokenizers(current) {
+		locked, exists := mapLockedTokenizers[tokenizer.Class]
+		if !exists {
+			continue
+		}
+		files, err = buildFiles(tokenizer.Path, nil)
+		if err != nil {
+			return differences, err
+		}
+		differences = append(differences, compareFiles(current.Name+"/"+tokenizer.Class, locked.Files, files)...)
+	}
+
+	return differences, nil
+}
+
+// VerifyPackages returns the differences between the locked python packages and the installed ones
+func (l Lock) VerifyPackages(installed map[string]string) (differences []string) {
+	for name, version := range l.Packages {
+		if packagingTools[name] {
+			continue
+		}
+		found, exists := installed[name]
+		if !exists {
+			differences = append(differences, fmt.Sprintf("package '%s' is locked at version '%s' but not installed", name, version))
+		} else if found != version {
+			differences = append(differences, fmt.Sprintf("package '%s' is locked at version '%s' but version '%s' is installed",
+				name, version, found))
+		}
+	}
+	sort.Strings(differences)
+	return differences
+}
+
+// Constraints returns the locked python packages available from PyPI as pip requirements pinned to their version
+// (i.e. diffusers==0.27.0), sorted by name
+// The packaging tools and the packages locked at a local version (i.e. torch==2.2.0+cu121) are left out
+func (l Lock) Constraints() []string {
+	return l.constraints(false)
+}
+
+// LocalConstraints returns the locked python packages pinned to a local version (i.e. torch==2.2.0+cu121), sorted by name
+// Those are only available from their own package index
+func (l Lock) LocalConstraints() []string {
+	return l.constraints(true)
+}
+
+// constraints returns the locked python packages pinned to their version, whether local or not, sorted by name
+func (l Lock) constraints(local bool) []string {
+	var constraints []string
+	for name, version := range l.Packages {
+		if packagingTools[name] || strings.Contains(version, "+") != local {
+			continue
+		}
+		constraints = append(constraints, name+"=="+version)
+	}
+	sort.Strings(constraints)
+	return constraints
+}
+
+// compareFiles returns the differences between the locked files and the actual files
+func compareFiles(name string, locked []File, actual []File) (differences []string) {
+	mapActual := make(map[string]File)
+	for _, file := range actual {
+		mapActual[file.Path] = file
+	}
+
+	for _, file := range locked {
+		found, exists := mapActual[file.Path]
+		if !exists {
+			differences = append(differences, fmt.Sprintf("file '%s' of '%s' is locked but missing", file.Path, name))
+		} else if found.Sha256 != file.Sha256 {
+			differences = append(differences, fmt.Sprintf("file '%s' of '%s' doesn't match its locked checksum", file.Path, name))
+		}
+		delete(mapActual, file.Path)
+	}
+
+	for path := range mapActual {
+		differences = append(differences, fmt.Sprintf("file '%s' of '%s' is not locked", path, name))
+	}
+	sort.Strings(differences)
+	return differences
+}
@@ -0,0 +1,273 @@
+package lockfile
+
+import (
+	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/easy-model-fusion/emf-cli/internal/config"
+	"github.com/easy-model-fusion/emf-cli/internal/model"
+	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
+	"github.com/easy-model-fusion/emf-cli/test"
+	"github.com/easy-model-fusion/emf-cli/test/mock"
+	"github.com/spf13/viper"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	app.SetUI(&mock.MockUI{})
+	os.Exit(m.Run())
+}
+
+// getTestModels returns the models used by the lockfile tests
+func getTestModels() model.Models {
+	return model.Models{
+		{Name: "org/diffusers", Module: huggingface.DIFFUSERS, Revision: "sha1", IsDownloaded: true},
+		{Name: "org/transformers", Module: huggingface.TRANSFORMERS, Revision: "sha2", IsDownloaded: true,
+			Tokenizers: model.Tokenizers{{Class: "Tokenizer"}}},
+		{Name: "org/configuration", Module: huggingface.DIFFUSERS, Revision: "sha3"},
+	}
+}
+
+// TestBuild_Success tests that the files of the models and tokenizers are locked.
+func TestBuild_Success(t *testing.T) {
+	// Init
+	ts := test.TestSuite{}
+	_ = ts.CreateFullTestSuite(t)
+	defer ts.CleanTestSuite(t)
+	viper.Set("sdk-tag", "v1.0.0")
+	models := getTestModels()
+	test.CreateFiles(t, models[0].GetBasePath(), map[string]string{"unet/weights": "hello"})
+	test.CreateFiles(t, models[1].GetBasePath(), map[string]string{"model/weights": "abc", "Tokenizer/vocab": "a"})
+
+	// Execute
+	lock, err := Build(models, Lock{})
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, lock.Version, formatVersion)
+	test.AssertEqual(t, lock.SdkTag, "v1.0.0")
+	test.AssertEqual(t, len(lock.Models), 3)
+	test.AssertEqual(t, lock.Models[0].Revision, "sha1")
+	test.AssertEqual(t, len(lock.Models[0].Files), 1)
+	test.AssertEqual(t, lock.Models[0].Files[0].Path, "unet/weights")
+	test.AssertEqual(t, lock.Models[0].Files[0].Size, int64(5))
+	test.AssertEqual(t, lock.Models[0].Files[0].Sha256, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824")
+	test.AssertEqual(t, len(lock.Models[1].Files), 1)
+	test.AssertEqual(t, lock.Models[1].Files[0].Path, "weights")
+	test.AssertEqual(t, len(lock.Models[1].Tokenizers), 1)
+	test.AssertEqual(t, lock.Models[1].Tokenizers[0].Files[0].Path, "vocab")
+	test.AssertEqual(t, len(lock.Models[2].Files), 0)
+}
+
+// TestBuild_SingleFile tests that only the file of a single file model is locked, not the directory of its tokenizer.
+func TestBuild_SingleFile(t *testing.T) {
+	// Init
+	ts := test.TestSuite{}
+	_ = ts.CreateFullTestSuite(t)
+	defer ts.CleanTestSuite(t)
+	test.CreateFiles(t, "models/host", map[string]string{"llm.gguf": "hello", "other.gguf": "other"})
+	models := model.Models{{Name: "host/llm", Path: "models/host/llm.gguf", Module: huggingface.TRANSFORMERS, Source: model.CUSTOM,
+		IsDownloaded: true, Tokenizers: model.Tokenizers{{Class: "AutoTokenizer", Path: "models/host"}}}}
+
+	// Execute
+	lock, err := Build(models, Lock{})
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, len(lock.Models[0].Files), 1)
+	test.AssertEqual(t, len(lock.Models[0].Tokenizers), 0)
+}
+
+// TestBuild_Packages tests that the packages of the virtual environment next to the configuration file are locked,
+// except for the packaging tools.
+func TestBuild_Packages(t *testing.T) {
+	// Init
+	ts := test.TestSuite{}
+	_ = ts.CreateFullTestSuite(t)
+	defer ts.CleanTestSuite(t)
+	config.FilePath = "project"
+	defer func() { config.FilePath = "." }()
+	sitePackages := filepath.Join("project", ".venv", "lib", "python3.11", "site-packages")
+	test.CreateFiles(t, sitePackages, map[string]string{
+		"diffusers-0.27.0.dist-info/METADATA": "",
+		"pip-24.0.dist-info/METADATA":         "",
+		"setuptools-69.0.dist-info/METADATA":  "",
+	})
+
+	// Execute
+	lock, err := Build(model.Models{}, Lock{})
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, len(lock.Packages), 1)
+	test.AssertEqual(t, lock.Packages["diffusers"], "0.27.0")
+}
+
+// TestBuild_ReusePrevious tests that the checksums of unchanged files are reused.
+func TestBuild_ReusePrevious(t *testing.T) {
+	// Init
+	ts := test.TestSuite{}
+	_ = ts.CreateFullTestSuite(t)
+	defer ts.CleanTestSuite(t)
+	models := model.Models{getTestModels()[0]}
+	test.CreateFiles(t, models[0].GetBasePath(), map[string]string{"weights": "hello"})
+	previous := Lock{Models: []Model{{Name: "org/diffusers", Revision: "sha1", Files: []File{{Path: "weights", Size: 5, Sha256: "previous"}}}}}
+
+	// Execute
+	lock, err := Build(models, previous)
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, lock.Models[0].Files[0].Sha256, "previous")
+
+	// A new revision invalidates the previous checksums
+	previous.Models[0].Revision = "sha0"
+	lock, err = Build(models, previous)
+	test.AssertEqual(t, err, nil)
+	test.AssertNotEqual(t, lock.Models[0].Files[0].Sha256, "previous")
+}
+
+// TestBuild_KeepLockedRevision tests that a model configured without any revision keeps its locked revision.
+func TestBuild_KeepLockedRevision(t *testing.T) {
+	// Init
+	ts := test.TestSuite{}
+	_ = ts.CreateFullTestSuite(t)
+	defer ts.CleanTestSuite(t)
+	models := model.Models{getTestModels()[0]}
+	models[0].Revision = ""
+	test.CreateFiles(t, models[0].GetBasePath(), map[string]string{"weights": "hello"})
+	previous := Lock{Models: []Model{{Name: "org/diffusers", Revision: "sha1", Files: []File{{Path: "weights", Size: 5, Sha256: "previous"}}}}}
+
+	// Execute
+	lock, err := Build(models, previous)
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, lock.Models[0].Revision, "sha1")
+	test.AssertEqual(t, lock.Models[0].Files[0].Sha256, "previous")
+
+	// A model never locked has no revision to keep
+	lock, err = Build(models, Lock{})
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, lock.Models[0].Revision, "")
+}
+
+// TestWriteRead_Success tests that a written lockfile can be read again.
+func TestWriteRead_Success(t *testing.T) {
+	// Init
+	ts := test.TestSuite{}
+	_ = ts.CreateFullTestSuite(t)
+	defer ts.CleanTestSuite(t)
+	config.FilePath = "."
+	lock := Lock{Version: formatVersion, SdkTag: "v1", Packages: map[string]string{"torch": "2.2.0"},
+		Models: []Model{{Name: "org/model", Revision: "sha", Files: []File{{Path: "weights", Size: 1, Sha256: "sum"}}}}}
+
+	// Missing lockfile
+	_, exists, err := Read()
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, exists, false)
+
+	// Execute
+	err = Write(lock)
+	test.AssertEqual(t, err, nil)
+	result, exists, err := Read()
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, exists, true)
+	test.AssertEqual(t, result.SdkTag, "v1")
+	test.AssertEqual(t, result.Packages["torch"], "2.2.0")
+	test.AssertEqual(t, result.Models[0].Files[0].Sha256, "sum")
+}
+
+// TestRefresh_Success tests that the lockfile is generated from the configuration file.
+func TestRefresh_Success(t *testing.T) {
+	// Init
+	ts := test.TestSuite{}
+	_ = ts.CreateFullTestSuite(t)
+	defer ts.CleanTestSuite(t)
+	config.FilePath = "."
+	err := config.GetViperConfig(".")
+	test.AssertEqual(t, err, nil)
+	viper.Set("models", getTestModels())
+	test.AssertEqual(t, config.WriteViperConfig(), nil)
+
+	// Execute
+	err = Refresh()
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	lock, exists, err := Read()
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, exists, true)
+	test.AssertEqual(t, len(lock.Models), 3)
+}
+
+// TestVerify tests that the differences between the configuration and the lock are found.
+func TestVerify(t *testing.T) {
+	// Init
+	lock := Lock{Models: []Model{
+		{Name: "org/diffusers", Revision: "sha1"},
+		{Name: "org/transformers", Revision: "sha0"},
+		{Name: "org/removed", Revision: "sha4"},
+	}}
+
+	// Execute
+	differences := lock.Verify(getTestModels())
+
+	// Assert
+	test.AssertEqual(t, len(differences), 3)
+	test.AssertEqual(t, differences[0], "model 'org/transformers' is configured at revision 'sha2' but locked at revision 'sha0'")
+	test.AssertEqual(t, differences[1], "model 'org/configuration' is configured but not locked")
+	test.AssertEqual(t, differences[2], "model 'org/removed' is locked but not configured")
+}
+
+// TestVerifyFiles tests that the differences between the locked files and the device are found.
+func TestVerifyFiles(t *testing.T) {
+	// Init
+	ts := test.TestSuite{}
+	_ = ts.CreateFullTestSuite(t)
+	defer ts.CleanTestSuite(t)
+	current := getTestModels()[0]
+	test.CreateFiles(t, current.GetBasePath(), map[string]string{"weights": "hello", "extra": "a"})
+	locked := Model{Name: current.Name, Files: []File{
+		{Path: "weights", Size: 5, Sha256: "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"},
+		{Path: "missing", Size: 1, Sha256: "sum"},
+	}}
+
+	// Execute
+	differences, err := locked.VerifyFiles(current)
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, len(differences), 2)
+	test.AssertEqual(t, differences[0], "file 'extra' of 'org/diffusers' is not locked")
+	test.AssertEqual(t, differences[1], "file 'missing' of 'org/diffusers' is locked but missing")
+}
+
+// TestConstraints tests that the locked packages available from PyPI are pinned to their version.
+func TestConstraints(t *testing.T) {
+	// Init
+	lock := Lock{Packages: map[string]string{"torch": "2.2.0+cu118", "diffusers": "0.27.0", "accelerate": "0.28.0", "pip": "24.0"}}
+
+	// Execute
+	constraints := lock.Constraints()
+
+	// Assert
+	test.AssertEqual(t, len(constraints), 2)
+	test.AssertEqual(t, constraints[0], "accelerate==0.28.0")
+	test.AssertEqual(t, constraints[1], "diffusers==0.27.0")
+}
+
+// TestLocalConstraints tests that only the packages locked at a local version are returned.
+func TestLocalConstraints(t *testing.T) {
+	// Init
+	lock := Lock{Packages: map[string]string{"torch": "2.2.0+cu118", "diffusers": "0.27.0"}}
+
+	// Execute
+	constraints := lock.LocalConstraints()
+
+	// Assert
+	test.AssertEqual(t, len(constraints), 1)
+	test.AssertEqual(t, constraints[0], "torch==2.2.0+cu118")
+}
@@ -3,7 +3,9 @@ package model
 import (
 	"context"
 	"errors"
+	"fmt"
 	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/easy-model-fusion/emf-cli/internal/downloader"
 	"github.com/easy-model-fusion/emf-cli/internal/downloader/model"
 	"github.com/easy-model-fusion/emf-cli/internal/utils/fileutil"
 	"github.com/easy-model-fusion/emf-cli/internal/utils/stringutil"
@@ -26,6 +28,11 @@ func (m *Model) FromDownloaderModel(dlModel downloadermodel.Model) {
 		m.Options = dlModel.Options
 	}
 
+	// The script resolved the downloaded revision
+	if dlModel.Revision != "" {
+		m.Revision = dlModel.Revision
+	}
+
 	// Check if ScriptTokenizer is valid
 	if !dlModel.Tokenizer.Empty() {
 
@@ -98,40 +105,107 @@ func (m *Model) executeDownload(downloaderArgs downloadermodel.Args) (success bo
 	// Running the script (with cancellation handling)
 	var dlModel downloadermodel.Model
 
+	// Downloading into the staging directory : the previous version is only replaced once the download succeeded
+	var transaction *downloadTransaction
+	if !downloaderArgs.OnlyConfiguration {
+		staging := newDownloadTransaction(downloaderArgs.ModelName, downloaderArgs.DirectoryPath)
+		if err = staging.begin(); err != nil {
+			return false, warnings, err
+		}
+		downloaderArgs.DirectoryPath = app.StagingDirectoryPath
+		transaction = &staging
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
-	done := make(chan os.Signal, 1)
-	signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+	interrupted := make(chan os.Signal, 1)
+	signal.Notify(interrupted, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(interrupted)
+
+	// Rendering the progress of the download
+	renderer := newDownloadRenderer(m.Name)
+	downloaderArgs.Events = renderer.Handle
 
 	// Running the script in a goroutine (to handle cancellation, since the script can take a long time)
+	finished := make(chan struct{})
 	go func() {
 		// Running the script
 		dlModel, err = app.Downloader().Execute(downloaderArgs, app.Python(), ctx)
-		// Sending signal to the main goroutine that the script has finished
-		done <- syscall.SIGQUIT
+		// Notifying the main goroutine that the script has finished
+		close(finished)
 	}()
 
-	switch code := <-done; {
-	case code == syscall.SIGQUIT:
+	select {
+	case <-finished:
 		// Do nothing
-	case code == syscall.SIGINT:
-		fallthrough
-	case code == syscall.SIGTERM:
+	case <-interrupted:
 		cancel() // Cancel the context (to stop the script)
-		warnings = append(warnings, "Please note that when cancelling the model, partial files may have been downloaded.")
-		warnings = append(warnings, "Please remove the related model directory or the cache if you want to clean up the partial files.")
+		// Waiting for the script to stop, so that the staged files are no longer written
+		<-finished
+		warnings = append(warnings, renderer.Stop()...)
+		warnings = append(warnings, "Please note that the previous version of the model, if any, is left untouched.")
+		if transaction != nil && transaction.resumable() {
+			warnings = append(warnings, "The partially downloaded files are kept : the download will resume on the next attempt.")
+		} else if transaction != nil && os.RemoveAll(transaction.stagingPath) == nil {
+			warnings = append(warnings, "The partially downloaded files were discarded : the download will start over on the next attempt.")
+		}
 		return false, warnings, errors.New("download cancelled manually")
 	}
 
 	// make sure that the context is cancelled, even if the script has finished
 	cancel()
+	warnings = append(warnings, renderer.Stop()...)
 
 	if err != nil {
+		// Retrying is pointless when offline : the error is returned to stop the process
+		var offlineErr *app.OfflineError
+		if errors.As(err, &offlineErr) {
+			return false, warnings, err
+		}
 		// Something went wrong or no data has been returned
 		return false, warnings, nil
 	}
 
+	// Replacing the previous version by the downloaded one
+	if transaction != nil {
+		if err = transaction.commit(dlModel.Path, dlModel.Tokenizer.Path); err != nil {
+			warnings = append(warnings, fmt.Sprintf("The previous version of %s was kept : %s", m.Name, err))
+			return false, warnings, nil
+		}
+		dlModel.Path = transaction.rebase(dlModel.Path)
+		dlModel.Tokenizer.Path = transaction.rebase(dlModel.Tokenizer.Path)
+	}
+
+	renderer.PrintSummary()
+
 	// Update the model for the configuration file
 	m.FromDownloaderModel(dlModel)
 
 	return true, warnings, err
 }
+
+// EstimateDownloadSize returns the size of the files that would be downloaded for the model at its revision
+func (m *Model) EstimateDownloadSize(ctx context.Context, accessToken string) (size int64, err error) {
+	accessToken, err = m.ResolveAccessToken(accessToken)
+	if err != nil {
+		return 0, err
+	}
+	revision := m.Revision
+	if revision == "" {
+		revision = "main"
+	}
+
+	// Listing the files of the repository with their size
+	hfModel, err := app.H().GetModelByRevision(ctx, m.Name, revision, accessToken)
+	if err != nil {
+		return 0, err
+	}
+
+	downloaderArgs := downloadermodel.Args{
+		ModelName:    m.Name,
+		ModelModule:  string(m.Module),
+		Include:      m.Include,
+		Exclude:      m.Exclude,
+		PreferFormat: m.PreferFormat,
+	}
+	return downloader.EstimateSize(hfModel.Siblings, downloaderArgs), nil
+}
@@ -1,10 +1,12 @@
 package model
 
 import (
+	"context"
 	"errors"
 	"github.com/easy-model-fusion/emf-cli/internal/app"
 	"github.com/easy-model-fusion/emf-cli/internal/downloader/model"
 	"github.com/easy-model-fusion/emf-cli/internal/utils/fileutil"
+	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
 	"github.com/easy-model-fusion/emf-cli/test"
 	"github.com/easy-model-fusion/emf-cli/test/dmock"
 	"github.com/easy-model-fusion/emf-cli/test/mock"
@@ -99,6 +101,7 @@ func TestFromDownloaderModel_Fill(t *testing.T) {
 				"option2": "'text'",
 			},
 		},
+		Revision: "sha",
 	}
 	expected := Model{
 		Path:   fileutil.PathUniformize("/path/to/model"),
@@ -133,6 +136,7 @@ func TestFromDownloaderModel_Fill(t *testing.T) {
 	test.AssertEqual(t, expected.Tokenizers[0].Path, input.Tokenizers[0].Path)
 	test.AssertEqual(t, expected.Tokenizers[0].Class, input.Tokenizers[0].Class)
 	test.AssertEqual(t, len(expected.Tokenizers[0].Options), len(input.Tokenizers[0].Options))
+	test.AssertEqual(t, input.Revision, "sha")
 }
 
 // TestFromDownloaderModel_ReplaceTokenizer tests the Model.FromDownloaderModel to return the correct Model.
@@ -210,6 +214,30 @@ func TestGetConfig_Failure(t *testing.T) {
 	test.AssertEqual(t, reflect.DeepEqual(expected, input), true)
 }
 
+// TestGetConfig_Offline tests that the Model.GetConfig returns the error of the offline mode.
+func TestGetConfig_Offline(t *testing.T) {
+	// Mock python script to fail because of the offline mode
+	SetupDownloaderForFailure()
+	app.Downloader().(*dmock.MockDownloader).DownloaderError = app.NewOfflineError("downloading model")
+	defer SetupDownloaderForFailure()
+
+	// Init
+	input := GetModel(0)
+	downloaderArgs := downloadermodel.Args{
+		ModelName:     input.Name,
+		ModelModule:   string(input.Module),
+		DirectoryPath: app.DownloadDirectoryPath,
+	}
+
+	// Execute
+	success, _, err := input.GetConfig(downloaderArgs)
+
+	// Assert
+	var offlineErr *app.OfflineError
+	test.AssertEqual(t, errors.As(err, &offlineErr), true)
+	test.AssertEqual(t, success, false)
+}
+
 // TestGetConfig_Success tests the Model.GetConfig upon success.
 func TestGetConfig_Success(t *testing.T) {
 	// Init
@@ -353,3 +381,21 @@ func TestTokenizer_Download_Success(t *testing.T) {
 	test.AssertEqual(t, success, true)
 	test.AssertEqual(t, reflect.DeepEqual(expected, input), true)
 }
+
+// TestEstimateDownloadSize tests that the size of the selected files of the repository is returned.
+func TestEstimateDownloadSize(t *testing.T) {
+	// Init
+	app.SetHuggingFace(&huggingface.MockHuggingFace{GetModelResult: huggingface.Model{Siblings: []huggingface.Sibling{
+		{RFileName: "model_index.json", Size: 1},
+		{RFileName: "unet/diffusion_pytorch_model.safetensors", Size: 10},
+		{RFileName: "unet/diffusion_pytorch_model.bin", Size: 100},
+	}}})
+	model := Model{Name: "org/name", Module: huggingface.DIFFUSERS}
+
+	// Execute
+	size, err := model.EstimateDownloadSize(context.Background(), "token")
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, size, int64(11))
+}
@@ -0,0 +1,110 @@
+package model
+
+import (
+	"fmt"
+	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/easy-model-fusion/emf-cli/internal/downloader/model"
+	"github.com/easy-model-fusion/emf-cli/internal/ui"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/fileutil"
+	"sync"
+)
+
+// downloadRenderer renders the events of a download as one progress bar per file
+// When several models are downloaded at the same time, the progress is summarized instead.
+type downloadRenderer struct {
+	name     string
+	mutex    sync.Mutex
+	files    map[string]*fileProgress
+	warnings []string
+	stopped  bool
+
+	// Summarizing the download instead of rendering the progress bars
+	summary         bool
+	downloadedFiles int
+	downloadedSize  int64
+}
+
+// fileProgress represents the progress bar of a file being downloaded
+type fileProgress struct {
+	progressBar ui.ProgressBar
+	total       int64
+	downloaded  int64
+}
+
+// newDownloadRenderer creates a renderer for the download of the named model
+func newDownloadRenderer(name string) *downloadRenderer {
+	return &downloadRenderer{name: name, files: make(map[string]*fileProgress), summary: app.ConcurrentDownloads()}
+}
+
+// Handle renders an event sent by the downloader
+// The files whose size is unknown are downloaded without progress bar.
+func (r *downloadRenderer) Handle(event downloadermodel.Event) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.stopped {
+		return
+	}
+
+	switch event.Type {
+	case downloadermodel.EventFileStarted:
+		r.stopFile(event.File)
+		if r.summary {
+			r.files[event.File] = &fileProgress{total: event.Total, downloaded: event.Downloaded}
+		} else if event.Total > 0 {
+			progressBar := app.UI().StartProgressBar(fmt.Sprintf("%s : %s", r.name, event.File), int(event.Total))
+			progressBar.Add(int(event.Downloaded))
+			r.files[event.File] = &fileProgress{progressBar: progressBar, total: event.Total, downloaded: event.Downloaded}
+		}
+	case downloadermodel.EventProgress:
+		if file, found := r.files[event.File]; found && event.Downloaded > file.downloaded {
+			if file.progressBar != nil {
+				file.progressBar.Add(int(event.Downloaded - file.downloaded))
+			}
+			file.downloaded = event.Downloaded
+		}
+	case downloadermodel.EventFileDone:
+		if file, found := r.files[event.File]; found && r.summary {
+			r.downloadedFiles++
+			r.downloadedSize += max(file.total, file.downloaded)
+		}
+		r.stopFile(event.File)
+	case downloadermodel.EventWarning:
+		r.warnings = append(r.warnings, event.Message)
+	}
+}
+
+// Stop stops the progress bars still running and returns the warnings sent by the downloader
+// The events sent afterward are ignored.
+func (r *downloadRenderer) Stop() (warnings []string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for file := range r.files {
+		r.stopFile(file)
+	}
+	r.stopped = true
+	return r.warnings
+}
+
+// PrintSummary prints what was downloaded, if the download was summarized
+func (r *downloadRenderer) PrintSummary() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.summary && r.downloadedFiles > 0 {
+		printDownloadSummary(r.name, r.downloadedFiles, r.downloadedSize)
+	}
+}
+
+// stopFile stops the progress bar of the file, if any
+func (r *downloadRenderer) stopFile(file string) {
+	if progress, found := r.files[file]; found {
+		if progress.progressBar != nil {
+			progress.progressBar.Stop()
+		}
+		delete(r.files, file)
+	}
+}
+
+// printDownloadSummary prints the number of files and the size downloaded for the model
+func printDownloadSummary(name string, files int, size int64) {
+	app.UI().Success().Printfln("%s : %d file(s) downloaded (%s)", name, files, fileutil.ReadableSize(size))
+}
@@ -0,0 +1,61 @@
+package model
+
+import (
+	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/easy-model-fusion/emf-cli/internal/downloader/model"
+	"github.com/easy-model-fusion/emf-cli/test"
+	"github.com/easy-model-fusion/emf-cli/test/mock"
+	"testing"
+)
+
+// TestDownloadRenderer tests that a progress bar is tracked per file until it is done.
+func TestDownloadRenderer(t *testing.T) {
+	// Init
+	app.SetUI(mock.MockUI{})
+	renderer := newDownloadRenderer("org/name")
+
+	// Execute
+	renderer.Handle(downloadermodel.Event{Type: downloadermodel.EventFileStarted, File: "a", Total: 10, Downloaded: 2})
+	renderer.Handle(downloadermodel.Event{Type: downloadermodel.EventFileStarted, File: "b", Total: 10})
+	renderer.Handle(downloadermodel.Event{Type: downloadermodel.EventFileStarted, File: "unknown size"})
+	renderer.Handle(downloadermodel.Event{Type: downloadermodel.EventProgress, File: "a", Downloaded: 6})
+	renderer.Handle(downloadermodel.Event{Type: downloadermodel.EventFileDone, File: "b"})
+	renderer.Handle(downloadermodel.Event{Type: downloadermodel.EventWarning, Message: "warning"})
+
+	// Assert
+	test.AssertEqual(t, len(renderer.files), 1)
+	test.AssertEqual(t, renderer.files["a"].downloaded, int64(6))
+
+	// Execute : the events sent once stopped are ignored
+	warnings := renderer.Stop()
+	renderer.Handle(downloadermodel.Event{Type: downloadermodel.EventFileStarted, File: "c", Total: 10})
+
+	// Assert
+	test.AssertEqual(t, len(renderer.files), 0)
+	test.AssertEqual(t, len(warnings), 1)
+	test.AssertEqual(t, warnings[0], "warning")
+}
+
+// TestDownloadRenderer_Summary tests that the download is summarized when several models are downloaded at the same time.
+func TestDownloadRenderer_Summary(t *testing.T) {
+	// Init
+	app.SetUI(mock.MockUI{})
+	app.SetConcurrentDownloads(true)
+	defer app.SetConcurrentDownloads(false)
+	renderer := newDownloadRenderer("org/name")
+
+	// Execute
+	renderer.Handle(downloadermodel.Event{Type: downloadermodel.EventFileStarted, File: "a", Total: 10, Downloaded: 2})
+	renderer.Handle(downloadermodel.Event{Type: downloadermodel.EventFileStarted, File: "unknown size"})
+	renderer.Handle(downloadermodel.Event{Type: downloadermodel.EventProgress, File: "unknown size", Downloaded: 4})
+	renderer.Handle(downloadermodel.Event{Type: downloadermodel.EventFileDone, File: "a"})
+	renderer.Handle(downloadermodel.Event{Type: downloadermodel.EventFileDone, File: "unknown size"})
+	renderer.Stop()
+	renderer.PrintSummary()
+
+	// Assert
+	test.AssertEqual(t, renderer.summary, true)
+	test.AssertEqual(t, len(renderer.files), 0)
+	test.AssertEqual(t, renderer.downloadedFiles, 2)
+	test.AssertEqual(t, renderer.downloadedSize, int64(14))
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
+	"path"
 	"strings"
 )
 
@@ -52,6 +53,9 @@ func (m *Model) GetHuggingFaceClassImport() string {
 	case huggingface.DIFFUSERS:
 		return m.Class
 	case huggingface.TRANSFORMERS:
+		if len(m.Tokenizers) == 0 {
+			return m.Class
+		}
 		return fmt.Sprintf("%s, %s", m.Class, m.Tokenizers[0].Class)
 	default:
 		return ""
@@ -147,7 +151,15 @@ func (m *Model) GenInitParamsWithModule() []codegen.Parameter {
 }
 
 // GenSuperInitParamsWithModule generate the init params for the super class
+// Diffusers loads a single file on its own, whereas transformers loads it from its directory :
+// a GGUF file being then given by name, and the tokenizer being loaded from the same directory.
 func (m *Model) GenSuperInitParamsWithModule() []codegen.FunctionCallParameter {
+	modelPath := m.GenModelPath()
+	singleFile := m.Source == CUSTOM
+	if singleFile && m.Module == huggingface.TRANSFORMERS {
+		modelPath = path.Dir(fileutil.PathUniformize(m.Path))
+	}
+
 	params := []codegen.FunctionCallParameter{
 		{
 			Name:  "model_name",
@@ -155,7 +167,7 @@ func (m *Model) GenSuperInitParamsWithModule() []codegen.FunctionCallParameter {
 		},
 		{
 			Name:  "model_path",
-			Value: "\"" + m.GenModelPath() + "\"",
+			Value: "\"" + modelPath + "\"",
 		},
 		{
 			Name:  "model_class",
@@ -167,16 +179,15 @@ func (m *Model) GenSuperInitParamsWithModule() []codegen.FunctionCallParameter {
 		},
 	}
 
-	if m.Source == CUSTOM {
-		// If the model is a single file (source=="custom"), we need to add the single file parameter
-		params = append(params, codegen.FunctionCallParameter{
-			Name:  "single_file",
-			Value: "True",
-		})
-	}
-
 	switch m.Module {
 	case huggingface.DIFFUSERS:
+		if singleFile {
+			// If the model is a single file (source=="custom"), we need to add the single file parameter
+			params = append(params, codegen.FunctionCallParameter{
+				Name:  "single_file",
+				Value: "True",
+			})
+		}
 		params = append(params, codegen.FunctionCallParameter{
 			Value: "**kwargs",
 		})
@@ -187,10 +198,22 @@ func (m *Model) GenSuperInitParamsWithModule() []codegen.FunctionCallParameter {
 			Value: "\"" + string(m.PipelineTag) + "\"",
 		})
 
+		if m.IsGGUF() {
+			params = append(params, codegen.FunctionCallParameter{
+				Name:  "gguf_file",
+				Value: "\"" + path.Base(fileutil.PathUniformize(m.Path)) + "\"",
+			})
+		}
+
 		if len(m.Tokenizers) > 0 {
+			tokenizerPath := fileutil.PathUniformize(m.Tokenizers[0].Path)
+			if singleFile && tokenizerPath == fileutil.PathUniformize(m.Path) {
+				// The tokenizer of a single file is stored alongside it
+				tokenizerPath = modelPath
+			}
 			params = append(params, codegen.FunctionCallParameter{
 				Name:  "tokenizer_path",
-				Value: "\"" + m.Tokenizers[0].Path + "\"",
+				Value: "\"" + tokenizerPath + "\"",
 			})
 			params = append(params, codegen.FunctionCallParameter{
 				Name:  "tokenizer_class",
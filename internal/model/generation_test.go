@@ -225,6 +225,99 @@ func TestModel_GenSuperInitParamsWithModule(t *testing.T) {
 	test.AssertEqual(t, len(params), 6, "The number of parameters should be correct.")
 }
 
+// superInitParams maps the generated init params of the super class by name
+func superInitParams(m Model) map[string]string {
+	params := make(map[string]string)
+	for _, param := range m.GenSuperInitParamsWithModule() {
+		params[param.Name] = param.Value
+	}
+	return params
+}
+
+// TestModel_GenSuperInitParamsWithModule_DiffusersSingleFile tests that diffusers loads the single file itself.
+func TestModel_GenSuperInitParamsWithModule_DiffusersSingleFile(t *testing.T) {
+	// Init
+	m := Model{Name: "host/sdxl", Path: "models/host/sdxl.safetensors", Module: huggingface.DIFFUSERS,
+		Class: "StableDiffusionXLPipeline", PipelineTag: huggingface.TextToImage, Source: CUSTOM, IsDownloaded: true}
+
+	// Execute
+	params := superInitParams(m)
+
+	// Assert
+	test.AssertEqual(t, params["model_path"], "\"models/host/sdxl.safetensors\"")
+	test.AssertEqual(t, params["single_file"], "True")
+	test.AssertEqual(t, params[""], "**kwargs")
+}
+
+// TestModel_GenSuperInitParamsWithModule_TransformersSafetensors tests that transformers loads the single file
+// and its tokenizer from their directory.
+func TestModel_GenSuperInitParamsWithModule_TransformersSafetensors(t *testing.T) {
+	// Init
+	m := Model{Name: "host/llm", Path: "models/host/llm.safetensors", Module: huggingface.TRANSFORMERS,
+		Class: "AutoModelForCausalLM", PipelineTag: huggingface.TextGeneration, Source: CUSTOM, IsDownloaded: true,
+		Tokenizers: Tokenizers{{Class: "AutoTokenizer", Path: "models/host"}}}
+
+	// Execute
+	params := superInitParams(m)
+
+	// Assert
+	test.AssertEqual(t, params["model_path"], "\"models/host\"")
+	test.AssertEqual(t, params["task"], "\"text-generation\"")
+	test.AssertEqual(t, params["tokenizer_path"], "\"models/host\"")
+	test.AssertEqual(t, params["tokenizer_class"], "AutoTokenizer")
+	_, found := params["single_file"]
+	test.AssertEqual(t, found, false)
+	_, found = params["gguf_file"]
+	test.AssertEqual(t, found, false)
+}
+
+// TestModel_GenSuperInitParamsWithModule_TransformersGGUF tests that a GGUF file is given by name along with its directory,
+// the tokenizer previously configured as the weights file being loaded from the same directory.
+func TestModel_GenSuperInitParamsWithModule_TransformersGGUF(t *testing.T) {
+	// Init
+	m := Model{Name: "host/llm", Path: "models/host/llm.Q4.gguf", Module: huggingface.TRANSFORMERS,
+		Class: "AutoModelForCausalLM", PipelineTag: huggingface.TextGeneration, Source: CUSTOM, IsDownloaded: true,
+		Tokenizers: Tokenizers{{Class: "AutoTokenizer", Path: "models/host/llm.Q4.gguf"}}}
+
+	// Execute
+	params := superInitParams(m)
+
+	// Assert
+	test.AssertEqual(t, params["model_path"], "\"models/host\"")
+	test.AssertEqual(t, params["gguf_file"], "\"llm.Q4.gguf\"")
+	test.AssertEqual(t, params["task"], "\"text-generation\"")
+	test.AssertEqual(t, params["tokenizer_path"], "\"models/host\"")
+	_, found := params["single_file"]
+	test.AssertEqual(t, found, false)
+}
+
+// TestModel_GenSuperInitParamsWithModule_TransformersSingleFileTask tests that the task is the configured pipeline tag.
+func TestModel_GenSuperInitParamsWithModule_TransformersSingleFileTask(t *testing.T) {
+	// Init
+	m := Model{Name: "host/captioner", Path: "models/host/captioner.safetensors", Module: huggingface.TRANSFORMERS,
+		Class: "AutoModelForVision2Seq", PipelineTag: huggingface.ImageToText, Source: CUSTOM, IsDownloaded: true}
+
+	// Execute
+	params := superInitParams(m)
+
+	// Assert
+	test.AssertEqual(t, params["task"], "\"image-to-text\"")
+	_, found := params["tokenizer_path"]
+	test.AssertEqual(t, found, false)
+}
+
+// TestGetHuggingFaceClassImport_WithoutTokenizer tests that a transformers model without tokenizer only imports its class.
+func TestGetHuggingFaceClassImport_WithoutTokenizer(t *testing.T) {
+	// Init
+	model := Model{Module: huggingface.TRANSFORMERS, Class: "AutoModelForCausalLM"}
+
+	// Execute
+	result := model.GetHuggingFaceClassImport()
+
+	// Assert
+	test.AssertEqual(t, result, "AutoModelForCausalLM")
+}
+
 func TestModel_GenFile_SingleFile(t *testing.T) {
 	model := Model{
 		Name:        "stabilityai/sdxl-turbo",
@@ -0,0 +1,181 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/fileutil"
+	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Files written by save_pretrained and describing the saved model
+const (
+	diffusersConfigFileName    = "model_index.json"
+	transformersConfigFileName = "config.json"
+	tokenizerConfigFileName    = "tokenizer_config.json"
+	transformersModelDirectory = "model"
+)
+
+// weightsExtensions are the extensions of the files storing the weights of a model
+var weightsExtensions = []string{".safetensors", ".bin", ".pt", ".pth", ".ckpt", ".gguf", ".h5", ".msgpack", ".onnx"}
+
+// localConfig represents the fields of the save_pretrained configuration files used to configure a model
+type localConfig struct {
+	ClassName      string   `json:"_class_name"`
+	Architectures  []string `json:"architectures"`
+	TokenizerClass string   `json:"tokenizer_class"`
+}
+
+// FromLocalDirectory builds a model from a directory saved with save_pretrained
+// The module, class, pipeline tag and tokenizers are detected from the configuration files.
+// The paths of the returned model and tokenizers point to the given directory.
+func FromLocalDirectory(name, path string) (model Model, err error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return model, err
+	}
+	if !info.IsDir() {
+		return model, fmt.Errorf("%s is not a directory", path)
+	}
+
+	model.Name = name
+	model.Source = LOCAL
+
+	// Diffusers pipeline
+	config, found, err := readLocalConfig(fileutil.PathJoin(path, diffusersConfigFileName))
+	if err != nil {
+		return model, err
+	}
+	if found {
+		if config.ClassName == "" {
+			return model, fmt.Errorf("no pipeline class found in %s", diffusersConfigFileName)
+		}
+		model.Module = huggingface.DIFFUSERS
+		model.Class = config.ClassName
+		model.PipelineTag = InferDiffusersPipelineTag(config.ClassName)
+		model.Path = fileutil.PathUniformize(path)
+		return model, nil
+	}
+
+	// Transformers model : either saved at the root or already organized like a downloaded model
+	modelPath := fileutil.PathUniformize(path)
+	config, found, err = readLocalConfig(fileutil.PathJoin(modelPath, transformersConfigFileName))
+	if err != nil {
+		return model, err
+	}
+	if !found {
+		modelPath = fileutil.PathJoin(path, transformersModelDirectory)
+		config, found, err = readLocalConfig(fileutil.PathJoin(modelPath, transformersConfigFileName))
+		if err != nil {
+			return model, err
+		}
+	}
+	if !found {
+		return model, fmt.Errorf("neither %s nor %s found in %s", diffusersConfigFileName, transformersConfigFileName, path)
+	}
+
+	model.Module = huggingface.TRANSFORMERS
+	model.Class = huggingface.AutoTransformers
+	if len(config.Architectures) > 0 {
+		model.Class = config.Architectures[0]
+	}
+	model.PipelineTag = InferTransformersPipelineTag(model.Class)
+	model.Path = modelPath
+	model.Tokenizers, err = findLocalTokenizers(path)
+	return model, err
+}
+
+// findLocalTokenizers returns the tokenizers saved at the root of the directory or in its subdirectories
+func findLocalTokenizers(path string) (tokenizers Tokenizers, err error) {
+	directories := []string{path}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return tokenizers, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			directories = append(directories, fileutil.PathJoin(path, entry.Name()))
+		}
+	}
+
+	for _, directory := range directories {
+		config, found, err := readLocalConfig(fileutil.PathJoin(directory, tokenizerConfigFileName))
+		if err != nil {
+			return tokenizers, err
+		}
+		if !found {
+			continue
+		}
+
+		class := config.TokenizerClass
+		if class == "" {
+			class = "AutoTokenizer"
+		}
+		if tokenizers.ContainsByClass(class) {
+			continue
+		}
+		tokenizers = append(tokenizers, Tokenizer{Class: class, Path: fileutil.PathUniformize(directory)})
+	}
+
+	return tokenizers, nil
+}
+
+// readLocalConfig reads the requested configuration file, returning false if it doesn't exist
+func readLocalConfig(path string) (config localConfig, found bool, err error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return config, false, nil
+	} else if err != nil {
+		return config, false, err
+	}
+	if err = json.Unmarshal(content, &config); err != nil {
+		return config, true, fmt.Errorf("error reading %s : %s", path, err)
+	}
+	return config, true, nil
+}
+
+// IsWeightsFile returns true if the file stores the weights of a model
+func IsWeightsFile(path string) bool {
+	extension := strings.ToLower(filepath.Ext(path))
+	for _, current := range weightsExtensions {
+		if extension == current {
+			return true
+		}
+	}
+	return false
+}
+
+// InferDiffusersPipelineTag infers the pipeline tag from the name of a diffusers pipeline class
+func InferDiffusersPipelineTag(class string) huggingface.PipelineTag {
+	class = strings.ToLower(class)
+	switch {
+	case strings.Contains(class, "img2img"), strings.Contains(class, "imagetoimage"),
+		strings.Contains(class, "pix2pix"), strings.Contains(class, "inpaint"):
+		return huggingface.ImageToImage
+	case strings.Contains(class, "video"):
+		return huggingface.TextToVideo
+	case strings.Contains(class, "audio"), strings.Contains(class, "music"):
+		return huggingface.TextToAudio
+	case strings.Contains(class, "shap"), strings.Contains(class, "3d"):
+		return huggingface.TextTo3d
+	default:
+		return huggingface.TextToImage
+	}
+}
+
+// InferTransformersPipelineTag infers the pipeline tag from the name of a transformers model class
+func InferTransformersPipelineTag(class string) huggingface.PipelineTag {
+	class = strings.ToLower(class)
+	switch {
+	case strings.Contains(class, "vision2seq"), strings.Contains(class, "visionencoderdecoder"),
+		strings.Contains(class, "imagetotext"), strings.Contains(class, "blip"):
+		return huggingface.ImageToText
+	case strings.Contains(class, "texttoaudio"), strings.Contains(class, "texttospeech"),
+		strings.Contains(class, "musicgen"):
+		return huggingface.TextToAudio
+	default:
+		return huggingface.TextGeneration
+	}
+}
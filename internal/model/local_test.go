@@ -0,0 +1,98 @@
+package model
+
+import (
+	"github.com/easy-model-fusion/emf-cli/internal/utils/fileutil"
+	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
+	"github.com/easy-model-fusion/emf-cli/test"
+	"testing"
+)
+
+// TestFromLocalDirectory_Diffusers tests that a diffusers pipeline is detected from its model_index.json.
+func TestFromLocalDirectory_Diffusers(t *testing.T) {
+	// Init
+	directory := t.TempDir()
+	test.CreateFiles(t, directory, map[string]string{
+		"model_index.json":                 `{"_class_name": "StableDiffusionImg2ImgPipeline"}`,
+		"unet/diffusion_pytorch_model.bin": "weights",
+	})
+
+	// Execute
+	result, err := FromLocalDirectory("org/model", directory)
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, result.Name, "org/model")
+	test.AssertEqual(t, result.Source, LOCAL)
+	test.AssertEqual(t, result.Module, huggingface.DIFFUSERS)
+	test.AssertEqual(t, result.Class, "StableDiffusionImg2ImgPipeline")
+	test.AssertEqual(t, result.PipelineTag, huggingface.ImageToImage)
+	test.AssertEqual(t, len(result.Tokenizers), 0)
+}
+
+// TestFromLocalDirectory_Transformers tests that a transformers model and its tokenizers are detected.
+func TestFromLocalDirectory_Transformers(t *testing.T) {
+	// Init
+	directory := t.TempDir()
+	test.CreateFiles(t, directory, map[string]string{
+		"config.json":                 `{"architectures": ["GPT2LMHeadModel"]}`,
+		"tokenizer_config.json":       `{"tokenizer_class": "GPT2Tokenizer"}`,
+		"model.safetensors":           "weights",
+		"other/tokenizer_config.json": `{"tokenizer_class": "GPT2TokenizerFast"}`,
+		"empty/file":                  "",
+	})
+
+	// Execute
+	result, err := FromLocalDirectory("org/model", directory)
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, result.Module, huggingface.TRANSFORMERS)
+	test.AssertEqual(t, result.Class, "GPT2LMHeadModel")
+	test.AssertEqual(t, result.PipelineTag, huggingface.TextGeneration)
+	test.AssertEqual(t, result.Path, fileutil.PathUniformize(directory))
+	test.AssertEqual(t, len(result.Tokenizers), 2)
+	test.AssertEqual(t, result.Tokenizers[0].Class, "GPT2Tokenizer")
+	test.AssertEqual(t, result.Tokenizers[1].Class, "GPT2TokenizerFast")
+	test.AssertEqual(t, result.Tokenizers[1].Path, fileutil.PathJoin(directory, "other"))
+}
+
+// TestFromLocalDirectory_TransformersModelDirectory tests that an already organized transformers model is detected.
+func TestFromLocalDirectory_TransformersModelDirectory(t *testing.T) {
+	// Init
+	directory := t.TempDir()
+	test.CreateFiles(t, directory, map[string]string{
+		"model/config.json": `{"architectures": ["BlipForConditionalGeneration"]}`,
+	})
+
+	// Execute
+	result, err := FromLocalDirectory("org/model", directory)
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, result.Path, fileutil.PathJoin(directory, "model"))
+	test.AssertEqual(t, result.PipelineTag, huggingface.ImageToText)
+}
+
+// TestFromLocalDirectory_Fail tests that a directory without any configuration file can't be imported.
+func TestFromLocalDirectory_Fail(t *testing.T) {
+	// Init
+	directory := t.TempDir()
+	test.CreateFiles(t, directory, map[string]string{"model.safetensors": "weights"})
+
+	// Execute
+	_, errNoConfig := FromLocalDirectory("org/model", directory)
+	_, errMissing := FromLocalDirectory("org/model", fileutil.PathJoin(directory, "missing"))
+	_, errFile := FromLocalDirectory("org/model", fileutil.PathJoin(directory, "model.safetensors"))
+
+	// Assert
+	test.AssertNotEqual(t, errNoConfig, nil)
+	test.AssertNotEqual(t, errMissing, nil)
+	test.AssertNotEqual(t, errFile, nil)
+}
+
+// TestIsWeightsFile tests the detection of the weights files.
+func TestIsWeightsFile(t *testing.T) {
+	test.AssertEqual(t, IsWeightsFile("model.safetensors"), true)
+	test.AssertEqual(t, IsWeightsFile("pytorch_model.BIN"), true)
+	test.AssertEqual(t, IsWeightsFile("vocab.json"), false)
+}
@@ -2,6 +2,7 @@ package model
 
 import (
 	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/easy-model-fusion/emf-cli/internal/auth"
 	"github.com/easy-model-fusion/emf-cli/internal/utils/dotenv"
 	"github.com/easy-model-fusion/emf-cli/internal/utils/fileutil"
 	"github.com/easy-model-fusion/emf-cli/internal/utils/stringutil"
@@ -22,7 +23,14 @@ type Model struct {
 	AddToBinaryFile bool
 	IsDownloaded    bool
 	Version         string
+	Revision        string
+	Pinned          bool
 	AccessToken     string
+	URL             string
+	Sha256          string
+	Include         []string
+	Exclude         []string
+	PreferFormat    string `yaml:"prefer-format" mapstructure:"prefer-format"`
 }
 
 type Tokenizers []Tokenizer
@@ -36,6 +44,7 @@ type Tokenizer struct {
 const (
 	HUGGING_FACE = "hugging_face"
 	CUSTOM       = "custom"
+	LOCAL        = "local"
 )
 
 // Empty checks if the models slice is empty.
@@ -159,6 +168,39 @@ func (m Models) FilterWithSourceHuggingface() Models {
 	return huggingfaceModels
 }
 
+// FilterWithSourceCustom return a sub-slice of models that do not source from huggingface.
+func (m Models) FilterWithSourceCustom() Models {
+	var customModels Models
+	for _, current := range m {
+		if current.Source == CUSTOM {
+			customModels = append(customModels, current)
+		}
+	}
+	return customModels
+}
+
+// FilterWithSourceLocal return a sub-slice of models imported from a local directory.
+func (m Models) FilterWithSourceLocal() Models {
+	var localModels Models
+	for _, current := range m {
+		if current.Source == LOCAL {
+			localModels = append(localModels, current)
+		}
+	}
+	return localModels
+}
+
+// FilterWithModule return a sub-slice of models using the requested module.
+func (m Models) FilterWithModule(module huggingface.Module) Models {
+	var moduleModels Models
+	for _, current := range m {
+		if current.Module == module {
+			moduleModels = append(moduleModels, current)
+		}
+	}
+	return moduleModels
+}
+
 // FilterWithIsDownloadedOrAddToBinaryFileTrue return a sub-slice of models with IsDownloaded or AddToBinaryFile  to true.
 func (m Models) FilterWithIsDownloadedOrAddToBinaryFileTrue() Models {
 	var downloadedModels Models
@@ -267,3 +309,18 @@ func (m *Model) SaveAccessToken(accessToken string) error {
 func (m *Model) GetAccessToken() (string, error) {
 	return dotenv.GetEnvValue(m.AccessToken)
 }
+
+// ResolveAccessToken returns the access token to use for the model :
+// the flag, then the token saved for the model, then the HF_TOKEN environment variable, then the stored one
+func (m *Model) ResolveAccessToken(flag string) (string, error) {
+	var modelToken string
+	if flag == "" && m.AccessToken != "" {
+		value, err := m.GetAccessToken()
+		if err != nil {
+			return "", err
+		}
+		modelToken = value
+	}
+	token, err := auth.ResolveToken(flag, modelToken)
+	return token.Value, err
+}
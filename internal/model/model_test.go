@@ -3,9 +3,11 @@ package model
 import (
 	"fmt"
 	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/easy-model-fusion/emf-cli/internal/auth"
 	"github.com/easy-model-fusion/emf-cli/internal/utils/fileutil"
 	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
 	"github.com/easy-model-fusion/emf-cli/test"
+	"path/filepath"
 	"testing"
 )
 
@@ -231,6 +233,36 @@ func TestFilterWithSourceHuggingface_Success(t *testing.T) {
 	test.AssertEqual(t, len(expected), len(result), "Lengths should be equal.")
 }
 
+// TestFilterWithSourceCustom_Success tests the Models.FilterWithSourceCustom to return the sub-slice.
+func TestFilterWithSourceCustom_Success(t *testing.T) {
+	// Init
+	models := GetModels(3)
+	models[1].Source = CUSTOM
+
+	// Execute
+	result := models.FilterWithSourceCustom()
+
+	// Assert
+	test.AssertEqual(t, len(result), 1)
+	test.AssertEqual(t, result[0].Name, models[1].Name)
+}
+
+// TestFilterWithModule_Success tests the Models.FilterWithModule to return the sub-slice.
+func TestFilterWithModule_Success(t *testing.T) {
+	// Init
+	models := GetModels(3)
+	models[0].Module = huggingface.DIFFUSERS
+	models[2].Module = huggingface.DIFFUSERS
+
+	// Execute
+	result := models.FilterWithModule(huggingface.DIFFUSERS)
+
+	// Assert
+	test.AssertEqual(t, len(result), 2)
+	test.AssertEqual(t, result[0].Name, models[0].Name)
+	test.AssertEqual(t, result[1].Name, models[2].Name)
+}
+
 // TestFilterWithIsDownloadedTrue_Success tests the Models.FilterWithIsDownloadedTrue to return the sub-slice.
 func TestFilterWithIsDownloadedTrue_Success(t *testing.T) {
 	// Init
@@ -391,3 +423,27 @@ func TestModelSaveAndGetAccessToken(t *testing.T) {
 	test.AssertEqual(t, err, nil)
 	test.AssertEqual(t, savedToken, "token")
 }
+
+// TestModel_ResolveAccessToken tests that the flag comes first, then the model token, then the default one.
+func TestModel_ResolveAccessToken(t *testing.T) {
+	// Init
+	model := GetModel(1)
+	model.Name = "1model/name1.6-test_escape"
+	ts := test.TestSuite{}
+	_ = ts.CreateFullTestSuite(t)
+	defer ts.CleanTestSuite(t)
+	t.Setenv(auth.CredentialsFileEnv, filepath.Join(t.TempDir(), "credentials.json"))
+	t.Setenv(auth.TokenEnv, "environment")
+
+	// Execute & Assert
+	token, err := model.ResolveAccessToken("")
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, token, "environment")
+
+	test.AssertEqual(t, model.SaveAccessToken("model"), nil)
+	token, _ = model.ResolveAccessToken("")
+	test.AssertEqual(t, token, "model")
+
+	token, _ = model.ResolveAccessToken("flag")
+	test.AssertEqual(t, token, "flag")
+}
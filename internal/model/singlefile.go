@@ -0,0 +1,215 @@
+package model
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/easy-model-fusion/emf-cli/internal/ui"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/fileutil"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/httputil"
+	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Classes used to load the single files whose class couldn't be given
+const (
+	singleFileStableDiffusionClass   = "StableDiffusionPipeline"
+	singleFileStableDiffusionXLClass = "StableDiffusionXLPipeline"
+	singleFileCausalLMClass          = "AutoModelForCausalLM"
+)
+
+// ggufMagic is the magic number starting every GGUF file
+var ggufMagic = []byte("GGUF")
+
+// safetensorsMaxHeaderSize prevents reading a whole file that isn't a safetensors file
+const safetensorsMaxHeaderSize = 100 * 1024 * 1024
+
+// SingleFileInfo represents what could be inferred from the header of a single file model
+type SingleFileInfo struct {
+	Module      huggingface.Module
+	Class       string
+	PipelineTag huggingface.PipelineTag
+}
+
+// InferSingleFile infers the module, class and pipeline tag of a single file model from its header
+// Supported formats are GGUF and safetensors : an empty SingleFileInfo is returned for any other format.
+func InferSingleFile(path string) (info SingleFileInfo, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return info, err
+	}
+	defer fileutil.CloseFile(file)
+
+	// GGUF files are only used by transformers to store language models
+	magic := make([]byte, len(ggufMagic))
+	if _, err = io.ReadFull(file, magic); err != nil {
+		// File too small to have a header
+		return info, nil
+	}
+	if bytes.Equal(magic, ggufMagic) {
+		return SingleFileInfo{
+			Module:      huggingface.TRANSFORMERS,
+			Class:       singleFileCausalLMClass,
+			PipelineTag: huggingface.TextGeneration,
+		}, nil
+	}
+
+	if strings.ToLower(filepath.Ext(path)) != ".safetensors" {
+		return info, nil
+	}
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return info, err
+	}
+	tensorNames, err := readSafetensorsTensorNames(file)
+	if err != nil {
+		return info, err
+	}
+	return inferFromTensorNames(tensorNames), nil
+}
+
+// readSafetensorsTensorNames returns the names of the tensors stored in a safetensors file
+// A safetensors file starts with the size of its JSON header as a little endian uint64.
+func readSafetensorsTensorNames(reader io.Reader) (names []string, err error) {
+	var headerSize uint64
+	if err = binary.Read(reader, binary.LittleEndian, &headerSize); err != nil {
+		return names, fmt.Errorf("invalid safetensors header : %s", err)
+	}
+	if headerSize > safetensorsMaxHeaderSize {
+		return names, fmt.Errorf("invalid safetensors header : size %d is too large", headerSize)
+	}
+
+	header := make([]byte, headerSize)
+	if _, err = io.ReadFull(reader, header); err != nil {
+		return names, fmt.Errorf("invalid safetensors header : %s", err)
+	}
+	var tensors map[string]json.RawMessage
+	if err = json.Unmarshal(header, &tensors); err != nil {
+		return names, fmt.Errorf("invalid safetensors header : %s", err)
+	}
+
+	for name := range tensors {
+		if name != "__metadata__" {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// inferFromTensorNames recognizes the original checkpoints of the most common architectures
+func inferFromTensorNames(names []string) (info SingleFileInfo) {
+	hasPrefix := func(prefix string) bool {
+		for _, name := range names {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case hasPrefix("conditioner.embedders.1."):
+		return SingleFileInfo{Module: huggingface.DIFFUSERS, Class: singleFileStableDiffusionXLClass, PipelineTag: huggingface.TextToImage}
+	case hasPrefix("model.diffusion_model."):
+		return SingleFileInfo{Module: huggingface.DIFFUSERS, Class: singleFileStableDiffusionClass, PipelineTag: huggingface.TextToImage}
+	case hasPrefix("lm_head."), hasPrefix("model.layers."), hasPrefix("transformer.h."):
+		return SingleFileInfo{Module: huggingface.TRANSFORMERS, Class: singleFileCausalLMClass, PipelineTag: huggingface.TextGeneration}
+	default:
+		return info
+	}
+}
+
+// IsGGUF returns true if the model is a GGUF single file, which transformers loads from its directory by name
+func (m *Model) IsGGUF() bool {
+	return m.Source == CUSTOM && strings.EqualFold(filepath.Ext(m.Path), ".gguf")
+}
+
+// IsURL returns true if the single file model is requested through an HTTP(S) URL
+func IsURL(name string) bool {
+	return strings.HasPrefix(name, "http://") || strings.HasPrefix(name, "https://")
+}
+
+// FromSingleFileURL builds a single file model downloaded from the given URL
+// The model is named after the host and the whole path of the file, so that files sharing their name don't collide,
+// and stored under the models directory following the same structure.
+func FromSingleFileURL(rawURL string) (model Model, err error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return model, fmt.Errorf("invalid url %s : %s", rawURL, err)
+	}
+	filePath := strings.TrimPrefix(path.Clean("/"+parsed.Path), "/")
+	if parsed.Host == "" || filePath == "" {
+		return model, fmt.Errorf("invalid url %s : no file to download", rawURL)
+	}
+
+	model.Name = parsed.Hostname() + "/" + strings.TrimSuffix(filePath, path.Ext(filePath))
+	model.Path = fileutil.PathJoin(app.DownloadDirectoryPath, parsed.Hostname(), filePath)
+	model.URL = rawURL
+	model.Source = CUSTOM
+	model.IsDownloaded = true
+	model.AddToBinaryFile = true
+	return model, nil
+}
+
+// DownloadSingleFile downloads the single file model from its URL and verifies its checksum when known
+// An interrupted download (i.e. cancelled context) is resumed, the file being only moved to the model path
+// once fully downloaded and verified.
+func (m *Model) DownloadSingleFile(ctx context.Context) (err error) {
+	if app.Offline() {
+		return app.NewOfflineError("downloading " + m.Name)
+	}
+
+	// Displaying the progress only when the size is known, and summarizing it when several models are downloaded
+	var progressBar ui.ProgressBar
+	summary := app.ConcurrentDownloads()
+	download := httputil.Download{
+		URL:    m.URL,
+		Path:   m.Path,
+		Sha256: m.Sha256,
+		Client: app.DownloadClient(),
+		Progress: func(total, downloaded int64) io.Writer {
+			if total <= 0 || summary {
+				return nil
+			}
+			progressBar = app.UI().StartProgressBar(fmt.Sprintf("Downloading %s", m.Name), int(total))
+			progressBar.Add(int(downloaded))
+			return progressWriter{progressBar}
+		},
+	}
+	sum, err := download.Run(ctx)
+	if progressBar != nil {
+		progressBar.Stop()
+	}
+	if ctx.Err() != nil {
+		return fmt.Errorf("download of %s cancelled manually, it will resume on the next attempt : %w", m.Name, ctx.Err())
+	} else if err != nil {
+		return err
+	}
+
+	if summary {
+		size, _ := fileutil.PathSize(m.Path)
+		printDownloadSummary(m.Name, 1, size)
+	}
+	m.Sha256 = sum
+	return nil
+}
+
+// progressWriter reports the written bytes to the progress bar, if any
+type progressWriter struct {
+	progressBar ui.ProgressBar
+}
+
+// Write reports the written bytes to the progress bar
+func (p progressWriter) Write(data []byte) (int, error) {
+	if p.progressBar != nil {
+		p.progressBar.Add(len(data))
+	}
+	return len(data), nil
+}
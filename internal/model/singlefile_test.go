@@ -0,0 +1,190 @@
+package model
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/fileutil"
+	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
+	"github.com/easy-model-fusion/emf-cli/test"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+// createSafetensorsFile creates a safetensors file with the given JSON header
+func createSafetensorsFile(t *testing.T, path string, header string) {
+	content := binary.LittleEndian.AppendUint64(nil, uint64(len(header)))
+	content = append(content, []byte(header)...)
+	err := os.WriteFile(path, content, os.ModePerm)
+	test.AssertEqual(t, err, nil)
+}
+
+// TestInferSingleFile_GGUF tests that a GGUF file is inferred as a transformers language model.
+func TestInferSingleFile_GGUF(t *testing.T) {
+	// Init
+	path := fileutil.PathJoin(t.TempDir(), "model.gguf")
+	err := os.WriteFile(path, []byte("GGUF\x03\x00\x00\x00"), os.ModePerm)
+	test.AssertEqual(t, err, nil)
+
+	// Execute
+	info, err := InferSingleFile(path)
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, info.Module, huggingface.TRANSFORMERS)
+	test.AssertEqual(t, info.PipelineTag, huggingface.TextGeneration)
+}
+
+// TestInferSingleFile_Safetensors tests that the architecture is recognized from the tensor names.
+func TestInferSingleFile_Safetensors(t *testing.T) {
+	// Init
+	directory := t.TempDir()
+	diffusersPath := fileutil.PathJoin(directory, "sd.safetensors")
+	createSafetensorsFile(t, diffusersPath, `{"__metadata__": {"format": "pt"}, "model.diffusion_model.input": {}}`)
+	xlPath := fileutil.PathJoin(directory, "sdxl.safetensors")
+	createSafetensorsFile(t, xlPath, `{"model.diffusion_model.input": {}, "conditioner.embedders.1.model": {}}`)
+	transformersPath := fileutil.PathJoin(directory, "llm.safetensors")
+	createSafetensorsFile(t, transformersPath, `{"lm_head.weight": {}}`)
+	unknownPath := fileutil.PathJoin(directory, "unknown.safetensors")
+	createSafetensorsFile(t, unknownPath, `{"weight": {}}`)
+
+	// Execute
+	diffusersInfo, errDiffusers := InferSingleFile(diffusersPath)
+	xlInfo, errXl := InferSingleFile(xlPath)
+	transformersInfo, errTransformers := InferSingleFile(transformersPath)
+	unknownInfo, errUnknown := InferSingleFile(unknownPath)
+
+	// Assert
+	test.AssertEqual(t, errDiffusers, nil)
+	test.AssertEqual(t, diffusersInfo.Module, huggingface.DIFFUSERS)
+	test.AssertEqual(t, diffusersInfo.Class, "StableDiffusionPipeline")
+	test.AssertEqual(t, diffusersInfo.PipelineTag, huggingface.TextToImage)
+	test.AssertEqual(t, errXl, nil)
+	test.AssertEqual(t, xlInfo.Class, "StableDiffusionXLPipeline")
+	test.AssertEqual(t, errTransformers, nil)
+	test.AssertEqual(t, transformersInfo.Module, huggingface.TRANSFORMERS)
+	test.AssertEqual(t, errUnknown, nil)
+	test.AssertEqual(t, unknownInfo, SingleFileInfo{})
+}
+
+// TestInferSingleFile_Invalid tests that an invalid safetensors header returns an error.
+func TestInferSingleFile_Invalid(t *testing.T) {
+	// Init
+	directory := t.TempDir()
+	invalidPath := fileutil.PathJoin(directory, "invalid.safetensors")
+	createSafetensorsFile(t, invalidPath, `not json`)
+	otherPath := fileutil.PathJoin(directory, "model.ckpt")
+	err := os.WriteFile(otherPath, []byte("content"), os.ModePerm)
+	test.AssertEqual(t, err, nil)
+
+	// Execute
+	_, errInvalid := InferSingleFile(invalidPath)
+	otherInfo, errOther := InferSingleFile(otherPath)
+	_, errMissing := InferSingleFile(fileutil.PathJoin(directory, "missing"))
+
+	// Assert
+	test.AssertNotEqual(t, errInvalid, nil)
+	test.AssertEqual(t, errOther, nil)
+	test.AssertEqual(t, otherInfo, SingleFileInfo{})
+	test.AssertNotEqual(t, errMissing, nil)
+}
+
+// TestFromSingleFileURL tests that a single file model is named after its URL.
+func TestFromSingleFileURL(t *testing.T) {
+	// Execute
+	result, err := FromSingleFileURL("https://artifacts.example.com/models/sd-v1-5.safetensors?token=1")
+	_, errInvalid := FromSingleFileURL("https://artifacts.example.com/")
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, result.Name, "artifacts.example.com/models/sd-v1-5")
+	test.AssertEqual(t, result.Path, fileutil.PathJoin(app.DownloadDirectoryPath, "artifacts.example.com", "models/sd-v1-5.safetensors"))
+	test.AssertEqual(t, result.Source, CUSTOM)
+	test.AssertEqual(t, result.IsDownloaded, true)
+	test.AssertNotEqual(t, errInvalid, nil)
+}
+
+// TestFromSingleFileURL_SameFileName tests that the files sharing their name under different paths don't collide.
+func TestFromSingleFileURL_SameFileName(t *testing.T) {
+	// Execute
+	first, errFirst := FromSingleFileURL("https://example.com/a/model.safetensors")
+	second, errSecond := FromSingleFileURL("https://example.com/b/../b/model.safetensors")
+
+	// Assert
+	test.AssertEqual(t, errFirst, nil)
+	test.AssertEqual(t, errSecond, nil)
+	test.AssertEqual(t, first.Name, "example.com/a/model")
+	test.AssertEqual(t, second.Name, "example.com/b/model")
+	test.AssertNotEqual(t, first.Path, second.Path)
+}
+
+// TestDownloadSingleFile tests the download and the checksum verification of a single file model.
+func TestDownloadSingleFile(t *testing.T) {
+	// Init
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/model.safetensors" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+	directory := t.TempDir()
+	m := Model{Name: "model", URL: server.URL + "/model.safetensors", Path: fileutil.PathJoin(directory, "model.safetensors")}
+
+	// Checksum mismatch : nothing is kept
+	m.Sha256 = "invalid"
+	err := m.DownloadSingleFile(context.Background())
+	test.AssertNotEqual(t, err, nil)
+	exists, _ := fileutil.IsExistingPath(m.Path)
+	test.AssertEqual(t, exists, false)
+	exists, _ = fileutil.IsExistingPath(m.Path + ".part")
+	test.AssertEqual(t, exists, false)
+
+	// Success : the checksum is recorded
+	m.Sha256 = ""
+	err = m.DownloadSingleFile(context.Background())
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, m.Sha256, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824")
+	content, err := os.ReadFile(m.Path)
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, string(content), "hello")
+
+	// Not found
+	m.URL = server.URL + "/missing.safetensors"
+	err = m.DownloadSingleFile(context.Background())
+	test.AssertNotEqual(t, err, nil)
+
+	// Interrupted : the cancellation is reported
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	m.URL = server.URL + "/model.safetensors"
+	m.Path = fileutil.PathJoin(directory, "interrupted.safetensors")
+	err = m.DownloadSingleFile(ctx)
+	test.AssertEqual(t, errors.Is(err, context.Canceled), true)
+}
+
+// TestTidyConfiguredModel_SingleFileURL tests that a missing single file model is fetched again from its URL.
+func TestTidyConfiguredModel_SingleFileURL(t *testing.T) {
+	// Init
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+	m := Model{Name: "model", URL: server.URL + "/model.safetensors", Path: fileutil.PathJoin(t.TempDir(), "model.safetensors"),
+		Source: CUSTOM, IsDownloaded: true, Sha256: "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"}
+
+	// Execute
+	warnings, success, clean, err := m.TidyConfiguredModel("")
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, len(warnings), 0)
+	test.AssertEqual(t, success, true)
+	test.AssertEqual(t, clean, false)
+	exists, _ := fileutil.IsExistingPath(m.Path)
+	test.AssertEqual(t, exists, true)
+}
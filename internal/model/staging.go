@@ -0,0 +1,209 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/fileutil"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/httputil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// previousSuffix is appended to the staged items to back up the version they replace
+const previousSuffix = ".previous"
+
+// incompleteSuffix is appended by the hugging face hub library (i.e. the downloader script) to the files being downloaded
+const incompleteSuffix = ".incomplete"
+
+// downloadTransaction downloads a model into the staging directory,
+// its previous version being only replaced once the download succeeded.
+type downloadTransaction struct {
+	stagingRoot string
+	targetRoot  string
+	stagingPath string
+}
+
+// swappedItem is a downloaded item that replaced its previous version
+type swappedItem struct {
+	staged   string
+	target   string
+	backup   string
+	previous bool
+	moved    bool
+}
+
+// newDownloadTransaction prepares the staging of the model downloaded into the directory
+func newDownloadTransaction(modelName, directoryPath string) downloadTransaction {
+	return downloadTransaction{
+		stagingRoot: fileutil.PathJoin(app.StagingDirectoryPath),
+		targetRoot:  fileutil.PathJoin(directoryPath),
+		stagingPath: fileutil.PathJoin(app.StagingDirectoryPath, modelName),
+	}
+}
+
+// begin discards the files left by a previous attempt, unless its download can be resumed
+func (t downloadTransaction) begin() error {
+	if t.resumable() {
+		return nil
+	}
+	return os.RemoveAll(t.stagingPath)
+}
+
+// resumable returns true if the staged files can be resumed by the next attempt :
+// the partial files described by a manifest (i.e. written by the hub downloader),
+// or the incomplete files left by the hugging face hub library (i.e. written by the downloader script).
+func (t downloadTransaction) resumable() bool {
+	partial, err := httputil.HasPartialDownloads(t.stagingPath, "")
+	if err == nil && partial {
+		return true
+	}
+	incomplete, err := hasIncompleteFiles(t.stagingPath)
+	return err == nil && incomplete
+}
+
+// hasIncompleteFiles returns true if the directory contains files being downloaded by the hugging face hub library
+func hasIncompleteFiles(directory string) (found bool, err error) {
+	err = filepath.WalkDir(directory, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() && strings.HasSuffix(path, incompleteSuffix) {
+			found = true
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return found, err
+}
+
+// relative returns the path relative to the root, or false if the path is outside the root
+func relative(root, path string) (string, bool) {
+	absoluteRoot, err := filepath.Abs(root)
+	if err != nil {
+		return "", false
+	}
+	absolutePath, err := filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(absoluteRoot, absolutePath)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return rel, true
+}
+
+// rebase returns where the staged path will be once the download committed
+// A path outside the staging directory is returned as is.
+func (t downloadTransaction) rebase(path string) string {
+	if path == "" {
+		return path
+	}
+	rel, ok := relative(t.stagingRoot, path)
+	if !ok {
+		return path
+	}
+	return fileutil.PathJoin(t.targetRoot, rel)
+}
+
+// commit replaces the previous version of the staged items (i.e. the model and tokenizer paths)
+// If an item can't be replaced, the items already replaced are restored to their previous version.
+func (t downloadTransaction) commit(stagedPaths ...string) (err error) {
+	var swapped []swappedItem
+	defer func() {
+		if err != nil {
+			err = errors.Join(err, t.rollback(swapped))
+		}
+	}()
+
+	for _, staged := range stagedPaths {
+		// Only the items downloaded into the staging directory are to be moved
+		if staged == "" {
+			continue
+		}
+		if _, ok := relative(t.stagingRoot, staged); !ok {
+			continue
+		}
+		if exists, err := fileutil.IsExistingPath(staged); err != nil {
+			return err
+		} else if !exists {
+			continue
+		}
+
+		item := swappedItem{staged: staged, target: t.rebase(staged), backup: fileutil.PathJoin(staged + previousSuffix)}
+		if err = os.RemoveAll(item.backup); err != nil {
+			return err
+		}
+
+		// Backing up the previous version
+		if item.previous, err = fileutil.IsExistingPath(item.target); err != nil {
+			return err
+		} else if item.previous {
+			if err = os.Rename(item.target, item.backup); err != nil {
+				return fmt.Errorf("unable to back up %s : %w", item.target, err)
+			}
+		}
+		swapped = append(swapped, item)
+
+		// Moving the downloaded version into place
+		if err = os.MkdirAll(filepath.Dir(item.target), 0750); err != nil {
+			return err
+		}
+		if err = os.Rename(staged, item.target); err != nil {
+			return fmt.Errorf("unable to move %s into place : %w", staged, err)
+		}
+		swapped[len(swapped)-1].moved = true
+	}
+
+	// Every item was replaced : the previous versions and the staging directory are no longer needed
+	for _, item := range swapped {
+		_ = os.RemoveAll(item.backup)
+	}
+	t.discard()
+	return nil
+}
+
+// rollback restores the previous version of the replaced items, from the last to the first
+// The downloaded versions are moved back to the staging directory.
+func (t downloadTransaction) rollback(swapped []swappedItem) error {
+	var errs []error
+	for i := len(swapped) - 1; i >= 0; i-- {
+		item := swapped[i]
+		if item.moved {
+			if err := os.Rename(item.target, item.staged); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		}
+		if !item.previous {
+			continue
+		}
+		if err := os.Rename(item.backup, item.target); err != nil {
+			errs = append(errs, fmt.Errorf("unable to restore %s : %w", item.target, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// discard removes the staging directory of the model, along with its parents once empty
+func (t downloadTransaction) discard() {
+	_ = os.RemoveAll(t.stagingPath)
+	for path := filepath.Dir(t.stagingPath); ; path = filepath.Dir(path) {
+		if _, ok := relative(t.stagingRoot, path); !ok {
+			return
+		}
+		if exists, err := fileutil.IsExistingPath(path); err != nil {
+			return
+		} else if !exists {
+			continue
+		}
+		if err := fileutil.DeleteDirectoryIfEmpty(path); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package model
+
+import (
+	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/easy-model-fusion/emf-cli/internal/downloader/model"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/fileutil"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/httputil"
+	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
+	"github.com/easy-model-fusion/emf-cli/test"
+	"github.com/easy-model-fusion/emf-cli/test/dmock"
+	"os"
+	"testing"
+)
+
+// newTestTransaction creates a transaction staging the model in a temporary directory
+func newTestTransaction(t *testing.T) downloadTransaction {
+	root := t.TempDir()
+	return downloadTransaction{
+		stagingRoot: fileutil.PathJoin(root, "staging"),
+		targetRoot:  fileutil.PathJoin(root, "models"),
+		stagingPath: fileutil.PathJoin(root, "staging", "provider", "name"),
+	}
+}
+
+// TestDownload_Staged tests that the downloaded model replaces its previous version once the download succeeded.
+func TestDownload_Staged(t *testing.T) {
+	// Init
+	modelName := "provider/staged"
+	stagedPath := fileutil.PathJoin(app.StagingDirectoryPath, modelName, "model")
+	targetPath := fileutil.PathJoin(app.DownloadDirectoryPath, modelName, "model")
+	test.CreateFiles(t, targetPath, map[string]string{"old": "old"})
+	defer os.RemoveAll(app.DownloadDirectoryPath)
+	defer os.RemoveAll(app.StagingDirectoryPath)
+	SetupDownloaderForSuccess(downloadermodel.Model{Path: stagedPath, Module: string(huggingface.TRANSFORMERS), Class: "class"})
+	app.Downloader().(*dmock.MockDownloader).OnExecute = func(downloadermodel.Args) {
+		test.CreateFiles(t, stagedPath, map[string]string{"new": "new"})
+	}
+	defer func() { app.Downloader().(*dmock.MockDownloader).OnExecute = nil }()
+	m := Model{Name: modelName}
+
+	// Execute
+	success, _, err := m.Download(downloadermodel.Args{ModelName: modelName, DirectoryPath: app.DownloadDirectoryPath})
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, success, true)
+	test.AssertEqual(t, m.Path, targetPath)
+	test.AssertEqual(t, app.Downloader().(*dmock.MockDownloader).Args.DirectoryPath, app.StagingDirectoryPath)
+	exists, _ := fileutil.IsExistingPath(fileutil.PathJoin(targetPath, "new"))
+	test.AssertEqual(t, exists, true)
+	exists, _ = fileutil.IsExistingPath(fileutil.PathJoin(targetPath, "old"))
+	test.AssertEqual(t, exists, false)
+	exists, _ = fileutil.IsExistingPath(app.StagingDirectoryPath)
+	test.AssertEqual(t, exists, false)
+}
+
+// TestDownload_StagedFailure tests that the previous version is kept when the download fails.
+func TestDownload_StagedFailure(t *testing.T) {
+	// Init
+	modelName := "provider/staged"
+	targetPath := fileutil.PathJoin(app.DownloadDirectoryPath, modelName, "model")
+	test.CreateFiles(t, targetPath, map[string]string{"old": "old"})
+	defer os.RemoveAll(app.DownloadDirectoryPath)
+	defer os.RemoveAll(app.StagingDirectoryPath)
+	SetupDownloaderForFailure()
+	m := Model{Name: modelName}
+
+	// Execute
+	success, _, err := m.Download(downloadermodel.Args{ModelName: modelName, DirectoryPath: app.DownloadDirectoryPath})
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, success, false)
+	exists, _ := fileutil.IsExistingPath(fileutil.PathJoin(targetPath, "old"))
+	test.AssertEqual(t, exists, true)
+}
+
+// TestDownloadTransactionCommit_Rollback tests that the items already replaced are restored when an item can't be replaced.
+func TestDownloadTransactionCommit_Rollback(t *testing.T) {
+	// Init
+	transaction := newTestTransaction(t)
+	stagedModel := fileutil.PathJoin(transaction.stagingPath, "model")
+	stagedTokenizer := fileutil.PathJoin(transaction.stagingRoot, "provider", "blocked", "tokenizer")
+	targetModel := transaction.rebase(stagedModel)
+	test.CreateFiles(t, stagedModel, map[string]string{"new": "new"})
+	test.CreateFiles(t, stagedTokenizer, map[string]string{"new": "new"})
+	test.CreateFiles(t, targetModel, map[string]string{"old": "old"})
+	// The tokenizer can't be moved since its parent is a file
+	test.CreateFiles(t, transaction.targetRoot, map[string]string{"provider/blocked": "file"})
+
+	// Execute
+	err := transaction.commit(stagedModel, stagedTokenizer)
+
+	// Assert
+	test.AssertNotEqual(t, err, nil)
+	exists, _ := fileutil.IsExistingPath(fileutil.PathJoin(targetModel, "old"))
+	test.AssertEqual(t, exists, true)
+	exists, _ = fileutil.IsExistingPath(fileutil.PathJoin(targetModel, "new"))
+	test.AssertEqual(t, exists, false)
+	exists, _ = fileutil.IsExistingPath(fileutil.PathJoin(stagedModel, "new"))
+	test.AssertEqual(t, exists, true)
+}
+
+// TestDownloadTransactionBegin tests that only the staged files that can be resumed are kept.
+func TestDownloadTransactionBegin(t *testing.T) {
+	// Init
+	transaction := newTestTransaction(t)
+	stale := fileutil.PathJoin(transaction.stagingPath, "stale")
+	test.CreateFiles(t, transaction.stagingPath, map[string]string{"stale": "stale"})
+
+	// Execute
+	resumable := transaction.resumable()
+	err := transaction.begin()
+
+	// Assert
+	test.AssertEqual(t, resumable, false)
+	test.AssertEqual(t, err, nil)
+	exists, _ := fileutil.IsExistingPath(stale)
+	test.AssertEqual(t, exists, false)
+
+	// Init
+	partial := fileutil.PathJoin(transaction.stagingPath, "weights"+httputil.PartSuffix)
+	test.CreateFiles(t, transaction.stagingPath, map[string]string{
+		"weights" + httputil.PartSuffix:     "partial",
+		"weights" + httputil.ManifestSuffix: `{"url": "https://huggingface.co"}`,
+	})
+
+	// Execute
+	resumable = transaction.resumable()
+	err = transaction.begin()
+
+	// Assert
+	test.AssertEqual(t, resumable, true)
+	test.AssertEqual(t, err, nil)
+	exists, _ = fileutil.IsExistingPath(partial)
+	test.AssertEqual(t, exists, true)
+}
+
+// TestDownloadTransactionBegin_Incomplete tests that the incomplete files left by the downloader script are kept.
+func TestDownloadTransactionBegin_Incomplete(t *testing.T) {
+	// Init
+	transaction := newTestTransaction(t)
+	incomplete := fileutil.PathJoin(transaction.stagingPath, ".cache", "huggingface", "download", "weights"+incompleteSuffix)
+	test.CreateFiles(t, transaction.stagingPath, map[string]string{".cache/huggingface/download/weights" + incompleteSuffix: "partial"})
+
+	// Execute
+	resumable := transaction.resumable()
+	err := transaction.begin()
+
+	// Assert
+	test.AssertEqual(t, resumable, true)
+	test.AssertEqual(t, err, nil)
+	exists, _ := fileutil.IsExistingPath(incomplete)
+	test.AssertEqual(t, exists, true)
+}
+
+// TestDownloadTransactionRebase tests that only the staged paths are moved to the download directory.
+func TestDownloadTransactionRebase(t *testing.T) {
+	// Init
+	transaction := newTestTransaction(t)
+
+	// Execute & Assert
+	test.AssertEqual(t, transaction.rebase(fileutil.PathJoin(transaction.stagingPath, "model")),
+		fileutil.PathJoin(transaction.targetRoot, "provider", "name", "model"))
+	test.AssertEqual(t, transaction.rebase("/elsewhere/model"), "/elsewhere/model")
+	test.AssertEqual(t, transaction.rebase(""), "")
+}
@@ -1,10 +1,14 @@
 package model
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/easy-model-fusion/emf-cli/internal/auth"
 	"github.com/easy-model-fusion/emf-cli/internal/downloader/model"
 	"github.com/easy-model-fusion/emf-cli/internal/utils/fileutil"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/httputil"
 	"github.com/easy-model-fusion/emf-cli/internal/utils/stringutil"
 	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
 	"os"
@@ -31,6 +35,11 @@ func (m *Model) DownloadedOnDevice(useBasePath bool) (bool, error) {
 		return false, nil
 	}
 
+	// Single file model : nothing more to check
+	if isFile, err := isFilePath(modelPath); err != nil || isFile {
+		return isFile, err
+	}
+
 	// Check if the model directory is empty
 	empty, err := fileutil.IsDirectoryEmpty(modelPath)
 	if err != nil {
@@ -41,8 +50,8 @@ func (m *Model) DownloadedOnDevice(useBasePath bool) (bool, error) {
 		return false, nil
 	}
 
-	// Model is downloaded on the device
-	return true, nil
+	// An interrupted download has yet to be resumed
+	return hasNoPartialDownloads(modelPath)
 }
 
 // DownloadedOnDevice returns true if the tokenizer is physically present on the device.
@@ -58,6 +67,11 @@ func (t *Tokenizer) DownloadedOnDevice() (bool, error) {
 		return false, nil
 	}
 
+	// Tokenizer stored within a single file model : nothing more to check
+	if isFile, err := isFilePath(t.Path); err != nil || isFile {
+		return isFile, err
+	}
+
 	// Check if the model directory is empty
 	empty, err := fileutil.IsDirectoryEmpty(t.Path)
 	if err != nil {
@@ -68,8 +82,23 @@ func (t *Tokenizer) DownloadedOnDevice() (bool, error) {
 		return false, nil
 	}
 
-	// Model is downloaded on the device
-	return true, nil
+	// An interrupted download has yet to be resumed
+	return hasNoPartialDownloads(t.Path)
+}
+
+// isFilePath returns true if the existing path is a file rather than a directory
+func isFilePath(path string) (bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	return !info.IsDir(), nil
+}
+
+// hasNoPartialDownloads returns true if no download was interrupted in the directory
+func hasNoPartialDownloads(path string) (bool, error) {
+	partial, err := httputil.HasPartialDownloads(path, "")
+	return !partial, err
 }
 
 // GetTokenizersNotDownloadedOnDevice returns the list of tokenizers that should but are not physically present on the device.
@@ -99,8 +128,34 @@ func (m *Model) GetTokenizersNotDownloadedOnDevice() Tokenizers {
 	return notDownloadedTokenizers
 }
 
+// SizeOnDevice returns the size in bytes taken by the model and its tokenizers on the device.
+func (m *Model) SizeOnDevice() (int64, error) {
+
+	// Models are stored under their base path by default
+	path := m.GetBasePath()
+	exists, err := fileutil.IsExistingPath(path)
+	if err != nil {
+		return 0, err
+	}
+
+	// Otherwise : the model may be stored elsewhere (i.e. single file)
+	if !exists {
+		if m.Path == "" {
+			return 0, nil
+		}
+		path = m.Path
+		exists, err = fileutil.IsExistingPath(path)
+		if err != nil || !exists {
+			return 0, err
+		}
+	}
+
+	return fileutil.PathSize(path)
+}
+
 // BuildModelsFromDevice builds a slice of models recovered from the device folders.
-func BuildModelsFromDevice(accessToken string) Models {
+// Once the context is cancelled, the modules are inferred from the files as when offline.
+func BuildModelsFromDevice(ctx context.Context, accessToken string) Models {
 
 	// Get all the providers in the root folder
 	providers, err := os.ReadDir(app.DownloadDirectoryPath)
@@ -108,6 +163,11 @@ func BuildModelsFromDevice(accessToken string) Models {
 		return Models{}
 	}
 
+	// Without the flag, the models are fetched with the default access token, if it can be read
+	if token, err := auth.ResolveToken(accessToken, ""); err == nil {
+		accessToken = token.Value
+	}
+
 	// Processing each provider
 	var models Models
 	for _, provider := range providers {
@@ -135,26 +195,29 @@ func BuildModelsFromDevice(accessToken string) Models {
 			modelName := fileutil.PathJoin(provider.Name(), providerModel.Name())
 			modelPath := fileutil.PathJoin(providerPath, providerModel.Name())
 
-			// Fetching model from huggingface
-			huggingfaceModel, err := app.H().GetModelById(modelName, accessToken)
-			if err != nil {
+			// Fetching model from huggingface, only its cached metadata being available when offline
+			var modelMapped Model
+			huggingfaceModel, err := app.H().GetModelById(ctx, modelName, accessToken)
+			if errors.Is(err, huggingface.ErrNotFound) {
 				// Model not found : custom
-				models = append(models, Model{
-					Name:            modelName,
-					Path:            modelPath,
-					Source:          CUSTOM,
-					AddToBinaryFile: true,
-					IsDownloaded:    true,
-				})
+				models = append(models, customModelFromDevice(modelName, modelPath))
 				continue
+			} else if err != nil {
+				// Model unreachable (i.e. offline, gated or rate limited) : its module is inferred from its files instead
+				modelMapped = buildModelFromDeviceFiles(modelName, modelPath)
+				if modelMapped.Source == CUSTOM {
+					models = append(models, modelMapped)
+					continue
+				}
+			} else {
+				// Fetching succeeded : processing the response
+				// Map API response to model.Model
+				modelMapped = FromHuggingfaceModel(huggingfaceModel)
 			}
 
-			// Fetching succeeded : processing the response
-			// Map API response to model.Model
-			modelMapped := FromHuggingfaceModel(huggingfaceModel)
-
-			// Leaving the version field as empty since it's impossible to trace the version back
+			// Leaving the version fields as empty since it's impossible to trace the version back
 			modelMapped.Version = ""
+			modelMapped.Revision = ""
 
 			// Get all the folders for the model
 			directories, err := os.ReadDir(modelPath)
@@ -202,6 +265,32 @@ func BuildModelsFromDevice(accessToken string) Models {
 	return models
 }
 
+// customModelFromDevice maps a downloaded model that isn't a huggingface model
+func customModelFromDevice(name, path string) Model {
+	return Model{
+		Name:            name,
+		Path:            path,
+		Source:          CUSTOM,
+		AddToBinaryFile: true,
+		IsDownloaded:    true,
+	}
+}
+
+// buildModelFromDeviceFiles maps a downloaded model without fetching it from huggingface
+// The diffusers models hold a model_index.json file, while the transformers models hold a model folder.
+// A model laid out otherwise is considered as custom.
+func buildModelFromDeviceFiles(name, path string) Model {
+	model := Model{Name: name, Source: HUGGING_FACE}
+	if exists, _ := fileutil.IsExistingPath(fileutil.PathJoin(path, "model_index.json")); exists {
+		model.Module = huggingface.DIFFUSERS
+	} else if exists, _ := fileutil.IsExistingPath(fileutil.PathJoin(path, "model")); exists {
+		model.Module = huggingface.TRANSFORMERS
+	} else {
+		return customModelFromDevice(name, path)
+	}
+	return model
+}
+
 // FromHuggingfaceModel map the Huggingface API huggingface.Model to a Model
 func FromHuggingfaceModel(huggingfaceModel huggingface.Model) Model {
 	var model Model
@@ -210,20 +299,39 @@ func FromHuggingfaceModel(huggingfaceModel huggingface.Model) Model {
 	model.Module = huggingfaceModel.LibraryName
 	model.Source = HUGGING_FACE
 	model.Version = huggingfaceModel.LastModified
+	model.Revision = huggingfaceModel.Sha
 	return model
 }
 
+// IsSameVersion returns true if both models point to the same commit sha
+// The versions are unknown when the commit sha of either model is missing, in which case known is false.
+func (m *Model) IsSameVersion(other Model) (same bool, known bool) {
+	if m.Revision == "" || other.Revision == "" {
+		return false, false
+	}
+	return m.Revision == other.Revision, true
+}
+
 // Update attempts to update the model
 func (m *Model) Update(yes bool, accessToken string) (warnings []string, success bool, err error) {
+	install, tokenizerNames, err := m.ConfirmUpdate(yes)
+	if err != nil || !install {
+		// Model will not be downloaded or overwritten, nothing more to do here
+		return warnings, success, err
+	}
+	return m.ExecuteUpdate(tokenizerNames, accessToken)
+}
+
+// ConfirmUpdate asks the user whether to update the model and which tokenizers to update
+func (m *Model) ConfirmUpdate(yes bool) (install bool, tokenizerNames []string, err error) {
 	// Check if model is physically present on the device
 	m.UpdatePaths()
 	downloaded, err := m.DownloadedOnDevice(false)
 	if err != nil {
-		return warnings, success, err
+		return install, tokenizerNames, err
 	}
 
 	// Process internal state of the model
-	install := false
 	if downloaded {
 		// Model already configured and downloaded : a new version is available
 		install = yes || app.UI().AskForUsersConfirmation(fmt.Sprintf("New version of '%s' is available. "+
@@ -235,14 +343,10 @@ func (m *Model) Update(yes bool, accessToken string) (warnings []string, success
 
 	// Model will not be downloaded or overwritten, nothing more to do here
 	if !install {
-		return warnings, success, err
+		return install, tokenizerNames, err
 	}
 
-	// Downloader script to skip the tokenizers download process if none selected
-	var skipTokenizer bool
-
 	// If transformers : select the tokenizers to update using a multiselect
-	var tokenizerNames []string
 	if m.Module == huggingface.TRANSFORMERS {
 
 		// Get tokenizer names for the model
@@ -255,18 +359,22 @@ func (m *Model) Update(yes bool, accessToken string) (warnings []string, success
 			message := "Please select the tokenizer(s) to be updated"
 			tokenizerNames = app.UI().DisplayInteractiveMultiselect(message, availableNames, app.UI().BasicCheckmark(), true, true, 8)
 			app.UI().DisplaySelectedItems(tokenizerNames)
-
-			// No tokenizer is selected : skipping so that it doesn't overwrite the default one
-			skipTokenizer = len(tokenizerNames) > 0
 		}
 	}
+
+	return install, tokenizerNames, err
+}
+
+// ExecuteUpdate downloads the new version of the model and of the selected tokenizers
+func (m *Model) ExecuteUpdate(tokenizerNames []string, accessToken string) (warnings []string, success bool, err error) {
+	// Downloader script to skip the tokenizers download process if some are selected : they are downloaded separately
+	skipTokenizer := len(tokenizerNames) > 0
+
 	// Prepare the script arguments
-	if accessToken == "" {
-		accessToken, err = m.GetAccessToken()
+	accessToken, err = m.ResolveAccessToken(accessToken)
+	if err != nil {
 		// Download failed
-		if err != nil {
-			return warnings, success, err
-		}
+		return warnings, success, err
 	}
 	downloaderArgs := downloadermodel.Args{
 		ModelName:         m.Name,
@@ -277,6 +385,10 @@ func (m *Model) Update(yes bool, accessToken string) (warnings []string, success
 		OnlyConfiguration: false,
 		DirectoryPath:     app.DownloadDirectoryPath,
 		AccessToken:       accessToken,
+		Revision:          m.Revision,
+		Include:           m.Include,
+		Exclude:           m.Exclude,
+		PreferFormat:      m.PreferFormat,
 	}
 
 	// Downloading model
@@ -337,13 +449,34 @@ func (m *Model) TidyConfiguredModel(accessToken string) (warnings []string, succ
 	if downloaded && len(missingTokenizers) == 0 {
 		return warnings, true, true, err
 	}
-	// Prepare the script arguments
-	if accessToken == "" {
-		accessToken, err = m.GetAccessToken()
-		if err != nil {
-			// Download failed
+
+	// Single file downloaded from a URL : fetching it again
+	if m.URL != "" {
+		app.UI().Info().Printfln("Downloading %s from %s", m.Name, m.URL)
+		ctx, stop := app.InterruptContext()
+		err = m.DownloadSingleFile(ctx)
+		stop()
+		var offlineErr *app.OfflineError
+		if errors.Is(err, context.Canceled) || errors.As(err, &offlineErr) {
 			return warnings, false, false, err
+		} else if err != nil {
+			warnings = append(warnings, err.Error())
+			return warnings, false, false, nil
 		}
+		return warnings, true, false, nil
+	}
+
+	// Imported models can't be downloaded : they must be imported again
+	if m.Source == LOCAL {
+		warnings = append(warnings, fmt.Sprintf("Model '%s' was imported from a local directory and must be imported again", m.Name))
+		return warnings, false, false, nil
+	}
+
+	// Prepare the script arguments
+	accessToken, err = m.ResolveAccessToken(accessToken)
+	if err != nil {
+		// Download failed
+		return warnings, false, false, err
 	}
 	downloaderArgs := downloadermodel.Args{
 		ModelName:         m.Name,
@@ -353,6 +486,10 @@ func (m *Model) TidyConfiguredModel(accessToken string) (warnings []string, succ
 		OnlyConfiguration: false,
 		DirectoryPath:     app.DownloadDirectoryPath,
 		AccessToken:       accessToken,
+		Revision:          m.Revision,
+		Include:           m.Include,
+		Exclude:           m.Exclude,
+		PreferFormat:      m.PreferFormat,
 	}
 
 	// Model has yet to be downloaded
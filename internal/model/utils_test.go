@@ -1,16 +1,20 @@
 package model
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/easy-model-fusion/emf-cli/internal/app"
 	"github.com/easy-model-fusion/emf-cli/internal/downloader/model"
 	"github.com/easy-model-fusion/emf-cli/internal/utils/fileutil"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/httputil"
 	"github.com/easy-model-fusion/emf-cli/pkg/huggingface"
 	"github.com/easy-model-fusion/emf-cli/test/dmock"
 	"github.com/easy-model-fusion/emf-cli/test/mock"
 	"github.com/pterm/pterm"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/easy-model-fusion/emf-cli/test"
@@ -79,6 +83,31 @@ func TestDownloadedOnDevice_True(t *testing.T) {
 	test.AssertEqual(t, exists, true)
 }
 
+// TestDownloadedOnDevice_FalsePartial tests the Model.DownloadedOnDevice function to return false upon interrupted download.
+func TestDownloadedOnDevice_FalsePartial(t *testing.T) {
+	// Create a temporary directory representing the model base path
+	modelDirectory := t.TempDir()
+	err := os.WriteFile(filepath.Join(modelDirectory, "config.json"), []byte("{}"), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(filepath.Join(modelDirectory, "model.safetensors"+httputil.ManifestSuffix), []byte(`{"url": "url"}`), os.ModePerm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Init
+	model := GetModel(0)
+	model.Path = modelDirectory
+
+	// Execute
+	exists, err := model.DownloadedOnDevice(false)
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, exists, false)
+}
+
 // TestModelDownloadedOnDevice_UseBasePath_True tests the ModelDownloadedOnDevice function to return true.
 func TestModelDownloadedOnDevice_UseBasePath_True(t *testing.T) {
 	// Create a temporary directory representing the model base path
@@ -103,6 +132,47 @@ func TestModelDownloadedOnDevice_UseBasePath_True(t *testing.T) {
 	test.AssertEqual(t, exists, true)
 }
 
+// TestSizeOnDevice_BasePath tests the Model.SizeOnDevice function to sum the files under the base path.
+func TestSizeOnDevice_BasePath(t *testing.T) {
+	// Create a temporary directory representing the model base path
+	modelName := fileutil.PathJoin("microsoft", "phi-2")
+	modelPath := fileutil.PathJoin(app.DownloadDirectoryPath, modelName, "model")
+	err := os.MkdirAll(modelPath, 0750)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(app.DownloadDirectoryPath)
+	err = os.WriteFile(fileutil.PathJoin(modelPath, "weights"), []byte("1234"), 0750)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Init
+	model := GetModel(0)
+	model.Name = modelName
+
+	// Execute
+	size, err := model.SizeOnDevice()
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, size, int64(4))
+}
+
+// TestSizeOnDevice_Missing tests the Model.SizeOnDevice function to return 0 when the model is missing.
+func TestSizeOnDevice_Missing(t *testing.T) {
+	// Init
+	model := GetModel(0)
+	model.Path = fileutil.PathJoin("missing", "model.safetensors")
+
+	// Execute
+	size, err := model.SizeOnDevice()
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, size, int64(0))
+}
+
 // TestTokenizer_DownloadedOnDevice_FalseMissing tests the Tokenizer.DownloadedOnDevice function to return false upon missing.
 func TestTokenizer_DownloadedOnDevice_FalseMissing(t *testing.T) {
 	// Init
@@ -251,8 +321,8 @@ func TestBuildModelsFromDevice_Custom(t *testing.T) {
 	defer os.RemoveAll(app.DownloadDirectoryPath)
 
 	// Execute
-	app.InitHuggingFace(huggingface.BaseUrl, "")
-	models := BuildModelsFromDevice("")
+	app.InitHuggingFace(huggingface.BaseUrl, "", huggingface.DefaultTimeouts, nil)
+	models := BuildModelsFromDevice(context.Background(), "")
 
 	// Assert
 	test.AssertEqual(t, len(models), 1)
@@ -274,8 +344,8 @@ func TestBuildModelsFromDevice_HuggingfaceEmpty(t *testing.T) {
 	defer os.RemoveAll(app.DownloadDirectoryPath)
 
 	// Execute
-	app.InitHuggingFace(huggingface.BaseUrl, "")
-	models := BuildModelsFromDevice("")
+	app.InitHuggingFace(huggingface.BaseUrl, "", huggingface.DefaultTimeouts, nil)
+	models := BuildModelsFromDevice(context.Background(), "")
 
 	// Assert
 	test.AssertEqual(t, len(models), 0)
@@ -293,8 +363,8 @@ func TestBuildModelsFromDevice_HuggingfaceDiffusers(t *testing.T) {
 	defer os.RemoveAll(app.DownloadDirectoryPath)
 
 	// Execute
-	app.InitHuggingFace(huggingface.BaseUrl, "")
-	models := BuildModelsFromDevice("")
+	app.InitHuggingFace(huggingface.BaseUrl, "", huggingface.DefaultTimeouts, nil)
+	models := BuildModelsFromDevice(context.Background(), "")
 
 	// Assert
 	test.AssertEqual(t, len(models), 1)
@@ -327,8 +397,8 @@ func TestBuildModelsFromDevice_HuggingfaceTransformers(t *testing.T) {
 	}
 
 	// Execute
-	app.InitHuggingFace(huggingface.BaseUrl, "")
-	models := BuildModelsFromDevice("")
+	app.InitHuggingFace(huggingface.BaseUrl, "", huggingface.DefaultTimeouts, nil)
+	models := BuildModelsFromDevice(context.Background(), "")
 
 	// Assert
 	test.AssertEqual(t, len(models), 1)
@@ -345,6 +415,68 @@ func TestBuildModelsFromDevice_HuggingfaceTransformers(t *testing.T) {
 
 }
 
+// TestBuildModelsFromDevice_Offline tests that the modules are inferred from the files when offline, the models not being cached.
+func TestBuildModelsFromDevice_Offline(t *testing.T) {
+	// Init
+	diffusersDirectory := fileutil.PathJoin(app.DownloadDirectoryPath, "stabilityai", "sdxl-turbo")
+	transformersDirectory := fileutil.PathJoin(app.DownloadDirectoryPath, "microsoft", "phi-2")
+	customDirectory := fileutil.PathJoin(app.DownloadDirectoryPath, "provider", "custom")
+	for _, directory := range []string{diffusersDirectory, fileutil.PathJoin(transformersDirectory, "model"),
+		fileutil.PathJoin(transformersDirectory, "tokenizer"), fileutil.PathJoin(customDirectory, "weights")} {
+		if err := os.MkdirAll(directory, 0750); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(fileutil.PathJoin(diffusersDirectory, "model_index.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(app.DownloadDirectoryPath)
+	app.SetHuggingFace(&huggingface.MockHuggingFace{Error: errors.New("should not be called")})
+	app.SetOffline(true)
+	defer app.SetOffline(false)
+
+	// Execute
+	models := BuildModelsFromDevice(context.Background(), "")
+
+	// Assert
+	mapModels := models.Map()
+	test.AssertEqual(t, len(models), 3)
+	test.AssertEqual(t, mapModels["stabilityai/sdxl-turbo"].Module, huggingface.DIFFUSERS)
+	test.AssertEqual(t, mapModels["stabilityai/sdxl-turbo"].Source, HUGGING_FACE)
+	test.AssertEqual(t, mapModels["stabilityai/sdxl-turbo"].Path, diffusersDirectory)
+	test.AssertEqual(t, mapModels["microsoft/phi-2"].Module, huggingface.TRANSFORMERS)
+	test.AssertEqual(t, mapModels["microsoft/phi-2"].Source, HUGGING_FACE)
+	test.AssertEqual(t, mapModels["microsoft/phi-2"].Path, fileutil.PathJoin(transformersDirectory, "model"))
+	test.AssertEqual(t, len(mapModels["microsoft/phi-2"].Tokenizers), 1)
+	test.AssertEqual(t, mapModels["provider/custom"].Source, CUSTOM)
+}
+
+// TestBuildModelsFromDevice_HubErrors tests that only the models missing from the hub are considered as custom.
+func TestBuildModelsFromDevice_HubErrors(t *testing.T) {
+	// Init
+	diffusersDirectory := fileutil.PathJoin(app.DownloadDirectoryPath, "stabilityai", "sdxl-turbo")
+	if err := os.MkdirAll(diffusersDirectory, 0750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fileutil.PathJoin(diffusersDirectory, "model_index.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(app.DownloadDirectoryPath)
+
+	// Execute
+	app.SetHuggingFace(&huggingface.MockHuggingFace{Error: &huggingface.APIError{Err: huggingface.ErrGated}})
+	gatedModels := BuildModelsFromDevice(context.Background(), "")
+	app.SetHuggingFace(&huggingface.MockHuggingFace{Error: &huggingface.APIError{Err: huggingface.ErrNotFound}})
+	notFoundModels := BuildModelsFromDevice(context.Background(), "")
+
+	// Assert
+	test.AssertEqual(t, len(gatedModels), 1)
+	test.AssertEqual(t, gatedModels[0].Source, HUGGING_FACE)
+	test.AssertEqual(t, gatedModels[0].Module, huggingface.DIFFUSERS)
+	test.AssertEqual(t, len(notFoundModels), 1)
+	test.AssertEqual(t, notFoundModels[0].Source, CUSTOM)
+}
+
 // TestFromHuggingfaceModel_Success tests the FromHuggingfaceModel to return the correct Model.
 func TestFromHuggingfaceModel_Success(t *testing.T) {
 	// Init
@@ -352,6 +484,7 @@ func TestFromHuggingfaceModel_Success(t *testing.T) {
 		Name:        "name",
 		PipelineTag: "pipeline",
 		LibraryName: "library",
+		Sha:         "sha",
 	}
 
 	// Execute
@@ -363,6 +496,36 @@ func TestFromHuggingfaceModel_Success(t *testing.T) {
 	test.AssertEqual(t, model.PipelineTag, huggingfaceModel.PipelineTag)
 	test.AssertEqual(t, model.Module, huggingfaceModel.LibraryName)
 	test.AssertEqual(t, model.Source, HUGGING_FACE)
+	test.AssertEqual(t, model.Revision, huggingfaceModel.Sha)
+}
+
+// Tests IsSameVersion comparing the commit sha when known by both models
+func TestIsSameVersion_Revision(t *testing.T) {
+	// Init
+	model := Model{Version: "2021", Revision: "sha1"}
+
+	// Execute
+	same, known := model.IsSameVersion(Model{Version: "2022", Revision: "sha1"})
+	different, knownDifferent := model.IsSameVersion(Model{Version: "2021", Revision: "sha2"})
+
+	// Assert
+	test.AssertEqual(t, same, true)
+	test.AssertEqual(t, known, true)
+	test.AssertEqual(t, different, false)
+	test.AssertEqual(t, knownDifferent, true)
+}
+
+// Tests IsSameVersion reporting an unknown version when the commit sha is missing, whatever the last modification date
+func TestIsSameVersion_Unknown(t *testing.T) {
+	// Init
+	model := Model{Version: "2021"}
+
+	// Execute
+	same, known := model.IsSameVersion(Model{Version: "2021", Revision: "sha1"})
+
+	// Assert
+	test.AssertEqual(t, same, false)
+	test.AssertEqual(t, known, false)
 }
 
 // Tests TidyConfiguredModel on clean model
@@ -421,6 +584,31 @@ func TestTidyConfiguredModel_Success(t *testing.T) {
 	test.AssertEqual(t, len(warnings), 0)
 }
 
+// Tests TidyConfiguredModel re-applies the download filters of the model
+func TestTidyConfiguredModel_Filters(t *testing.T) {
+	// Init
+	model := Model{
+		Name:         "Test",
+		Path:         "invalid/path",
+		Module:       huggingface.DIFFUSERS,
+		Include:      []string{"*.json", "unet/*"},
+		Exclude:      []string{"*.bin"},
+		PreferFormat: downloadermodel.FormatSafetensors,
+	}
+	downloader := dmock.MockDownloader{DownloaderModel: downloadermodel.Model{Path: "test"}}
+	app.SetDownloader(&downloader)
+
+	// Execute
+	_, success, _, err := model.TidyConfiguredModel("")
+
+	// Assert
+	test.AssertEqual(t, success, true)
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, strings.Join(downloader.Args.Include, ","), "*.json,unet/*")
+	test.AssertEqual(t, strings.Join(downloader.Args.Exclude, ","), "*.bin")
+	test.AssertEqual(t, downloader.Args.PreferFormat, downloadermodel.FormatSafetensors)
+}
+
 // Tests TidyConfiguredModel
 func TestTidyConfiguredModel_Fail(t *testing.T) {
 	// Init
@@ -0,0 +1,146 @@
+// Package plan describes the changes a command would make, so that they can be reviewed before being applied.
+package plan
+
+import (
+	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/fileutil"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/outpututil"
+	"os"
+	"strings"
+)
+
+// Actions applied to the configuration file entries
+const (
+	ConfigAdd    = "add"
+	ConfigUpdate = "update"
+)
+
+// Plan represents the changes a command would make
+type Plan struct {
+	Setup       []string      `json:"setup,omitempty" yaml:"setup,omitempty"`
+	Downloads   []Download    `json:"downloads" yaml:"downloads"`
+	Deletions   []Deletion    `json:"deletions" yaml:"deletions"`
+	Config      []ConfigEntry `json:"config" yaml:"config"`
+	CodeChanged bool          `json:"codeChanged" yaml:"codeChanged"`
+	Warnings    []string      `json:"warnings,omitempty" yaml:"warnings,omitempty"`
+}
+
+// Download represents a model, or some of its tokenizers, that would be downloaded
+// The estimated size is 0 when it couldn't be estimated.
+type Download struct {
+	Model         string   `json:"model" yaml:"model"`
+	Revision      string   `json:"revision,omitempty" yaml:"revision,omitempty"`
+	URL           string   `json:"url,omitempty" yaml:"url,omitempty"`
+	Tokenizers    []string `json:"tokenizers,omitempty" yaml:"tokenizers,omitempty"`
+	EstimatedSize int64    `json:"estimatedSize" yaml:"estimatedSize"`
+}
+
+// Deletion represents a model or a tokenizer that would be removed from the device
+type Deletion struct {
+	Model     string `json:"model" yaml:"model"`
+	Tokenizer string `json:"tokenizer,omitempty" yaml:"tokenizer,omitempty"`
+	Path      string `json:"path" yaml:"path"`
+}
+
+// ConfigEntry represents a model entry of the configuration file that would be added or updated
+type ConfigEntry struct {
+	Model      string   `json:"model" yaml:"model"`
+	Action     string   `json:"action" yaml:"action"`
+	Tokenizers []string `json:"tokenizers,omitempty" yaml:"tokenizers,omitempty"`
+}
+
+// New initializes an empty plan, whose lists are encoded as empty rather than null
+func New() Plan {
+	return Plan{Downloads: []Download{}, Deletions: []Deletion{}, Config: []ConfigEntry{}}
+}
+
+// Empty returns true if nothing would be changed
+func (p *Plan) Empty() bool {
+	return len(p.Setup) == 0 && len(p.Downloads) == 0 && len(p.Deletions) == 0 && len(p.Config) == 0 && !p.CodeChanged
+}
+
+// AddWarnings appends the warnings to the plan
+func (p *Plan) AddWarnings(warnings []string) {
+	p.Warnings = append(p.Warnings, warnings...)
+}
+
+// EstimatedSize returns the total size of the downloads that could be estimated
+func (p *Plan) EstimatedSize() (size int64) {
+	for _, download := range p.Downloads {
+		size += download.EstimatedSize
+	}
+	return size
+}
+
+// Display prints the plan using the requested output format
+func (p *Plan) Display(format outpututil.Format) error {
+	if format.IsStructured() {
+		return outpututil.Write(os.Stdout, format, p)
+	}
+
+	for _, warning := range p.Warnings {
+		app.UI().Warning().Println(warning)
+	}
+	if p.Empty() {
+		app.UI().Success().Println("Nothing to do.")
+		return nil
+	}
+
+	if len(p.Setup) > 0 {
+		app.UI().Info().Println("Project setup :")
+		for _, step := range p.Setup {
+			app.UI().Info().Println(" - " + step)
+		}
+	}
+	if len(p.Downloads) > 0 {
+		app.UI().Info().Printfln("Downloads (estimated %s) :", fileutil.ReadableSize(p.EstimatedSize()))
+		app.UI().DisplayTable(p.downloadsTable())
+	}
+	if len(p.Deletions) > 0 {
+		app.UI().Info().Println("Deletions :")
+		app.UI().DisplayTable(p.deletionsTable())
+	}
+	if len(p.Config) > 0 {
+		app.UI().Info().Println("Configuration file :")
+		app.UI().DisplayTable(p.configTable())
+	}
+	if p.CodeChanged {
+		app.UI().Info().Println("The generated python code would change.")
+	}
+	return nil
+}
+
+// downloadsTable builds the table of the downloads
+func (p *Plan) downloadsTable() [][]string {
+	data := [][]string{{"Model", "Revision", "Tokenizers", "Estimated size"}}
+	for _, download := range p.Downloads {
+		revision := download.Revision
+		if download.URL != "" {
+			revision = download.URL
+		}
+		size := "unknown"
+		if download.EstimatedSize > 0 {
+			size = fileutil.ReadableSize(download.EstimatedSize)
+		}
+		data = append(data, []string{download.Model, revision, strings.Join(download.Tokenizers, ", "), size})
+	}
+	return data
+}
+
+// deletionsTable builds the table of the deletions
+func (p *Plan) deletionsTable() [][]string {
+	data := [][]string{{"Model", "Tokenizer", "Path"}}
+	for _, deletion := range p.Deletions {
+		data = append(data, []string{deletion.Model, deletion.Tokenizer, deletion.Path})
+	}
+	return data
+}
+
+// configTable builds the table of the configuration file entries
+func (p *Plan) configTable() [][]string {
+	data := [][]string{{"Model", "Action", "Tokenizers"}}
+	for _, entry := range p.Config {
+		data = append(data, []string{entry.Model, entry.Action, strings.Join(entry.Tokenizers, ", ")})
+	}
+	return data
+}
@@ -0,0 +1,93 @@
+package plan
+
+import (
+	"bytes"
+	"github.com/easy-model-fusion/emf-cli/internal/app"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/outpututil"
+	"github.com/easy-model-fusion/emf-cli/test"
+	"github.com/easy-model-fusion/emf-cli/test/mock"
+	"strings"
+	"testing"
+)
+
+func init() {
+	app.SetUI(&mock.MockUI{})
+}
+
+// TestNew_Empty tests that a new plan doesn't change anything.
+func TestNew_Empty(t *testing.T) {
+	// Execute
+	p := New()
+	var buffer bytes.Buffer
+	err := outpututil.Write(&buffer, outpututil.JSON, p)
+
+	// Assert
+	test.AssertEqual(t, p.Empty(), true)
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, strings.Contains(buffer.String(), `"downloads": []`), true)
+}
+
+// TestEmpty_CodeChanged tests that a plan regenerating the code isn't empty.
+func TestEmpty_CodeChanged(t *testing.T) {
+	// Init
+	p := New()
+	p.CodeChanged = true
+
+	// Execute
+	empty := p.Empty()
+
+	// Assert
+	test.AssertEqual(t, empty, false)
+}
+
+// TestEstimatedSize tests that the estimated sizes of the downloads are summed.
+func TestEstimatedSize(t *testing.T) {
+	// Init
+	p := New()
+	p.Downloads = []Download{{Model: "model1", EstimatedSize: 10}, {Model: "model2"}, {Model: "model3", EstimatedSize: 5}}
+
+	// Execute
+	size := p.EstimatedSize()
+
+	// Assert
+	test.AssertEqual(t, size, int64(15))
+}
+
+// TestDownloadsTable tests that the unknown sizes and the urls are displayed.
+func TestDownloadsTable(t *testing.T) {
+	// Init
+	p := New()
+	p.Downloads = []Download{{Model: "model1", Revision: "abc", Tokenizers: []string{"t1", "t2"}, EstimatedSize: 2048},
+		{Model: "model2", URL: "https://host/file"}}
+
+	// Execute
+	data := p.downloadsTable()
+
+	// Assert
+	test.AssertEqual(t, len(data), 3)
+	test.AssertEqual(t, data[1][1], "abc")
+	test.AssertEqual(t, data[1][2], "t1, t2")
+	test.AssertEqual(t, data[1][3], "2.0 KiB")
+	test.AssertEqual(t, data[2][1], "https://host/file")
+	test.AssertEqual(t, data[2][3], "unknown")
+}
+
+// TestDisplay_Table tests that only the sections of a plan containing entries are displayed as tables.
+func TestDisplay_Table(t *testing.T) {
+	// Init
+	var tables [][][]string
+	app.SetUI(&mock.MockUI{Tables: &tables})
+	defer app.SetUI(&mock.MockUI{})
+	p := New()
+	p.Deletions = []Deletion{{Model: "model", Path: "models/model"}}
+	p.Config = []ConfigEntry{{Model: "model", Action: ConfigAdd}}
+
+	// Execute
+	err := p.Display(outpututil.Table)
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, len(tables), 2)
+	test.AssertEqual(t, tables[0][1][0], "model")
+	test.AssertEqual(t, tables[1][1][0], "model")
+}
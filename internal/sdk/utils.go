@@ -49,7 +49,7 @@ func setUpdateSuggestion(value bool) {
 // This is used to avoid spamming the user with update suggestions
 // The update suggestion is reset when the user updates the SDK
 func SendUpdateSuggestion() {
-	if !canSendUpdateSuggestion() {
+	if app.Offline() || !canSendUpdateSuggestion() {
 		return
 	}
 	tag, ok := checkForUpdates()
@@ -67,6 +67,10 @@ func SendUpdateSuggestion() {
 // Upgrade the SDK to the latest version
 // Make sure the config is loaded before calling this function
 func Upgrade() error {
+	if app.Offline() {
+		return app.NewOfflineError("upgrading the sdk")
+	}
+
 	tag, ok := checkForUpdates()
 	if !ok {
 		app.UI().Info().Println("SDK is already up to date")
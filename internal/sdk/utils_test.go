@@ -1,6 +1,7 @@
 package sdk
 
 import (
+	"errors"
 	"github.com/easy-model-fusion/emf-cli/internal/app"
 	"github.com/easy-model-fusion/emf-cli/internal/config"
 	"github.com/easy-model-fusion/emf-cli/test"
@@ -127,6 +128,29 @@ func TestSendUpdateSuggestion(t *testing.T) {
 	test.AssertEqual(t, viper.GetBool("update-suggested"), true, "Should not set update-suggested to true if there is a tag and update-suggested is true")
 }
 
+// TestSendUpdateSuggestion_Offline tests that no update is suggested when offline.
+func TestSendUpdateSuggestion_Offline(t *testing.T) {
+	ts := test.TestSuite{}
+	_ = ts.CreateFullTestSuite(t)
+	defer ts.CleanTestSuite(t)
+
+	err := config.GetViperConfig(config.FilePath)
+	if err != nil {
+		t.Error(err)
+		t.FailNow()
+	}
+	app.SetOffline(true)
+	defer app.SetOffline(false)
+
+	viper.Set("update-suggested", false)
+	viper.Set("sdk-tag", "v0.0.1")
+	SendUpdateSuggestion()
+	test.AssertEqual(t, viper.GetBool("update-suggested"), false, "Should not suggest an update when offline")
+
+	var offlineErr *app.OfflineError
+	test.AssertEqual(t, errors.As(Upgrade(), &offlineErr), true, "Should not upgrade when offline")
+}
+
 func TestUpgrade(t *testing.T) {
 	ts := test.TestSuite{}
 	_ = ts.CreateFullTestSuite(t)
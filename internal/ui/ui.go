@@ -11,6 +11,11 @@ type Spinner interface {
 	Fail(message ...interface{})
 }
 
+type ProgressBar interface {
+	Add(count int)
+	Stop()
+}
+
 type Printer interface {
 	Printfln(format string, a ...interface{})
 	Printf(format string, a ...interface{})
@@ -20,11 +25,14 @@ type Printer interface {
 
 type UI interface {
 	AskForUsersInput(message string) string
+	AskForUsersSecret(message string) string
 	DisplayInteractiveMultiselect(msg string, options []string, checkMark Checkmark, optionsDefaultAll, filter bool, maxHeight int) []string
 	DisplayInteractiveSelect(msg string, options []string, filter bool, maxHeight int) string
 	DisplaySelectedItems(items []string)
+	DisplayTable(data [][]string)
 	AskForUsersConfirmation(message string) bool
 	StartSpinner(message string) Spinner
+	StartProgressBar(title string, total int) ProgressBar
 
 	// Printers
 	Info() Printer
@@ -16,6 +16,10 @@ type ptermPrinter struct {
 
 type ptermDefaultBoxPrinter struct{}
 
+type ptermProgressBar struct {
+	printer *pterm.ProgressbarPrinter
+}
+
 // NewPTermUI creates a new ptermUI instance
 func NewPTermUI() UI {
 	return &ptermUI{
@@ -40,6 +44,14 @@ func (p ptermUI) AskForUsersInput(message string) string {
 	return result
 }
 
+// AskForUsersSecret asks the user for a secret input, masked while typed, and returns it
+func (p ptermUI) AskForUsersSecret(message string) string {
+	textInput := pterm.DefaultInteractiveTextInput.WithMultiLine(false).WithMask("*")
+	result, _ := textInput.Show(message)
+	pterm.Println()
+	return result
+}
+
 // DisplayInteractiveMultiselect displays an interactive multiselect prompt to the user.
 // It presents a message and a list of options, allowing the user to select multiple options.
 // Returns the selected options.
@@ -91,6 +103,11 @@ func (p ptermUI) DisplaySelectedItems(items []string) {
 	p.Info().Printfln("Selected options: %s", p.Green(items))
 }
 
+// DisplayTable prints the given data as a table, the first row being used as the header.
+func (p ptermUI) DisplayTable(data [][]string) {
+	_ = pterm.DefaultTable.WithHasHeader().WithData(data).Render()
+}
+
 // AskForUsersConfirmation asks the user for a confirmation, returns true if the user confirms, false otherwise
 func (p ptermUI) AskForUsersConfirmation(message string) bool {
 	confirmation, _ := pterm.DefaultInteractiveConfirm.Show(message)
@@ -104,6 +121,22 @@ func (p ptermUI) StartSpinner(message string) Spinner {
 	return spinner
 }
 
+// StartProgressBar starts a new progress bar with the given title and total and returns a ProgressBar interface
+func (p ptermUI) StartProgressBar(title string, total int) ProgressBar {
+	printer, _ := pterm.DefaultProgressbar.WithTotal(total).WithTitle(title).WithRemoveWhenDone(true).Start()
+	return &ptermProgressBar{printer: printer}
+}
+
+// Add increments the progress bar by the given count
+func (p ptermProgressBar) Add(count int) {
+	p.printer.Add(count)
+}
+
+// Stop stops the progress bar
+func (p ptermProgressBar) Stop() {
+	_, _ = p.printer.Stop()
+}
+
 // Info returns a Printer interface for printing info messages
 func (p ptermUI) Info() Printer {
 	return &p.infoPrinter
@@ -87,6 +87,11 @@ func TestPtermUI_DisplaySelectedItems(t *testing.T) {
 	ui.DisplaySelectedItems([]string{"test1", "test2"})
 }
 
+func TestPtermUI_DisplayTable(t *testing.T) {
+	ui := NewPTermUI()
+	ui.DisplayTable([][]string{{"header1", "header2"}, {"test1", "test2"}})
+}
+
 func TestPtermUI_StartSpinner(t *testing.T) {
 	ui := NewPTermUI()
 	spinner := ui.StartSpinner("test")
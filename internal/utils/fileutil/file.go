@@ -1,10 +1,13 @@
 package fileutil
 
 import (
+	"crypto/sha256"
 	"embed"
+	"encoding/hex"
 	"fmt"
 	"github.com/spf13/cobra"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -136,3 +139,129 @@ func PathUniformize(path string) string {
 	// Replace backslashes with forward slashes
 	return filepath.ToSlash(path)
 }
+
+// PathSize returns the total size in bytes of the requested file or directory
+func PathSize(path string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(path, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	return size, err
+}
+
+// ReadableSize returns the size in bytes as a human-readable string
+func ReadableSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// PathFilesSize returns the size in bytes of every file under the requested path, indexed by their relative path
+func PathFilesSize(path string) (map[string]int64, error) {
+	sizes := make(map[string]int64)
+	err := filepath.WalkDir(path, func(current string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		relative, err := filepath.Rel(path, current)
+		if err != nil {
+			return err
+		}
+		if relative == "." {
+			// The requested path is a file
+			relative = entry.Name()
+		}
+		sizes[filepath.ToSlash(relative)] = info.Size()
+		return nil
+	})
+	return sizes, err
+}
+
+// FileSha256 returns the hexadecimal sha256 checksum of the requested file
+func FileSha256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer CloseFile(file)
+
+	hash := sha256.New()
+	if _, err = io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// CopyFile copies the source file to the destination, creating the missing parent directories
+// When hardlink is true, the file is linked instead, falling back to a copy if linking isn't possible
+func CopyFile(source, destination string, hardlink bool) error {
+	if err := os.MkdirAll(filepath.Dir(destination), os.ModePerm); err != nil {
+		return err
+	}
+
+	// Linking fails across devices or on some file systems : copying instead
+	if hardlink && os.Link(source, destination) == nil {
+		return nil
+	}
+
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer CloseFile(in)
+
+	out, err := os.Create(destination)
+	if err != nil {
+		return err
+	}
+	defer CloseFile(out)
+
+	_, err = io.Copy(out, in)
+	return err
+}
+
+// CopyDirectory copies every file of the source directory to the destination directory
+// Only the files for which keep returns true are copied, keep being called with the path relative to the source
+func CopyDirectory(source, destination string, hardlink bool, keep func(relativePath string) bool) error {
+	return filepath.WalkDir(source, func(current string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		relative, err := filepath.Rel(source, current)
+		if err != nil {
+			return err
+		}
+		if keep != nil && !keep(filepath.ToSlash(relative)) {
+			return nil
+		}
+		return CopyFile(current, filepath.Join(destination, relative), hardlink)
+	})
+}
@@ -1,6 +1,7 @@
 package fileutil
 
 import (
+	"fmt"
 	"github.com/easy-model-fusion/emf-cli/sdk"
 	"github.com/easy-model-fusion/emf-cli/test"
 	"github.com/spf13/cobra"
@@ -354,3 +355,126 @@ func TestPathJoin_Success(t *testing.T) {
 		test.AssertEqual(t, result, item.expected)
 	}
 }
+
+func TestPathSize(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "emf-cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// Create files in nested directories
+	err = os.MkdirAll(PathJoin(tmpDir, "nested"), os.ModePerm)
+	test.AssertEqual(t, err, nil)
+	err = os.WriteFile(PathJoin(tmpDir, "file1"), []byte("12345"), os.ModePerm)
+	test.AssertEqual(t, err, nil)
+	err = os.WriteFile(PathJoin(tmpDir, "nested", "file2"), []byte("123"), os.ModePerm)
+	test.AssertEqual(t, err, nil)
+
+	// Directory
+	size, err := PathSize(tmpDir)
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, size, int64(8))
+
+	// Single file
+	size, err = PathSize(PathJoin(tmpDir, "file1"))
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, size, int64(5))
+
+	// Missing path
+	_, err = PathSize(PathJoin(tmpDir, "missing"))
+	test.AssertNotEqual(t, err, nil)
+}
+
+func TestReadableSize(t *testing.T) {
+	test.AssertEqual(t, ReadableSize(512), "512 B")
+	test.AssertEqual(t, ReadableSize(1536), "1.5 KiB")
+	test.AssertEqual(t, ReadableSize(5*1024*1024*1024), "5.0 GiB")
+}
+
+func TestPathFilesSize(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "emf-cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// Create files in nested directories
+	err = os.MkdirAll(PathJoin(tmpDir, "nested"), os.ModePerm)
+	test.AssertEqual(t, err, nil)
+	err = os.WriteFile(PathJoin(tmpDir, "file1"), []byte("12345"), os.ModePerm)
+	test.AssertEqual(t, err, nil)
+	err = os.WriteFile(PathJoin(tmpDir, "nested", "file2"), []byte("123"), os.ModePerm)
+	test.AssertEqual(t, err, nil)
+
+	// Execute
+	sizes, err := PathFilesSize(tmpDir)
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, len(sizes), 2)
+	test.AssertEqual(t, sizes["file1"], int64(5))
+	test.AssertEqual(t, sizes["nested/file2"], int64(3))
+}
+
+func TestFileSha256(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "emf-cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// Create file
+	path := PathJoin(tmpDir, "file")
+	err = os.WriteFile(path, []byte("hello"), os.ModePerm)
+	test.AssertEqual(t, err, nil)
+
+	// Existing file
+	sum, err := FileSha256(path)
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, sum, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824")
+
+	// Missing file
+	_, err = FileSha256(PathJoin(tmpDir, "missing"))
+	test.AssertNotEqual(t, err, nil)
+
+	// Sizes of a single file
+	sizes, err := PathFilesSize(path)
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, sizes["file"], int64(5))
+}
+
+// TestCopyDirectory tests the copy and the hardlink of a directory
+func TestCopyDirectory(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "emf-cli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// Create source files
+	source := PathJoin(tmpDir, "source")
+	err = os.MkdirAll(PathJoin(source, "sub"), os.ModePerm)
+	test.AssertEqual(t, err, nil)
+	err = os.WriteFile(PathJoin(source, "sub", "kept"), []byte("hello"), os.ModePerm)
+	test.AssertEqual(t, err, nil)
+	err = os.WriteFile(PathJoin(source, "skipped"), []byte("hello"), os.ModePerm)
+	test.AssertEqual(t, err, nil)
+	keep := func(relativePath string) bool { return relativePath != "skipped" }
+
+	for _, hardlink := range []bool{false, true} {
+		destination := PathJoin(tmpDir, fmt.Sprint("destination", hardlink))
+
+		// Execute
+		err = CopyDirectory(source, destination, hardlink, keep)
+
+		// Assert
+		test.AssertEqual(t, err, nil)
+		content, err := os.ReadFile(PathJoin(destination, "sub", "kept"))
+		test.AssertEqual(t, err, nil)
+		test.AssertEqual(t, string(content), "hello")
+		exists, err := IsExistingPath(PathJoin(destination, "skipped"))
+		test.AssertEqual(t, err, nil)
+		test.AssertEqual(t, exists, false)
+	}
+}
@@ -0,0 +1,255 @@
+package httputil
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/fileutil"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Suffixes of the files kept next to the destination while it is being downloaded
+const (
+	PartSuffix     = ".part"
+	ManifestSuffix = ".part.json"
+)
+
+// PartManifest describes the partial file of a download, so that it can be resumed
+type PartManifest struct {
+	URL    string `json:"url"`
+	Size   int64  `json:"size"`
+	ETag   string `json:"etag,omitempty"`
+	Sha256 string `json:"sha256,omitempty"`
+}
+
+// Download represents a file to download over HTTP
+type Download struct {
+	URL    string
+	Path   string
+	Sha256 string
+	Header http.Header
+	Client *http.Client
+	// Progress is called once the size of the file is known, with the size already downloaded by previous attempts.
+	// The returned writer, if any, receives the remaining bytes.
+	Progress func(total, downloaded int64) io.Writer
+}
+
+// Run downloads the file into a partial file, resuming the previous attempt when possible
+// The partial file is only moved to the requested path once its size and checksum are verified.
+// It is kept when the download is interrupted, so that the next run continues where this one stopped.
+// The sha256 checksum of the downloaded file is returned.
+func (d Download) Run(ctx context.Context) (sum string, err error) {
+	partPath := d.Path + PartSuffix
+	manifestPath := d.Path + ManifestSuffix
+	if err = os.MkdirAll(filepath.Dir(d.Path), os.ModePerm); err != nil {
+		return sum, err
+	}
+
+	// Resume only the partial file of the same download
+	manifest, offset := d.readPart()
+	if offset == 0 {
+		manifest = PartManifest{URL: d.URL, Sha256: d.Sha256}
+	}
+
+	response, err := d.get(ctx, offset, manifest.ETag)
+	if err != nil {
+		return sum, err
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	switch {
+	case response.StatusCode == http.StatusPartialContent && contentRangeStart(response) == offset:
+		// Resuming
+	case response.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0 && offset == manifest.Size:
+		// The previous attempt was interrupted right before the verification
+		return d.complete(partPath, manifestPath, offset, manifest.Size, nil)
+	case response.StatusCode == http.StatusOK:
+		// The server doesn't support resuming or the file changed : starting over
+		offset = 0
+		manifest = PartManifest{URL: d.URL, Sha256: d.Sha256}
+	default:
+		return sum, fmt.Errorf("error downloading %s : %s", d.URL, response.Status)
+	}
+
+	// Record the download before receiving any data
+	manifest.ETag = response.Header.Get("ETag")
+	manifest.Size = -1
+	if response.ContentLength >= 0 {
+		manifest.Size = offset + response.ContentLength
+	}
+	if err = writeManifest(manifestPath, manifest); err != nil {
+		return sum, err
+	}
+
+	// The checksum covers the data received by the previous attempts
+	hasher := sha256.New()
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if offset > 0 {
+		if err = hashFile(partPath, hasher); err != nil {
+			return sum, err
+		}
+		flags = os.O_WRONLY | os.O_APPEND
+	}
+	file, err := os.OpenFile(partPath, flags, 0644)
+	if err != nil {
+		return sum, err
+	}
+
+	writers := []io.Writer{file, hasher}
+	if d.Progress != nil {
+		if progress := d.Progress(manifest.Size, offset); progress != nil {
+			writers = append(writers, progress)
+		}
+	}
+	written, err := io.Copy(io.MultiWriter(writers...), response.Body)
+	fileutil.CloseFile(file)
+	if err != nil {
+		return sum, fmt.Errorf("error downloading %s : %s", d.URL, err)
+	}
+
+	return d.complete(partPath, manifestPath, offset+written, manifest.Size, hasher)
+}
+
+// complete verifies the partial file and moves it to the requested path
+// The hasher is nil when the partial file still has to be hashed.
+func (d Download) complete(partPath, manifestPath string, size, expectedSize int64, hasher hash.Hash) (sum string, err error) {
+	if expectedSize >= 0 && size != expectedSize {
+		return sum, fmt.Errorf("error downloading %s : received %d bytes out of %d", d.URL, size, expectedSize)
+	}
+
+	if hasher == nil {
+		hasher = sha256.New()
+		if err = hashFile(partPath, hasher); err != nil {
+			return sum, err
+		}
+	}
+	sum = hex.EncodeToString(hasher.Sum(nil))
+	if d.Sha256 != "" && !strings.EqualFold(sum, d.Sha256) {
+		// Corrupted : nothing can be resumed
+		_ = os.Remove(partPath)
+		_ = os.Remove(manifestPath)
+		return sum, fmt.Errorf("checksum mismatch for %s : expected %s but got %s", d.URL, d.Sha256, sum)
+	}
+
+	if err = os.Rename(partPath, d.Path); err != nil {
+		return sum, err
+	}
+	_ = os.Remove(manifestPath)
+	return sum, nil
+}
+
+// readPart returns the manifest of the previous attempt and the size of its partial file
+// The size is 0 when there is nothing to resume.
+func (d Download) readPart() (manifest PartManifest, size int64) {
+	content, err := os.ReadFile(d.Path + ManifestSuffix)
+	if err != nil || json.Unmarshal(content, &manifest) != nil {
+		return manifest, 0
+	}
+	if manifest.URL != d.URL || (d.Sha256 != "" && manifest.Sha256 != "" && !strings.EqualFold(manifest.Sha256, d.Sha256)) {
+		return manifest, 0
+	}
+	info, err := os.Stat(d.Path + PartSuffix)
+	if err != nil {
+		return manifest, 0
+	}
+	return manifest, info.Size()
+}
+
+// get requests the file, starting at the given offset
+func (d Download) get(ctx context.Context, offset int64, etag string) (*http.Response, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, d.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range d.Header {
+		for _, value := range values {
+			request.Header.Add(key, value)
+		}
+	}
+	if offset > 0 {
+		request.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		if etag != "" {
+			request.Header.Set("If-Range", etag)
+		}
+	}
+
+	client := d.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("error downloading %s : %s", d.URL, err)
+	}
+	return response, nil
+}
+
+// contentRangeStart returns the first byte of a partial response, -1 if unknown
+func contentRangeStart(response *http.Response) int64 {
+	// Content-Range: bytes <start>-<end>/<size>
+	contentRange := strings.TrimPrefix(response.Header.Get("Content-Range"), "bytes ")
+	start, _, found := strings.Cut(contentRange, "-")
+	if !found {
+		return -1
+	}
+	value, err := strconv.ParseInt(start, 10, 64)
+	if err != nil {
+		return -1
+	}
+	return value
+}
+
+// writeManifest stores the manifest of a partial file
+func writeManifest(path string, manifest PartManifest) error {
+	content, err := json.Marshal(manifest)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0644)
+}
+
+// hashFile writes the content of the file to the hasher
+func hashFile(path string, hasher hash.Hash) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer fileutil.CloseFile(file)
+	_, err = io.Copy(hasher, file)
+	return err
+}
+
+// HasPartialDownloads returns true if the directory contains partial files whose url starts with the prefix
+func HasPartialDownloads(directory string, urlPrefix string) (found bool, err error) {
+	err = filepath.WalkDir(directory, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !strings.HasSuffix(path, ManifestSuffix) {
+			return nil
+		}
+		var manifest PartManifest
+		content, err := os.ReadFile(path)
+		if err != nil || json.Unmarshal(content, &manifest) != nil {
+			return nil
+		}
+		if strings.HasPrefix(manifest.URL, urlPrefix) {
+			found = true
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return found, err
+}
@@ -0,0 +1,148 @@
+package httputil
+
+import (
+	"bytes"
+	"context"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/fileutil"
+	"github.com/easy-model-fusion/emf-cli/test"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+const downloadContent = "hello world"
+const downloadSha256 = "b94d27b9934d3e08a52e52d7da7dabfac484efe37a5380ee9088f7ace2efcde9"
+
+// newDownloadServer serves the content with Range support, recording the last requested range
+func newDownloadServer(t *testing.T, requestedRange *string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requestedRange = r.Header.Get("Range")
+		http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader([]byte(downloadContent)))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// TestDownloadRun_Success tests that the file is moved into place once downloaded and verified.
+func TestDownloadRun_Success(t *testing.T) {
+	// Init
+	var requestedRange string
+	server := newDownloadServer(t, &requestedRange)
+	path := fileutil.PathJoin(t.TempDir(), "directory", "file")
+	download := Download{URL: server.URL, Path: path, Sha256: downloadSha256}
+
+	// Execute
+	sum, err := download.Run(context.Background())
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, sum, downloadSha256)
+	test.AssertEqual(t, requestedRange, "")
+	content, err := os.ReadFile(path)
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, string(content), downloadContent)
+	exists, _ := fileutil.IsExistingPath(path + PartSuffix)
+	test.AssertEqual(t, exists, false)
+	exists, _ = fileutil.IsExistingPath(path + ManifestSuffix)
+	test.AssertEqual(t, exists, false)
+}
+
+// TestDownloadRun_Resume tests that a partial file is completed through a Range request.
+func TestDownloadRun_Resume(t *testing.T) {
+	// Init
+	var requestedRange string
+	server := newDownloadServer(t, &requestedRange)
+	path := fileutil.PathJoin(t.TempDir(), "file")
+	_ = os.WriteFile(path+PartSuffix, []byte(downloadContent[:5]), 0644)
+	_ = writeManifest(path+ManifestSuffix, PartManifest{URL: server.URL, Size: int64(len(downloadContent))})
+	download := Download{URL: server.URL, Path: path}
+
+	// Execute
+	sum, err := download.Run(context.Background())
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, requestedRange, "bytes=5-")
+	test.AssertEqual(t, sum, downloadSha256)
+	content, err := os.ReadFile(path)
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, string(content), downloadContent)
+}
+
+// TestDownloadRun_OtherUrl tests that the partial file of another download is not resumed.
+func TestDownloadRun_OtherUrl(t *testing.T) {
+	// Init
+	var requestedRange string
+	server := newDownloadServer(t, &requestedRange)
+	path := fileutil.PathJoin(t.TempDir(), "file")
+	_ = os.WriteFile(path+PartSuffix, []byte("other"), 0644)
+	_ = writeManifest(path+ManifestSuffix, PartManifest{URL: server.URL + "/other", Size: 100})
+	download := Download{URL: server.URL, Path: path}
+
+	// Execute
+	sum, err := download.Run(context.Background())
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, requestedRange, "")
+	test.AssertEqual(t, sum, downloadSha256)
+}
+
+// TestDownloadRun_Interrupted tests that the partial file and its manifest are kept when the download is interrupted.
+func TestDownloadRun_Interrupted(t *testing.T) {
+	// Init
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "11")
+		_, _ = w.Write([]byte(downloadContent[:5]))
+	}))
+	defer server.Close()
+	path := fileutil.PathJoin(t.TempDir(), "file")
+	download := Download{URL: server.URL, Path: path}
+
+	// Execute
+	_, err := download.Run(context.Background())
+
+	// Assert
+	test.AssertNotEqual(t, err, nil)
+	exists, _ := fileutil.IsExistingPath(path)
+	test.AssertEqual(t, exists, false)
+	manifest, size := download.readPart()
+	test.AssertEqual(t, size, int64(5))
+	test.AssertEqual(t, manifest.Size, int64(11))
+	partial, err := HasPartialDownloads(fileutil.PathJoin(path, ".."), server.URL)
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, partial, true)
+}
+
+// TestDownloadRun_ChecksumMismatch tests that nothing is kept when the checksum doesn't match.
+func TestDownloadRun_ChecksumMismatch(t *testing.T) {
+	// Init
+	var requestedRange string
+	server := newDownloadServer(t, &requestedRange)
+	path := fileutil.PathJoin(t.TempDir(), "file")
+	download := Download{URL: server.URL, Path: path, Sha256: "invalid"}
+
+	// Execute
+	_, err := download.Run(context.Background())
+
+	// Assert
+	test.AssertNotEqual(t, err, nil)
+	exists, _ := fileutil.IsExistingPath(path)
+	test.AssertEqual(t, exists, false)
+	exists, _ = fileutil.IsExistingPath(path + PartSuffix)
+	test.AssertEqual(t, exists, false)
+	exists, _ = fileutil.IsExistingPath(path + ManifestSuffix)
+	test.AssertEqual(t, exists, false)
+}
+
+// TestHasPartialDownloads_Missing tests that a missing directory has no partial downloads.
+func TestHasPartialDownloads_Missing(t *testing.T) {
+	// Execute
+	partial, err := HasPartialDownloads(fileutil.PathJoin(t.TempDir(), "missing"), "")
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, partial, false)
+}
@@ -0,0 +1,65 @@
+package outpututil
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/stringutil"
+	"gopkg.in/yaml.v3"
+	"io"
+)
+
+// Format represents the output format of a command result
+type Format string
+
+const (
+	Table Format = "table"
+	JSON  Format = "json"
+	YAML  Format = "yaml"
+)
+
+var AllFormats = []Format{
+	Table,
+	JSON,
+	YAML,
+}
+
+// AllFormatsString returns all formats as a string slice
+func AllFormatsString() []string {
+	var formats []string
+	for _, format := range AllFormats {
+		formats = append(formats, string(format))
+	}
+	return formats
+}
+
+// ParseFormat returns the format matching the given value
+func ParseFormat(value string) (Format, error) {
+	if !stringutil.SliceContainsItem(AllFormatsString(), value) {
+		return "", fmt.Errorf("invalid output format '%s', expected one of %s", value, stringutil.SliceToArgsFormat(AllFormatsString()))
+	}
+	return Format(value), nil
+}
+
+// IsStructured returns true if the format is meant to be parsed by other programs
+func (f Format) IsStructured() bool {
+	return f == JSON || f == YAML
+}
+
+// Write encodes the value into the writer using a structured format
+func Write(w io.Writer, format Format, value interface{}) error {
+	switch format {
+	case JSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	case YAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(value); err != nil {
+			return err
+		}
+		return encoder.Close()
+	default:
+		return fmt.Errorf("format '%s' is not a structured format", format)
+	}
+}
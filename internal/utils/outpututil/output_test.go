@@ -0,0 +1,65 @@
+package outpututil
+
+import (
+	"bytes"
+	"github.com/easy-model-fusion/emf-cli/test"
+	"testing"
+)
+
+// TestAllFormatsString tests the AllFormatsString function to return every format.
+func TestAllFormatsString(t *testing.T) {
+	formats := AllFormatsString()
+
+	test.AssertEqual(t, len(formats), len(AllFormats))
+	for i, format := range formats {
+		test.AssertEqual(t, format, string(AllFormats[i]))
+	}
+}
+
+// TestParseFormat_Success tests the ParseFormat function with a valid format.
+func TestParseFormat_Success(t *testing.T) {
+	format, err := ParseFormat("json")
+
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, format, JSON)
+	test.AssertEqual(t, format.IsStructured(), true)
+}
+
+// TestParseFormat_Fail tests the ParseFormat function with an invalid format.
+func TestParseFormat_Fail(t *testing.T) {
+	_, err := ParseFormat("xml")
+
+	test.AssertNotEqual(t, err, nil)
+}
+
+// TestWrite_JSON tests the Write function to encode as json.
+func TestWrite_JSON(t *testing.T) {
+	var buffer bytes.Buffer
+	value := map[string]string{"name": "model"}
+
+	err := Write(&buffer, JSON, value)
+
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, buffer.String(), "{\n  \"name\": \"model\"\n}\n")
+}
+
+// TestWrite_YAML tests the Write function to encode as yaml.
+func TestWrite_YAML(t *testing.T) {
+	var buffer bytes.Buffer
+	value := map[string]string{"name": "model"}
+
+	err := Write(&buffer, YAML, value)
+
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, buffer.String(), "name: model\n")
+}
+
+// TestWrite_Table tests the Write function to fail with a non-structured format.
+func TestWrite_Table(t *testing.T) {
+	var buffer bytes.Buffer
+
+	err := Write(&buffer, Table, "value")
+
+	test.AssertNotEqual(t, err, nil)
+	test.AssertEqual(t, Table.IsStructured(), false)
+}
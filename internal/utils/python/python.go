@@ -1,15 +1,19 @@
 package python
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"fmt"
 	"github.com/easy-model-fusion/emf-cli/internal/ui"
 	"github.com/easy-model-fusion/emf-cli/internal/utils/executil"
 	"github.com/easy-model-fusion/emf-cli/internal/utils/fileutil"
+	"github.com/easy-model-fusion/emf-cli/internal/utils/stringutil"
 	"github.com/pterm/pterm"
 	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
 	"strings"
 )
@@ -22,6 +26,7 @@ type Python interface {
 	InstallDependencies(pipPath, path string) error
 	ExecutePip(pipPath string, args []string) error
 	ExecuteScript(venvPath, filePath string, args []string, ctx context.Context) ([]byte, error, int)
+	ExecuteScriptStream(venvPath, filePath string, args []string, env []string, ctx context.Context, onLine func(line []byte)) (error, int)
 	CheckAskForPython(ui ui.UI) (string, bool)
 }
 
@@ -112,25 +117,12 @@ func (p *python) runCommand(cmd *exec.Cmd) error {
 // ExecuteScript runs the requested python file with the requested arguments
 func (p *python) ExecuteScript(venvPath, filePath string, args []string, ctx context.Context) ([]byte, error, int) {
 
-	// Find the python executable inside the venv to run the script
-	pythonPath, err := p.FindVEnvExecutable(venvPath, "python")
-	if err != nil {
-		return nil, fmt.Errorf("error using the venv : %s", err), 1
-	}
-
-	// Checking that the script does exist
-	exists, err := fileutil.IsExistingPath(filePath)
+	// Create command
+	cmd, err := p.scriptCommand(venvPath, filePath, args, ctx)
 	if err != nil {
-		pterm.Error.Println(fmt.Sprintf("Missing script '%s'", filePath))
-		return nil, err, 1
-	} else if !exists {
-		err = fmt.Errorf("missing script '%s'", filePath)
 		return nil, err, 1
 	}
 
-	// Create command
-	var cmd = exec.CommandContext(ctx, pythonPath, append([]string{filePath}, args...)...)
-
 	// Create pipe to capture stdout
 	stdoutPipe, err := cmd.StdoutPipe()
 	if err != nil {
@@ -168,6 +160,113 @@ func (p *python) ExecuteScript(venvPath, filePath string, args []string, ctx con
 	return nil, err, 1
 }
 
+// ExecuteScriptStream runs the requested python file with the requested arguments, delivering its output line by line
+// The error output is kept to describe the failure of the script instead of being displayed.
+// A line is only valid during the call to onLine.
+// The environment variables (KEY=value) are added to the environment of the current process.
+func (p *python) ExecuteScriptStream(venvPath, filePath string, args []string, env []string, ctx context.Context, onLine func(line []byte)) (error, int) {
+
+	// Create command
+	cmd, err := p.scriptCommand(venvPath, filePath, args, ctx)
+	if err != nil {
+		return err, 1
+	}
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+
+	// Create pipe to capture stdout
+	stdoutPipe, err := cmd.StdoutPipe()
+	if err != nil {
+		return err, 1
+	}
+
+	// Keep the end of stderr
+	stderr := &tailBuffer{limit: stderrTailSize}
+	cmd.Stderr = stderr
+
+	// Start the command
+	err = cmd.Start()
+	if err != nil {
+		return err, 1
+	}
+
+	// Deliver the output as soon as a line is complete
+	scanner := bufio.NewScanner(stdoutPipe)
+	scanner.Buffer(make([]byte, 64*1024), maxScriptLineSize)
+	for scanner.Scan() {
+		if line := bytes.TrimSpace(scanner.Bytes()); len(line) > 0 {
+			onLine(line)
+		}
+	}
+	scanErr := scanner.Err()
+	if scanErr != nil {
+		// Unblock the script before waiting for it
+		_, _ = io.Copy(io.Discard, stdoutPipe)
+	}
+
+	// Wait for the command to finish
+	err = cmd.Wait()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			err = fmt.Errorf("%s : %s", err, stringutil.RedactSecrets(message))
+		}
+		return err, 1
+	}
+	if scanErr != nil {
+		return scanErr, 1
+	}
+
+	return nil, 0
+}
+
+// scriptCommand creates the command running the requested python file inside the virtual environment
+func (p *python) scriptCommand(venvPath, filePath string, args []string, ctx context.Context) (*exec.Cmd, error) {
+
+	// Find the python executable inside the venv to run the script
+	pythonPath, err := p.FindVEnvExecutable(venvPath, "python")
+	if err != nil {
+		return nil, fmt.Errorf("error using the venv : %s", err)
+	}
+
+	// Checking that the script does exist
+	exists, err := fileutil.IsExistingPath(filePath)
+	if err != nil {
+		pterm.Error.Println(fmt.Sprintf("Missing script '%s'", filePath))
+		return nil, err
+	} else if !exists {
+		return nil, fmt.Errorf("missing script '%s'", filePath)
+	}
+
+	return exec.CommandContext(ctx, pythonPath, append([]string{filePath}, args...)...), nil
+}
+
+// Limits of the output kept when streaming a script
+const (
+	maxScriptLineSize = 16 * 1024 * 1024
+	stderrTailSize    = 4 * 1024
+)
+
+// tailBuffer keeps the last bytes written to it
+type tailBuffer struct {
+	limit int
+	data  []byte
+}
+
+// Write appends the data, dropping the oldest bytes beyond the limit
+func (b *tailBuffer) Write(data []byte) (int, error) {
+	b.data = append(b.data, data...)
+	if len(b.data) > b.limit {
+		b.data = b.data[len(b.data)-b.limit:]
+	}
+	return len(data), nil
+}
+
+// String returns the bytes kept
+func (b *tailBuffer) String() string {
+	return string(b.data)
+}
+
 // CheckAskForPython checks if python is available in the PATH
 // If python is not available, a message is printed to the user and asks to specify the path to python
 // Returns true if python is available and the PATH
@@ -210,3 +309,29 @@ func (p *python) askSpecificPythonPath(ui ui.UI) (string, bool) {
 
 	return "", false
 }
+
+// GetInstalledPackages returns the versions of the packages installed within a virtual environment, indexed by name
+// The packages are read from the metadata folders of the site-packages, so that python doesn't need to be run
+func GetInstalledPackages(venvPath string) (map[string]string, error) {
+	patterns := []string{
+		filepath.Join(venvPath, "lib", "python*", "site-packages", "*.dist-info"),
+		filepath.Join(venvPath, "Lib", "site-packages", "*.dist-info"),
+	}
+
+	packages := make(map[string]string)
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		for _, match := range matches {
+			// Metadata folders are named <name>-<version>.dist-info
+			name, version, found := strings.Cut(strings.TrimSuffix(filepath.Base(match), ".dist-info"), "-")
+			if !found {
+				continue
+			}
+			packages[strings.ToLower(name)] = version
+		}
+	}
+	return packages, nil
+}
@@ -8,7 +8,9 @@ import (
 	"github.com/easy-model-fusion/emf-cli/test/mock"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 )
 
@@ -255,6 +257,37 @@ func TestExecuteScript(t *testing.T) {
 	}
 }
 
+// TestExecuteScriptStream tests that the output of the script is delivered line by line and that failures describe the error output.
+func TestExecuteScriptStream(t *testing.T) {
+	// Init
+	dname, venvPath := CreateVenv(t)
+	defer os.RemoveAll(dname)
+	scriptPath := fileutil.PathJoin(dname, "script.py")
+	err := os.WriteFile(scriptPath, []byte("import sys\nprint('first', flush=True)\nprint('')\nprint('second')\nsys.stderr.write('failure')\nsys.exit(int(sys.argv[1]))"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Execute
+	var lines []string
+	err, exitCode := NewPython().ExecuteScriptStream(venvPath, scriptPath, []string{"0"}, nil, context.Background(), func(line []byte) {
+		lines = append(lines, string(line))
+	})
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, exitCode, 0)
+	test.AssertEqual(t, strings.Join(lines, ","), "first,second")
+
+	// Execute with failure
+	err, exitCode = NewPython().ExecuteScriptStream(venvPath, scriptPath, []string{"1"}, nil, context.Background(), func(_ []byte) {})
+
+	// Assert
+	test.AssertNotEqual(t, err, nil)
+	test.AssertEqual(t, strings.HasSuffix(err.Error(), "failure"), true)
+	test.AssertEqual(t, exitCode, 1)
+}
+
 // TestCheckAskForPython_Success tests the CheckAskForPython function when Python is installed.
 func TestCheckAskForPython_Success(t *testing.T) {
 	// check python
@@ -342,3 +375,27 @@ func TestPython_askSpecificPythonPath_fail(t *testing.T) {
 	test.AssertEqual(t, ok, false)
 	test.AssertEqual(t, path, "")
 }
+
+func TestGetInstalledPackages(t *testing.T) {
+	// Init
+	venvPath := t.TempDir()
+	sitePackages := filepath.Join(venvPath, "lib", "python3.11", "site-packages")
+	for _, folder := range []string{"torch-2.2.0.dist-info", "Jinja2-3.1.3.dist-info", "torch", "invalid.dist-info"} {
+		err := os.MkdirAll(filepath.Join(sitePackages, folder), os.ModePerm)
+		test.AssertEqual(t, err, nil)
+	}
+
+	// Execute
+	packages, err := GetInstalledPackages(venvPath)
+
+	// Assert
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, len(packages), 2)
+	test.AssertEqual(t, packages["torch"], "2.2.0")
+	test.AssertEqual(t, packages["jinja2"], "3.1.3")
+
+	// Missing virtual environment
+	packages, err = GetInstalledPackages(filepath.Join(venvPath, "missing"))
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, len(packages), 0)
+}
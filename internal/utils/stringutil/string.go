@@ -91,3 +91,68 @@ func OptionsMapToSlice(optionsMap map[string]string) []string {
 	}
 	return optionsSlice
 }
+
+// MaskSecret hides most of a secret value, only keeping a few characters at both ends for identification
+func MaskSecret(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	if len(secret) <= 8 {
+		return strings.Repeat("*", len(secret))
+	}
+	return secret[:3] + strings.Repeat("*", len(secret)-7) + secret[len(secret)-4:]
+}
+
+// redactedValue replaces the secrets hidden by RedactArgs
+const redactedValue = "***"
+
+// sensitiveArgNames are the parts of a flag name marking its value as a secret (i.e. --access-token, --api-key)
+var sensitiveArgNames = []string{"token", "password", "secret", "key"}
+
+// huggingfaceTokenPattern matches the hugging face access tokens, wherever they appear in an argument
+var huggingfaceTokenPattern = regexp.MustCompile(`hf_[A-Za-z0-9]{20,}`)
+
+// RedactArgs returns a copy of the command line arguments to display, their secrets being hidden :
+// the values of the flags named like a secret (--access-token value or --access-token=value) and the hugging face tokens
+func RedactArgs(args []string) []string {
+	redacted := make([]string, len(args))
+	secretNext := false
+	for index, arg := range args {
+		// Value of the previous flag
+		if secretNext && !strings.HasPrefix(arg, "-") {
+			redacted[index] = redactedValue
+			secretNext = false
+			continue
+		}
+		secretNext = false
+
+		name, _, hasValue := strings.Cut(arg, "=")
+		if strings.HasPrefix(name, "-") && isSensitiveArgName(name) {
+			if hasValue {
+				redacted[index] = name + "=" + redactedValue
+			} else {
+				redacted[index] = arg
+				secretNext = true
+			}
+			continue
+		}
+		redacted[index] = RedactSecrets(arg)
+	}
+	return redacted
+}
+
+// RedactSecrets hides the hugging face access tokens found in the text (i.e. the output of a script)
+func RedactSecrets(text string) string {
+	return huggingfaceTokenPattern.ReplaceAllString(text, redactedValue)
+}
+
+// isSensitiveArgName returns true if the flag holds a secret according to its name
+func isSensitiveArgName(name string) bool {
+	name = strings.ToLower(name)
+	for _, sensitive := range sensitiveArgNames {
+		if strings.Contains(name, sensitive) {
+			return true
+		}
+	}
+	return false
+}
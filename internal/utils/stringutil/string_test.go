@@ -144,3 +144,29 @@ func TestPathRemoveSpecialCharacter(t *testing.T) {
 
 	test.AssertEqual(t, updatedPath, expectedPath)
 }
+
+func TestMaskSecret(t *testing.T) {
+	test.AssertEqual(t, MaskSecret(""), "")
+	test.AssertEqual(t, MaskSecret("short"), "*****")
+	test.AssertEqual(t, MaskSecret("hf_abcdefghijklmnop"), "hf_************mnop")
+}
+
+// TestRedactArgs tests that the secrets are hidden, whatever the way they are passed.
+func TestRedactArgs(t *testing.T) {
+	// Init
+	args := []string{"--name=app", "--access-token", "value", "--api-key=value", "--onefile",
+		"--python-flag=-o hf_abcdefghijklmnopqrstuvwx", "--token", "--other", "main.py"}
+
+	// Execute
+	redacted := RedactArgs(args)
+
+	// Assert
+	expected := []string{"--name=app", "--access-token", "***", "--api-key=***", "--onefile",
+		"--python-flag=-o ***", "--token", "--other", "main.py"}
+	test.AssertEqual(t, len(redacted), len(expected))
+	for index := range expected {
+		test.AssertEqual(t, redacted[index], expected[index])
+	}
+	test.AssertEqual(t, args[2], "value", "The arguments shouldn't be modified.")
+	test.AssertEqual(t, RedactSecrets("invalid token hf_abcdefghijklmnopqrstuvwx"), "invalid token ***")
+}
@@ -0,0 +1,54 @@
+package workerutil
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// Run runs the task for each of the count items, with at most jobs tasks running at the same time
+// Once the process is interrupted, no new task is started : the indexes of the items that were skipped are returned.
+// The tasks already running are expected to handle the interruption themselves.
+func Run(jobs int, count int, task func(index int)) (skipped []int) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return run(ctx, jobs, count, task)
+}
+
+// run runs the tasks until the context is cancelled
+func run(ctx context.Context, jobs int, count int, task func(index int)) (skipped []int) {
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	// Workers processing the items as soon as they are available
+	indexes := make(chan int)
+	var waitGroup sync.WaitGroup
+	for worker := 0; worker < min(jobs, count); worker++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for index := range indexes {
+				task(index)
+			}
+		}()
+	}
+
+	for index := 0; index < count; index++ {
+		if ctx.Err() != nil {
+			skipped = append(skipped, index)
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			skipped = append(skipped, index)
+		case indexes <- index:
+		}
+	}
+	close(indexes)
+	waitGroup.Wait()
+
+	return skipped
+}
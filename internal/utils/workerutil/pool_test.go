@@ -0,0 +1,62 @@
+package workerutil
+
+import (
+	"context"
+	"github.com/easy-model-fusion/emf-cli/test"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestRun_Bounded tests that every task is run without exceeding the number of jobs.
+func TestRun_Bounded(t *testing.T) {
+	// Init
+	var running, maxRunning atomic.Int32
+	var mutex sync.Mutex
+	processed := make(map[int]bool)
+
+	// Execute
+	skipped := Run(3, 10, func(index int) {
+		current := running.Add(1)
+		for {
+			previous := maxRunning.Load()
+			if current <= previous || maxRunning.CompareAndSwap(previous, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		running.Add(-1)
+
+		mutex.Lock()
+		processed[index] = true
+		mutex.Unlock()
+	})
+
+	// Assert
+	test.AssertEqual(t, len(skipped), 0)
+	test.AssertEqual(t, len(processed), 10)
+	test.AssertEqual(t, maxRunning.Load() <= 3, true)
+	test.AssertEqual(t, maxRunning.Load() > 1, true)
+}
+
+// TestRun_Interrupted tests that no task is started once the process is interrupted.
+func TestRun_Interrupted(t *testing.T) {
+	// Init
+	var processed atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Execute
+	skipped := run(ctx, 1, 5, func(index int) {
+		processed.Add(1)
+		if index == 0 {
+			cancel()
+			time.Sleep(10 * time.Millisecond)
+		}
+	})
+
+	// Assert
+	test.AssertEqual(t, processed.Load(), int32(1))
+	test.AssertEqual(t, len(skipped), 4)
+}
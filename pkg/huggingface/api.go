@@ -1,85 +1,303 @@
 package huggingface
 
 import (
-	"fmt"
+	"context"
+	"encoding/json"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
+	"strings"
+	"time"
 )
 
-const BaseUrl = "https://huggingface.co/api"
+// Endpoint is the default hub, BaseUrl being its api
+const Endpoint = "https://huggingface.co"
+const BaseUrl = Endpoint + "/api"
+
+// Environment variables selecting the hub and the proxy used to reach it, and the access token
+const (
+	EndpointEnv = "HF_ENDPOINT"
+	ProxyEnv    = "HTTPS_PROXY"
+	TokenEnv    = "HF_TOKEN"
+)
 const modelEndpoint = "/models"
+const revisionEndpoint = "/revision"
 
+// HuggingFace reaches the hub api, every request being cancelled along with its context
 type HuggingFace interface {
-	GetModelsByPipelineTag(tag PipelineTag, limit int, authorizationKey string) (Models, error)
-	GetModelById(id string, authorizationKey string) (Model, error)
+	GetModelsByPipelineTag(ctx context.Context, tag PipelineTag, limit int, authorizationKey string) (Models, error)
+	GetModelById(ctx context.Context, id string, authorizationKey string) (Model, error)
+	GetModelByRevision(ctx context.Context, id string, revision string, authorizationKey string) (Model, error)
+	SearchModels(ctx context.Context, params SearchParams, authorizationKey string) (Models, error)
+	WhoAmI(ctx context.Context, authorizationKey string) (User, error)
 }
 
 type huggingFace struct {
 	BaseUrl string
 	Client  *http.Client
+	Retry   RetryPolicy
+	Cache   *Cache
+}
+
+// RetryPolicy defines how the requests failing with a transient status (429, 5xx) are retried
+// The wait starts at Backoff and doubles on every attempt, unless the hub asked for a given delay.
+// A delay longer than MaxBackoff isn't waited for : the error is returned instead.
+type RetryPolicy struct {
+	Attempts   int
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy is the retry policy of the requests sent to the hub
+var DefaultRetryPolicy = RetryPolicy{Attempts: 4, Backoff: time.Second, MaxBackoff: 30 * time.Second}
+
+// Timeouts defines how long the requests sent to the hub may hang
+type Timeouts struct {
+	// Connect bounds the connection to the hub (i.e. dial and TLS handshake)
+	Connect time.Duration
+	// Request bounds every request as a whole, 0 meaning no limit (i.e. for the file downloads)
+	Request time.Duration
 }
 
+// DefaultTimeouts are the timeouts of the requests sent to the hub api
+var DefaultTimeouts = Timeouts{Connect: 10 * time.Second, Request: time.Minute}
+
 // NewHuggingFace creates a new HuggingFace instance
-func NewHuggingFace(baseUrl, proxyUrl string) HuggingFace {
-	client := &http.Client{}
-	if proxyUrl != "" {
-		if pUrl, err := url.Parse(proxyUrl); err == nil {
-			client.Transport = &http.Transport{
-				Proxy: http.ProxyURL(pUrl),
-			}
-		}
+func NewHuggingFace(baseUrl, proxyUrl string, timeouts Timeouts) HuggingFace {
+	return &huggingFace{
+		BaseUrl: baseUrl,
+		Client:  NewClient(proxyUrl, timeouts),
+		Retry:   DefaultRetryPolicy,
 	}
+}
 
+// NewCachedHuggingFace creates a new HuggingFace instance storing the api responses in the cache
+func NewCachedHuggingFace(baseUrl, proxyUrl string, timeouts Timeouts, cache *Cache) HuggingFace {
 	return &huggingFace{
 		BaseUrl: baseUrl,
-		Client:  client,
+		Client:  NewClient(proxyUrl, timeouts),
+		Retry:   DefaultRetryPolicy,
+		Cache:   cache,
 	}
 }
 
+// NewClient creates an http client going through the proxy, if any, and bounded by the timeouts
+func NewClient(proxyUrl string, timeouts Timeouts) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if proxyUrl != "" {
+		if pUrl, err := url.Parse(proxyUrl); err == nil {
+			transport.Proxy = http.ProxyURL(pUrl)
+		}
+	}
+	if timeouts.Connect > 0 {
+		transport.DialContext = (&net.Dialer{Timeout: timeouts.Connect, KeepAlive: 30 * time.Second}).DialContext
+		transport.TLSHandshakeTimeout = timeouts.Connect
+	}
+	return &http.Client{Transport: transport, Timeout: timeouts.Request}
+}
+
+// ApiUrl returns the url of the api served by the hub endpoint (i.e. a mirror of huggingface.co)
+func ApiUrl(endpoint string) string {
+	return strings.TrimSuffix(endpoint, "/") + "/api"
+}
+
 // Models Define a list of models to match the JSON response from the API
 type Models []Model
 
 // Model Define a model to match the JSON response from the API
 type Model struct {
 	Name         string      `json:"modelId"`
+	Sha          string      `json:"sha"`
 	PipelineTag  PipelineTag `json:"pipeline_tag"`
 	LibraryName  Module      `json:"library_name"`
 	LastModified string      `json:"lastModified"`
+	Tags         []string    `json:"tags"`
+	Downloads    int         `json:"downloads"`
+	Likes        int         `json:"likes"`
+	Gated        Gated       `json:"gated"`
+	Private      bool        `json:"private"`
+	Siblings     []Sibling   `json:"siblings"`
+	CardData     CardData    `json:"cardData"`
+}
+
+// Sibling Define a file of a model repository to match the JSON response from the API
+type Sibling struct {
+	RFileName string `json:"rfilename"`
+	Size      int64  `json:"size"`
+	LFS       *LFS   `json:"lfs,omitempty"`
+}
+
+// LFS Define the large file storage details of a file, when stored through git LFS
+type LFS struct {
+	Size        int64  `json:"size"`
+	Sha256      string `json:"sha256"`
+	PointerSize int64  `json:"pointerSize"`
+}
+
+// Gated Define the access approval required by a model : empty when not gated, otherwise auto or manual
+// The API sends false for the models that aren't gated.
+type Gated string
+
+// UnmarshalJSON decodes the gated field, sent either as a boolean or as the approval mode
+func (g *Gated) UnmarshalJSON(data []byte) error {
+	var gated bool
+	if err := json.Unmarshal(data, &gated); err == nil {
+		*g = ""
+		if gated {
+			*g = "true"
+		}
+		return nil
+	}
+	var mode string
+	if err := json.Unmarshal(data, &mode); err != nil {
+		return err
+	}
+	*g = Gated(mode)
+	return nil
+}
+
+// IsGated returns true if the access to the model has to be approved
+func (g Gated) IsGated() bool {
+	return g != ""
+}
+
+// CardData Define the metadata of the model card (i.e. license, datasets, base model)
+type CardData map[string]interface{}
+
+// License returns the license declared in the model card, if any
+func (c CardData) License() string {
+	license, _ := c["license"].(string)
+	return license
 }
 
 // apiGet performs an HTTP GET request to the specified URL.
-func (h huggingFace) apiGet(getModelUrl *url.URL, authorizationKey string) ([]byte, error) {
+func (h huggingFace) apiGet(ctx context.Context, getModelUrl *url.URL, authorizationKey string) ([]byte, error) {
+	body, _, err := h.apiGetPage(ctx, getModelUrl, authorizationKey)
+	return body, err
+}
+
+// apiGetPage performs an HTTP GET request to the specified URL, returning the url of the next page if any.
+// The cached response is used while fresh, an older one being revalidated.
+func (h huggingFace) apiGetPage(ctx context.Context, getModelUrl *url.URL, authorizationKey string) ([]byte, *url.URL, error) {
+	var entry cacheEntry
+	var cached bool
+	if h.Cache != nil {
+		entry, cached = h.Cache.load(getModelUrl, authorizationKey)
+		if cached && (h.Cache.Only || entry.isFresh(h.Cache.TTL)) {
+			return entry.Body, nextPage(getModelUrl, entry.Link), nil
+		}
+		if h.Cache.Only {
+			return nil, nil, ErrNotCached
+		}
+	}
+
+	// Execute API call, only asking for the response if it changed since it was cached
+	response, err := h.apiGetWithRetry(ctx, getModelUrl, authorizationKey, entry.ETag)
+	if err != nil {
+		return nil, nil, err
+	}
+	if response.status == http.StatusNotModified {
+		entry.StoredAt = time.Now()
+		_ = h.Cache.store(getModelUrl, authorizationKey, entry)
+		return entry.Body, nextPage(getModelUrl, entry.Link), nil
+	}
+
+	if h.Cache != nil && json.Valid(response.body) {
+		_ = h.Cache.store(getModelUrl, authorizationKey, cacheEntry{
+			ETag:     response.header.Get("ETag"),
+			Link:     response.header.Get("Link"),
+			StoredAt: time.Now(),
+			Body:     response.body,
+		})
+	}
+	return response.body, nextPage(getModelUrl, response.header.Get("Link")), nil
+}
+
+// apiResponse represents a response accepted from the api
+type apiResponse struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// apiGetWithRetry performs an HTTP GET request to the specified URL, retrying the transient failures
+// according to the retry policy, unless the context is cancelled.
+func (h huggingFace) apiGetWithRetry(ctx context.Context, getModelUrl *url.URL, authorizationKey, etag string) (response apiResponse, err error) {
+	backoff := h.Retry.Backoff
+	for attempt := 1; ; attempt++ {
+		response, err = h.apiGetOnce(ctx, getModelUrl, authorizationKey, etag)
+		if err == nil || attempt >= h.Retry.Attempts || !isRetryable(response.status) {
+			return response, err
+		}
+
+		// Waiting as long as the hub asked to, otherwise backing off
+		wait := RetryAfter(err)
+		if wait == 0 {
+			wait = backoff
+			backoff *= 2
+		}
+		if wait > h.Retry.MaxBackoff {
+			return response, err
+		}
+		select {
+		case <-ctx.Done():
+			return response, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// apiGetOnce performs a single HTTP GET request to the specified URL
+// Given the ETag of a cached response, the response is only sent if it changed (otherwise 304 Not Modified).
+func (h huggingFace) apiGetOnce(ctx context.Context, getModelUrl *url.URL, authorizationKey, etag string) (apiResponse, error) {
 	// Create http request
-	req, err := http.NewRequest("GET", getModelUrl.String(), nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", getModelUrl.String(), nil)
+	if err != nil {
+		return apiResponse{}, err
+	}
 	// Add authorization key when needed
 	if authorizationKey != "" {
 		req.Header.Set("Authorization", "Bearer "+authorizationKey)
 	}
-	if err != nil {
-		return nil, err
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
 	}
 
 	// Execute API call
 	response, err := h.Client.Do(req)
 	if err != nil {
-		return nil, err
+		return apiResponse{}, err
 	}
 	defer response.Body.Close()
 
+	// Read response body
+	result := apiResponse{status: response.StatusCode, header: response.Header}
+	result.body, err = io.ReadAll(response.Body)
+	if err != nil {
+		return result, err
+	}
+
 	// Check response status
-	if response.StatusCode != http.StatusOK {
-		// Read response body
-		body, err := io.ReadAll(response.Body)
+	if response.StatusCode != http.StatusOK && (etag == "" || response.StatusCode != http.StatusNotModified) {
+		return result, newAPIError(response, result.body)
+	}
+	return result, nil
+}
+
+// nextPage returns the url of the next page from the Link header (i.e. <url>; rel="next"), or nil on the last page
+func nextPage(current *url.URL, link string) *url.URL {
+	for _, part := range strings.Split(link, ",") {
+		target, params, found := strings.Cut(strings.TrimSpace(part), ";")
+		if !found || !strings.Contains(strings.ReplaceAll(params, " ", ""), `rel="next"`) {
+			continue
+		}
+		target = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(target), "<"), ">")
+		next, err := current.Parse(target)
 		if err != nil {
-			fmt.Println("Error reading response body:", err)
+			return nil
 		}
-		return nil, fmt.Errorf("failed to fetch model. Status code: %s\n%s", response.Status, body)
-	}
-	body, err := io.ReadAll(response.Body)
-	if err != nil {
-		return nil, err
+		return next
 	}
-
-	return body, nil
+	return nil
 }
@@ -0,0 +1,98 @@
+package huggingface
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultCacheTTL is how long a cached response is used before being revalidated
+const DefaultCacheTTL = 10 * time.Minute
+
+// ErrNotCached is returned by a cache only serving the stored responses (i.e. offline) when the response is missing
+var ErrNotCached = errors.New("not available in the cache")
+
+// Cache stores the responses of the api on disk, keyed by url and by the scope of the access token
+// A response younger than the TTL is used as is, an older one being revalidated through its ETag.
+type Cache struct {
+	Directory string
+	TTL       time.Duration
+	// Only serves the stored responses whatever their age, without reaching the hub (i.e. when offline)
+	Only bool
+}
+
+// cacheEntry represents a cached response
+type cacheEntry struct {
+	Url      string          `json:"url"`
+	ETag     string          `json:"etag,omitempty"`
+	Link     string          `json:"link,omitempty"`
+	StoredAt time.Time       `json:"storedAt"`
+	Body     json.RawMessage `json:"body"`
+}
+
+// tokenScope identifies the access token without storing it, the responses depending on what the token can see
+func tokenScope(authorizationKey string) string {
+	if authorizationKey == "" {
+		return "anonymous"
+	}
+	sum := sha256.Sum256([]byte(authorizationKey))
+	return hex.EncodeToString(sum[:8])
+}
+
+// path returns where the response to the url is cached for the access token
+func (c *Cache) path(u *url.URL, authorizationKey string) string {
+	sum := sha256.Sum256([]byte(u.String()))
+	return filepath.Join(c.Directory, tokenScope(authorizationKey), hex.EncodeToString(sum[:])+".json")
+}
+
+// load returns the cached response to the url, if any
+func (c *Cache) load(u *url.URL, authorizationKey string) (entry cacheEntry, found bool) {
+	data, err := os.ReadFile(c.path(u, authorizationKey))
+	if err != nil {
+		return entry, false
+	}
+	if err = json.Unmarshal(data, &entry); err != nil || entry.Url != u.String() {
+		return cacheEntry{}, false
+	}
+	return entry, true
+}
+
+// store caches the response to the url, replacing the previous one atomically
+// The cache being an optimization, the callers can ignore the error.
+func (c *Cache) store(u *url.URL, authorizationKey string, entry cacheEntry) error {
+	entry.Url = u.String()
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	path := c.path(u, authorizationKey)
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	temporary, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = temporary.Write(data)
+	if closeErr := temporary.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(temporary.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(temporary.Name())
+	}
+	return err
+}
+
+// isFresh returns true if the entry can be used without being revalidated
+func (e cacheEntry) isFresh(ttl time.Duration) bool {
+	return time.Since(e.StoredAt) < ttl
+}
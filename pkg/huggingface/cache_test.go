@@ -0,0 +1,86 @@
+package huggingface
+
+import (
+	"context"
+	"errors"
+	"github.com/easy-model-fusion/emf-cli/test"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newTestCacheServer creates a server answering with an ETag, and only sending the model if it changed
+func newTestCacheServer(calls *int, etags *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		*etags = append(*etags, r.Header.Get("If-None-Match"))
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = w.Write([]byte(`{"modelId":"org/model","sha":"abc123"}`))
+	}))
+}
+
+// TestCache_Fresh tests that a fresh response is served without reaching the hub.
+func TestCache_Fresh(t *testing.T) {
+	var calls int
+	var etags []string
+	server := newTestCacheServer(&calls, &etags)
+	defer server.Close()
+	h := NewCachedHuggingFace(server.URL, "", DefaultTimeouts, &Cache{Directory: t.TempDir(), TTL: time.Hour})
+
+	_, err := h.GetModelById(context.Background(), "org/model", "")
+	test.AssertEqual(t, err, nil)
+	apiModel, err := h.GetModelById(context.Background(), "org/model", "")
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, apiModel.Sha, "abc123")
+	test.AssertEqual(t, calls, 1)
+
+	// The responses depend on the access token
+	_, err = h.GetModelById(context.Background(), "org/model", "token")
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, calls, 2)
+}
+
+// TestCache_Revalidation tests that a stale response is revalidated through its ETag.
+func TestCache_Revalidation(t *testing.T) {
+	var calls int
+	var etags []string
+	server := newTestCacheServer(&calls, &etags)
+	defer server.Close()
+	h := NewCachedHuggingFace(server.URL, "", DefaultTimeouts, &Cache{Directory: t.TempDir(), TTL: 0})
+
+	_, err := h.GetModelById(context.Background(), "org/model", "")
+	test.AssertEqual(t, err, nil)
+	apiModel, err := h.GetModelById(context.Background(), "org/model", "")
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, apiModel.Sha, "abc123")
+	test.AssertEqual(t, calls, 2)
+	test.AssertEqual(t, etags[0], "")
+	test.AssertEqual(t, etags[1], `"v1"`)
+}
+
+// TestCache_Only tests that only the stored responses are served, whatever their age.
+func TestCache_Only(t *testing.T) {
+	var calls int
+	var etags []string
+	server := newTestCacheServer(&calls, &etags)
+	directory := t.TempDir()
+	_, err := NewCachedHuggingFace(server.URL, "", DefaultTimeouts, &Cache{Directory: directory}).GetModelById(
+		context.Background(), "org/model", "")
+
+	test.AssertEqual(t, err, nil)
+	server.Close()
+
+	h := NewCachedHuggingFace(server.URL, "", DefaultTimeouts, &Cache{Directory: directory, Only: true})
+	apiModel, err := h.GetModelById(context.Background(), "org/model", "")
+	test.AssertEqual(t, err, nil)
+	test.AssertEqual(t, apiModel.Sha, "abc123")
+
+	_, err = h.GetModelById(context.Background(), "org/missing", "")
+	test.AssertEqual(t, errors.Is(err, ErrNotCached), true)
+	test.AssertEqual(t, calls, 1)
+}
@@ -0,0 +1,137 @@
+package huggingface
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Errors returned by the hub, wrapped by an APIError holding the details of the response
+var (
+	ErrNotFound     = errors.New("not found on the hub")
+	ErrUnauthorized = errors.New("unauthorized, the access token is missing or invalid")
+	ErrGated        = errors.New("gated, its access has to be requested")
+	ErrRateLimited  = errors.New("rate limited by the hub")
+)
+
+// Error codes sent by the hub through the X-Error-Code header
+const (
+	errorCodeHeader     = "X-Error-Code"
+	errorMessageHeader  = "X-Error-Message"
+	errorCodeRepo       = "RepoNotFound"
+	errorCodeRevision   = "RevisionNotFound"
+	errorCodeEntry      = "EntryNotFound"
+	errorCodeGatedRepo  = "GatedRepo"
+	retryAfterHeader    = "Retry-After"
+	errorMessageMaxSize = 500
+)
+
+// APIError represents a request the hub refused
+// Err is one of the errors above (i.e. errors.Is(err, ErrNotFound)), nil when the status has no specific meaning.
+type APIError struct {
+	Err        error
+	StatusCode int
+	Status     string
+	Message    string
+	// RetryAfter is how long the hub asked to wait before retrying, when rate limited
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	message := "failed to fetch model. Status code: " + e.Status
+	if e.Err != nil {
+		message = fmt.Sprintf("%s (%s)", e.Err, e.Status)
+	}
+	if e.RetryAfter > 0 {
+		message += fmt.Sprintf(", retry after %s", e.RetryAfter)
+	}
+	if e.Message != "" {
+		message += "\n" + e.Message
+	}
+	return message
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+// RetryAfter returns how long the hub asked to wait before retrying, 0 if it didn't
+func RetryAfter(err error) time.Duration {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.RetryAfter
+	}
+	return 0
+}
+
+// newAPIError maps the refused response to its error
+func newAPIError(response *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: response.StatusCode,
+		Status:     response.Status,
+		Message:    errorMessage(response.Header, body),
+	}
+
+	code := response.Header.Get(errorCodeHeader)
+	switch {
+	case code == errorCodeRepo || code == errorCodeRevision || code == errorCodeEntry:
+		apiErr.Err = ErrNotFound
+	case code == errorCodeGatedRepo:
+		apiErr.Err = ErrGated
+	case response.StatusCode == http.StatusNotFound:
+		apiErr.Err = ErrNotFound
+	case response.StatusCode == http.StatusUnauthorized:
+		apiErr.Err = ErrUnauthorized
+	case response.StatusCode == http.StatusForbidden:
+		apiErr.Err = ErrGated
+	case response.StatusCode == http.StatusTooManyRequests:
+		apiErr.Err = ErrRateLimited
+		apiErr.RetryAfter = parseRetryAfter(response.Header.Get(retryAfterHeader), time.Now())
+	}
+	return apiErr
+}
+
+// errorMessage returns the message sent by the hub, from the headers or from the json body
+func errorMessage(header http.Header, body []byte) string {
+	if message := header.Get(errorMessageHeader); message != "" {
+		return message
+	}
+	var payload struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &payload); err == nil && payload.Error != "" {
+		return payload.Error
+	}
+	message := strings.TrimSpace(string(body))
+	if len(message) > errorMessageMaxSize {
+		message = message[:errorMessageMaxSize] + "..."
+	}
+	return message
+}
+
+// parseRetryAfter parses the Retry-After header, sent either as a number of seconds or as a date
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil && date.After(now) {
+		return date.Sub(now)
+	}
+	return 0
+}
+
+// isRetryable returns true if the status is transient (i.e. rate limited or unavailable server)
+func isRetryable(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError
+}
@@ -0,0 +1,168 @@
+package huggingface
+
+import (
+	"context"
+	"errors"
+	"github.com/easy-model-fusion/emf-cli/test"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newTestHuggingFace creates a HuggingFace instance against the local server, retrying without waiting
+func newTestHuggingFace(serverUrl string) HuggingFace {
+	h := NewHuggingFace(serverUrl, "", DefaultTimeouts)
+	h.(*huggingFace).Retry = RetryPolicy{Attempts: 3, Backoff: time.Millisecond, MaxBackoff: time.Second}
+	return h
+}
+
+// TestGetModelById_Errors tests that the refused requests are mapped to their error.
+func TestGetModelById_Errors(t *testing.T) {
+	cases := []struct {
+		status   int
+		code     string
+		expected error
+	}{
+		{http.StatusNotFound, "", ErrNotFound},
+		{http.StatusUnauthorized, errorCodeRepo, ErrNotFound},
+		{http.StatusUnauthorized, errorCodeGatedRepo, ErrGated},
+		{http.StatusUnauthorized, "", ErrUnauthorized},
+		{http.StatusForbidden, "", ErrGated},
+		{http.StatusTooManyRequests, "", ErrRateLimited},
+	}
+	for _, c := range cases {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if c.code != "" {
+				w.Header().Set(errorCodeHeader, c.code)
+			}
+			w.Header().Set(retryAfterHeader, "120")
+			w.WriteHeader(c.status)
+			_, _ = w.Write([]byte(`{"error":"hub message"}`))
+		}))
+
+		_, err := newTestHuggingFace(server.URL).GetModelById(context.Background(), "org/model", "")
+		server.Close()
+
+		var apiErr *APIError
+		test.AssertEqual(t, errors.Is(err, c.expected), true, c.code)
+		test.AssertEqual(t, errors.As(err, &apiErr), true)
+		test.AssertEqual(t, apiErr.StatusCode, c.status)
+		test.AssertEqual(t, apiErr.Message, "hub message")
+	}
+}
+
+// TestGetModelById_Retry tests that the transient failures are retried until the request succeeds.
+func TestGetModelById_Retry(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if calls == 2 {
+			w.Header().Set(retryAfterHeader, "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{"modelId":"org/model"}`))
+	}))
+	defer server.Close()
+
+	apiModel, err := newTestHuggingFace(server.URL).GetModelById(context.Background(), "org/model", "")
+	test.AssertEqual(t, err, nil, "The api call should've passed.")
+	test.AssertEqual(t, apiModel.Name, "org/model")
+	test.AssertEqual(t, calls, 3)
+}
+
+// TestGetModelById_RetryExhausted tests that the last error is returned once the attempts are exhausted.
+func TestGetModelById_RetryExhausted(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	_, err := newTestHuggingFace(server.URL).GetModelById(context.Background(), "org/model", "")
+	var apiErr *APIError
+	test.AssertEqual(t, errors.As(err, &apiErr), true)
+	test.AssertEqual(t, apiErr.StatusCode, http.StatusBadGateway)
+	test.AssertEqual(t, calls, 3)
+
+	// A delay longer than the maximum backoff isn't waited for
+	calls = 0
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set(retryAfterHeader, "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	_, err = newTestHuggingFace(server.URL).GetModelById(context.Background(), "org/model", "")
+	test.AssertEqual(t, errors.Is(err, ErrRateLimited), true)
+	test.AssertEqual(t, RetryAfter(err), time.Hour)
+	test.AssertEqual(t, calls, 1)
+
+	// The requests refused for good aren't retried
+	calls = 0
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusNotFound)
+	})
+	_, err = newTestHuggingFace(server.URL).GetModelById(context.Background(), "org/model", "")
+	test.AssertEqual(t, errors.Is(err, ErrNotFound), true)
+	test.AssertEqual(t, calls, 1)
+}
+
+// TestParseRetryAfter tests that the delay is parsed from seconds or from a date.
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	test.AssertEqual(t, parseRetryAfter("30", now), 30*time.Second)
+	test.AssertEqual(t, parseRetryAfter("Mon, 01 Jan 2024 12:01:00 GMT", now), time.Minute)
+	test.AssertEqual(t, parseRetryAfter("Mon, 01 Jan 2024 11:00:00 GMT", now), time.Duration(0))
+	test.AssertEqual(t, parseRetryAfter("-5", now), time.Duration(0))
+	test.AssertEqual(t, parseRetryAfter("invalid", now), time.Duration(0))
+	test.AssertEqual(t, parseRetryAfter("", now), time.Duration(0))
+}
+
+// TestGetModelById_Cancelled tests that the request and the wait before retrying stop along with the context.
+func TestGetModelById_Cancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	h := NewHuggingFace(server.URL, "", DefaultTimeouts)
+	h.(*huggingFace).Retry = RetryPolicy{Attempts: 3, Backoff: time.Minute, MaxBackoff: time.Hour}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := h.GetModelById(ctx, "org/model", "")
+	test.AssertEqual(t, errors.Is(err, context.DeadlineExceeded), true)
+	test.AssertEqual(t, time.Since(start) < time.Minute, true)
+
+	// A cancelled context stops the request before it is sent
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	_, err = h.GetModelById(ctx, "org/model", "")
+	test.AssertEqual(t, errors.Is(err, context.Canceled), true)
+}
+
+// TestGetModelById_Timeout tests that a hanging hub fails once the request timeout is reached.
+func TestGetModelById_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Minute):
+		}
+	}))
+	defer server.Close()
+
+	h := NewHuggingFace(server.URL, "", Timeouts{Connect: time.Second, Request: 50 * time.Millisecond})
+	_, err := h.GetModelById(context.Background(), "org/model", "")
+	var netErr net.Error
+	test.AssertEqual(t, errors.As(err, &netErr), true)
+	test.AssertEqual(t, netErr.Timeout(), true)
+}
@@ -1,15 +1,28 @@
 package huggingface
 
+import "context"
+
 type MockHuggingFace struct {
 	GetModelResult  Model
 	GetModelsResult Models
+	WhoAmIResult    User
 	Error           error
 }
 
-func (hf *MockHuggingFace) GetModelsByPipelineTag(_ PipelineTag, _ int, _ string) (Models, error) {
+func (hf *MockHuggingFace) GetModelsByPipelineTag(_ context.Context, _ PipelineTag, _ int, _ string) (Models, error) {
 	return hf.GetModelsResult, hf.Error
 }
-func (hf *MockHuggingFace) GetModelById(id string, _ string) (Model, error) {
+func (hf *MockHuggingFace) GetModelById(_ context.Context, id string, _ string) (Model, error) {
+	hf.GetModelResult.Name = id
+	return hf.GetModelResult, hf.Error
+}
+func (hf *MockHuggingFace) GetModelByRevision(_ context.Context, id string, _ string, _ string) (Model, error) {
 	hf.GetModelResult.Name = id
 	return hf.GetModelResult, hf.Error
 }
+func (hf *MockHuggingFace) SearchModels(_ context.Context, _ SearchParams, _ string) (Models, error) {
+	return hf.GetModelsResult, hf.Error
+}
+func (hf *MockHuggingFace) WhoAmI(_ context.Context, _ string) (User, error) {
+	return hf.WhoAmIResult, hf.Error
+}
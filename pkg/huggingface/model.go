@@ -1,13 +1,18 @@
 package huggingface
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
 	"net/url"
+	"strconv"
 )
 
+// maxPageSize is the maximum number of models listed by a page of the api
+const maxPageSize = 1000
+
 // GetModelsByPipelineTag from hugging face api by pipeline tag
-func (h huggingFace) GetModelsByPipelineTag(tag PipelineTag, limit int, authorizationKey string) (Models, error) {
+// The pages are followed until the limit is reached, a limit of 0 only fetching the first page.
+func (h huggingFace) GetModelsByPipelineTag(ctx context.Context, tag PipelineTag, limit int, authorizationKey string) (Models, error) {
 	getModelsUrl, err := url.Parse(h.BaseUrl + modelEndpoint)
 	if err != nil {
 		return nil, err
@@ -17,37 +22,54 @@ func (h huggingFace) GetModelsByPipelineTag(tag PipelineTag, limit int, authoriz
 	q := getModelsUrl.Query()
 	q.Add("config", "config")
 	q.Add("pipeline_tag", string(tag))
-	if limit > 0 {
-		q.Add("limit", fmt.Sprintf("%d", limit))
-	}
 	getModelsUrl.RawQuery = q.Encode()
 
+	// Execute API calls
+	return h.getModelPages(ctx, getModelsUrl, limit, authorizationKey)
+}
+
+// GetModelById from hugging face api by id
+func (h huggingFace) GetModelById(ctx context.Context, id string, authorizationKey string) (Model, error) {
+
+	getModelUrl, err := url.Parse(h.BaseUrl + modelEndpoint + "/" + id)
+	if err != nil {
+		return Model{}, err
+	}
+
+	// Request the size and checksum of the files
+	q := getModelUrl.Query()
+	q.Add("blobs", "true")
+	getModelUrl.RawQuery = q.Encode()
+
 	// Execute API call
-	response, err := h.apiGet(getModelsUrl, authorizationKey)
+	response, err := h.apiGet(ctx, getModelUrl, authorizationKey)
 	if err != nil {
-		return Models{}, err
+		return Model{}, err
 	}
 
 	// Unmarshal API response
-	var models Models
-	if err = json.Unmarshal(response, &models); err != nil {
-		return Models{}, err
+	var model Model
+	if err = json.Unmarshal(response, &model); err != nil {
+		return Model{}, err
 	}
-
-	// Execute API call
-	return models, err
+	return model, nil
 }
 
-// GetModelById from hugging face api by id
-func (h huggingFace) GetModelById(id string, authorizationKey string) (Model, error) {
+// GetModelByRevision from hugging face api by id at the given revision (commit sha, branch or tag)
+func (h huggingFace) GetModelByRevision(ctx context.Context, id string, revision string, authorizationKey string) (Model, error) {
 
-	getModelUrl, err := url.Parse(h.BaseUrl + modelEndpoint + "/" + id)
+	getModelUrl, err := url.Parse(h.BaseUrl + modelEndpoint + "/" + id + revisionEndpoint + "/" + url.PathEscape(revision))
 	if err != nil {
 		return Model{}, err
 	}
 
+	// Request the size and checksum of the files
+	q := getModelUrl.Query()
+	q.Add("blobs", "true")
+	getModelUrl.RawQuery = q.Encode()
+
 	// Execute API call
-	response, err := h.apiGet(getModelUrl, authorizationKey)
+	response, err := h.apiGet(ctx, getModelUrl, authorizationKey)
 	if err != nil {
 		return Model{}, err
 	}
@@ -59,3 +81,79 @@ func (h huggingFace) GetModelById(id string, authorizationKey string) (Model, er
 	}
 	return model, nil
 }
+
+// SearchModels from hugging face api using the given query parameters
+func (h huggingFace) SearchModels(ctx context.Context, params SearchParams, authorizationKey string) (Models, error) {
+	searchModelsUrl, err := url.Parse(h.BaseUrl + modelEndpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prepare API call
+	q := searchModelsUrl.Query()
+	q.Add("config", "config")
+	if params.Search != "" {
+		q.Add("search", params.Search)
+	}
+	if params.Author != "" {
+		q.Add("author", params.Author)
+	}
+	if params.Tag != "" {
+		q.Add("filter", params.Tag)
+	}
+	if params.Library != "" {
+		q.Add("library", string(params.Library))
+	}
+	if params.Sort != "" {
+		q.Add("sort", string(params.Sort))
+		q.Add("direction", "-1")
+	}
+	if params.PipelineTag != "" {
+		q.Add("pipeline_tag", string(params.PipelineTag))
+	}
+	if params.Full {
+		q.Add("full", "true")
+		q.Add("cardData", "true")
+	}
+	searchModelsUrl.RawQuery = q.Encode()
+
+	// Execute API calls
+	return h.getModelPages(ctx, searchModelsUrl, params.Limit, authorizationKey)
+}
+
+// getModelPages fetches the models listed by the url, following the pages until the limit is reached
+// A limit of 0 only fetches the first page.
+func (h huggingFace) getModelPages(ctx context.Context, listUrl *url.URL, limit int, authorizationKey string) (Models, error) {
+	if limit > 0 {
+		q := listUrl.Query()
+		q.Set("limit", strconv.Itoa(min(limit, maxPageSize)))
+		listUrl.RawQuery = q.Encode()
+	}
+
+	models := Models{}
+	for pageUrl := listUrl; pageUrl != nil; {
+		// Execute API call
+		response, next, err := h.apiGetPage(ctx, pageUrl, authorizationKey)
+		if err != nil {
+			return Models{}, err
+		}
+
+		// Unmarshal API response
+		var page Models
+		if err = json.Unmarshal(response, &page); err != nil {
+			return Models{}, err
+		}
+		models = append(models, page...)
+
+		// Limit reached or last page
+		if limit <= 0 || len(models) >= limit {
+			break
+		}
+		pageUrl = next
+	}
+
+	if limit > 0 && len(models) > limit {
+		models = models[:limit]
+	}
+	return models, nil
+}
@@ -1,7 +1,13 @@
 package huggingface
 
 import (
+	"context"
+	"encoding/json"
 	"github.com/easy-model-fusion/emf-cli/test"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
 	"testing"
 )
 
@@ -10,8 +16,8 @@ import (
 // It asserts that the API call is successful, the expected number of models are returned, and that each model has a non-empty name.
 func TestGetModelsByPipelineTag_Success(t *testing.T) {
 	limit := 10
-	h := NewHuggingFace(BaseUrl, "")
-	models, err := h.GetModelsByPipelineTag(TextToImage, 10, "")
+	h := NewHuggingFace(BaseUrl, "", DefaultTimeouts)
+	models, err := h.GetModelsByPipelineTag(context.Background(), TextToImage, 10, "")
 	test.AssertEqual(t, err, nil, "The api call should've passed.")
 	test.AssertEqual(t, len(models), limit, "The api call should've returned 10 models.")
 
@@ -24,8 +30,8 @@ func TestGetModelsByPipelineTag_Success(t *testing.T) {
 // It initializes a HuggingFace instance and calls GetModelsByPipelineTag to retrieve models by pipeline tag.
 // It asserts that the API call fails and that no models are returned.
 func TestGetModelsByPipelineTag_Failure(t *testing.T) {
-	h := NewHuggingFace("% xw*cbadurl", "")
-	models, err := h.GetModelsByPipelineTag(TextToImage, 10, "")
+	h := NewHuggingFace("% xw*cbadurl", "", DefaultTimeouts)
+	models, err := h.GetModelsByPipelineTag(context.Background(), TextToImage, 10, "")
 	test.AssertNotEqual(t, err, nil, "The api call should've failed.")
 	test.AssertEqual(t, len(models), 0, "The api call should've returned 0 models.")
 }
@@ -34,16 +40,158 @@ func TestGetModelsByPipelineTag_Failure(t *testing.T) {
 // It initializes a HuggingFace instance and calls GetModelById to retrieve a model by its ID.
 // It asserts that the API call is successful, a model is returned, and that the model has a non-empty name matching the specified ID.
 func TestGetModelById(t *testing.T) {
-	h := NewHuggingFace(BaseUrl, "")
-	apiModel, err := h.GetModelById("Xibanya/sunset_city", "")
+	h := NewHuggingFace(BaseUrl, "", DefaultTimeouts)
+	apiModel, err := h.GetModelById(context.Background(), "Xibanya/sunset_city", "")
 	test.AssertEqual(t, err, nil, "The api call should've passed.")
 	test.AssertNotEqual(t, apiModel, nil, "The api call should've returned a model.")
 
 	test.AssertEqual(t, apiModel.Name, "Xibanya/sunset_city", "Model's name should not be empty.")
 
 	// set with bad url
-	h = NewHuggingFace("% xw*cbadurl", "")
-	apiModel, err = h.GetModelById("Xibanya/sunset_city", "")
+	h = NewHuggingFace("% xw*cbadurl", "", DefaultTimeouts)
+	apiModel, err = h.GetModelById(context.Background(), "Xibanya/sunset_city", "")
 	test.AssertNotEqual(t, err, nil, "The api call should've failed.")
-	test.AssertEqual(t, apiModel, Model{}, "The api call should've returned an empty model.")
+	test.AssertEqual(t, reflect.DeepEqual(apiModel, Model{}), true, "The api call should've returned an empty model.")
+}
+
+// TestSearchModels_Success tests the SearchModels method of the HuggingFace type.
+// It initializes a HuggingFace instance against a local server and asserts that the query parameters are sent.
+func TestSearchModels_Success(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		_, _ = w.Write([]byte(`[{"modelId":"org/model","library_name":"diffusers","downloads":42,"likes":7}]`))
+	}))
+	defer server.Close()
+
+	h := NewHuggingFace(server.URL, "", DefaultTimeouts)
+	models, err := h.SearchModels(
+
+		context.Background(), SearchParams{
+			Search:  "anime",
+			Author:  "org",
+			Tag:     "text-to-image",
+			Library: DIFFUSERS,
+			Sort:    SortDownloads,
+			Limit:   5,
+		}, "")
+
+	test.AssertEqual(t, err, nil, "The api call should've passed.")
+	test.AssertEqual(t, len(models), 1, "The api call should've returned 1 model.")
+	test.AssertEqual(t, models[0].Downloads, 42)
+	test.AssertEqual(t, models[0].Likes, 7)
+
+	test.AssertEqual(t, query.Get("search"), "anime")
+	test.AssertEqual(t, query.Get("author"), "org")
+	test.AssertEqual(t, query.Get("filter"), "text-to-image")
+	test.AssertEqual(t, query.Get("library"), "diffusers")
+	test.AssertEqual(t, query.Get("sort"), "downloads")
+	test.AssertEqual(t, query.Get("direction"), "-1")
+	test.AssertEqual(t, query.Get("limit"), "5")
+}
+
+// TestSearchModels_Failure tests the SearchModels method of the HuggingFace type with a bad url.
+// It asserts that the API call fails and that no models are returned.
+func TestSearchModels_Failure(t *testing.T) {
+	h := NewHuggingFace("% xw*cbadurl", "", DefaultTimeouts)
+	models, err := h.SearchModels(context.Background(), SearchParams{Search: "anime"}, "")
+	test.AssertNotEqual(t, err, nil, "The api call should've failed.")
+	test.AssertEqual(t, len(models), 0, "The api call should've returned 0 models.")
+}
+
+// TestGetModelByRevision tests the GetModelByRevision method of the HuggingFace type.
+// It initializes a HuggingFace instance against a local server and asserts that the revision endpoint is called.
+func TestGetModelByRevision(t *testing.T) {
+	var path, blobs string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		blobs = r.URL.Query().Get("blobs")
+		_, _ = w.Write([]byte(`{"modelId":"org/model","sha":"abc123","siblings":[{"rfilename":"model.safetensors","size":42}]}`))
+	}))
+	defer server.Close()
+
+	h := NewHuggingFace(server.URL, "", DefaultTimeouts)
+	apiModel, err := h.GetModelByRevision(context.Background(), "org/model", "v1.0", "")
+	test.AssertEqual(t, err, nil, "The api call should've passed.")
+	test.AssertEqual(t, apiModel.Sha, "abc123")
+	test.AssertEqual(t, path, "/models/org/model/revision/v1.0")
+	test.AssertEqual(t, blobs, "true")
+	test.AssertEqual(t, apiModel.Siblings[0].Size, int64(42))
+
+	// set with bad url
+	h = NewHuggingFace("% xw*cbadurl", "", DefaultTimeouts)
+	apiModel, err = h.GetModelByRevision(context.Background(), "org/model", "v1.0", "")
+	test.AssertNotEqual(t, err, nil, "The api call should've failed.")
+	test.AssertEqual(t, reflect.DeepEqual(apiModel, Model{}), true, "The api call should've returned an empty model.")
+}
+
+// TestSearchModels_Pagination tests that the pages are followed through the Link header until the limit is reached.
+func TestSearchModels_Pagination(t *testing.T) {
+	var requests []url.Values
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Query())
+		cursor := r.URL.Query().Get("cursor")
+		if cursor == "" {
+			w.Header().Set("Link", "<"+server.URL+"/models?cursor=page2&limit=2>; rel=\"next\"")
+			_, _ = w.Write([]byte(`[{"modelId":"org/model1"},{"modelId":"org/model2"}]`))
+			return
+		}
+		w.Header().Set("Link", "<"+server.URL+"/models?cursor=page3&limit=2>; rel=\"next\"")
+		_, _ = w.Write([]byte(`[{"modelId":"org/model3"},{"modelId":"org/model4"}]`))
+	}))
+	defer server.Close()
+
+	h := NewHuggingFace(server.URL, "", DefaultTimeouts)
+	models, err := h.SearchModels(context.Background(), SearchParams{Search: "anime", PipelineTag: TextToImage, Limit: 3}, "")
+	test.AssertEqual(t, err, nil, "The api call should've passed.")
+	test.AssertEqual(t, len(models), 3, "The pages should've been followed until the limit.")
+	test.AssertEqual(t, models[2].Name, "org/model3")
+	test.AssertEqual(t, len(requests), 2)
+	test.AssertEqual(t, requests[0].Get("limit"), "3")
+	test.AssertEqual(t, requests[0].Get("pipeline_tag"), "text-to-image")
+	test.AssertEqual(t, requests[1].Get("cursor"), "page2")
+
+	// Without limit, only the first page is fetched
+	requests = nil
+	models, err = h.SearchModels(context.Background(), SearchParams{Search: "anime"}, "")
+	test.AssertEqual(t, err, nil, "The api call should've passed.")
+	test.AssertEqual(t, len(models), 2)
+	test.AssertEqual(t, len(requests), 1)
+}
+
+// TestGetModelById_Metadata tests that the metadata of the model is decoded.
+func TestGetModelById_Metadata(t *testing.T) {
+	var blobs string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		blobs = r.URL.Query().Get("blobs")
+		_, _ = w.Write([]byte(`{"modelId":"org/model","sha":"abc123","tags":["diffusers","license:mit"],"gated":"manual",
+			"private":true,"cardData":{"license":"mit"},"siblings":[{"rfilename":"config.json","size":12},
+			{"rfilename":"model.safetensors","size":42,"lfs":{"size":42,"sha256":"def456","pointerSize":134}}]}`))
+	}))
+	defer server.Close()
+
+	h := NewHuggingFace(server.URL, "", DefaultTimeouts)
+	apiModel, err := h.GetModelById(context.Background(), "org/model", "")
+	test.AssertEqual(t, err, nil, "The api call should've passed.")
+	test.AssertEqual(t, blobs, "true")
+	test.AssertEqual(t, apiModel.Sha, "abc123")
+	test.AssertEqual(t, reflect.DeepEqual(apiModel.Tags, []string{"diffusers", "license:mit"}), true)
+	test.AssertEqual(t, apiModel.Gated.IsGated(), true)
+	test.AssertEqual(t, apiModel.Gated, Gated("manual"))
+	test.AssertEqual(t, apiModel.Private, true)
+	test.AssertEqual(t, apiModel.CardData.License(), "mit")
+	test.AssertEqual(t, apiModel.Siblings[0].LFS == nil, true)
+	test.AssertEqual(t, apiModel.Siblings[1].LFS.Sha256, "def456")
+	test.AssertEqual(t, apiModel.Siblings[1].LFS.Size, int64(42))
+}
+
+// TestGated_UnmarshalJSON tests that the gated field is decoded from a boolean or an approval mode.
+func TestGated_UnmarshalJSON(t *testing.T) {
+	var model Model
+	test.AssertEqual(t, json.Unmarshal([]byte(`{"gated":false}`), &model), nil)
+	test.AssertEqual(t, model.Gated.IsGated(), false)
+	test.AssertEqual(t, json.Unmarshal([]byte(`{"gated":"auto"}`), &model), nil)
+	test.AssertEqual(t, model.Gated, Gated("auto"))
+	test.AssertEqual(t, json.Unmarshal([]byte(`{"gated":12}`), &model) != nil, true)
 }
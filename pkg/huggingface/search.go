@@ -0,0 +1,38 @@
+package huggingface
+
+var AllSorts = []Sort{
+	SortDownloads,
+	SortLikes,
+	SortLastModified,
+}
+
+type Sort string
+
+const (
+	SortDownloads    Sort = "downloads"
+	SortLikes        Sort = "likes"
+	SortLastModified Sort = "lastModified"
+)
+
+// SearchParams Define the query parameters used to search models on the hub
+// The pages are followed until the limit is reached, a limit of 0 only fetching the first page.
+type SearchParams struct {
+	Search      string
+	Author      string
+	Tag         string
+	PipelineTag PipelineTag
+	Library     Module
+	Sort        Sort
+	Limit       int
+	// Full requests the files and the card data of every model
+	Full bool
+}
+
+// AllSortsString returns all sorts as a string slice
+func AllSortsString() []string {
+	var sorts []string
+	for _, sort := range AllSorts {
+		sorts = append(sorts, string(sort))
+	}
+	return sorts
+}
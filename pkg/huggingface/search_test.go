@@ -0,0 +1,12 @@
+package huggingface
+
+import (
+	"github.com/easy-model-fusion/emf-cli/test"
+	"testing"
+)
+
+func TestAllSortsString(t *testing.T) {
+	sorts := AllSortsString()
+	test.AssertEqual(t, len(sorts), len(AllSorts), "Should return all sorts")
+	test.AssertEqual(t, sorts[0], string(SortDownloads))
+}
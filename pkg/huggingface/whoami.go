@@ -0,0 +1,112 @@
+package huggingface
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+)
+
+const whoAmIEndpoint = "/whoami-v2"
+
+// roleFineGrained is the role of the tokens whose permissions are listed scope by scope
+const roleFineGrained = "fineGrained"
+
+// User Define the owner of an access token to match the JSON response from the API
+type User struct {
+	Type     string         `json:"type"`
+	Name     string         `json:"name"`
+	FullName string         `json:"fullname"`
+	Email    string         `json:"email"`
+	Orgs     []Organization `json:"orgs"`
+	Auth     Auth           `json:"auth"`
+}
+
+// Organization Define an organization the user belongs to
+type Organization struct {
+	Name      string `json:"name"`
+	RoleInOrg string `json:"roleInOrg"`
+}
+
+// Auth Define how the user authenticated
+type Auth struct {
+	Type        string    `json:"type"`
+	AccessToken TokenInfo `json:"accessToken"`
+}
+
+// TokenInfo Define the access token used : read, write or fine-grained
+type TokenInfo struct {
+	DisplayName string       `json:"displayName"`
+	Role        string       `json:"role"`
+	CreatedAt   string       `json:"createdAt"`
+	FineGrained *FineGrained `json:"fineGrained,omitempty"`
+}
+
+// FineGrained Define the permissions of a fine-grained token, globally and per entity
+type FineGrained struct {
+	Global []string      `json:"global"`
+	Scoped []ScopedGrant `json:"scoped"`
+}
+
+// ScopedGrant Define the permissions granted on a user, an organization or a repository
+type ScopedGrant struct {
+	Entity struct {
+		Type string `json:"type"`
+		Name string `json:"name"`
+	} `json:"entity"`
+	Permissions []string `json:"permissions"`
+}
+
+// OrgNames returns the names of the organizations of the user
+func (u User) OrgNames() []string {
+	var names []string
+	for _, org := range u.Orgs {
+		names = append(names, org.Name)
+	}
+	return names
+}
+
+// Scopes returns what the access token grants : its role, or every permission of a fine-grained token
+func (t TokenInfo) Scopes() []string {
+	if t.Role != roleFineGrained || t.FineGrained == nil {
+		if t.Role == "" {
+			return nil
+		}
+		return []string{t.Role}
+	}
+	scopes := append([]string{}, t.FineGrained.Global...)
+	for _, grant := range t.FineGrained.Scoped {
+		for _, permission := range grant.Permissions {
+			scopes = append(scopes, grant.Entity.Name+": "+permission)
+		}
+	}
+	return scopes
+}
+
+// WhoAmI returns the user owning the access token, validating it against the hub
+// The response is never cached, the token being possibly revoked since.
+func (h huggingFace) WhoAmI(ctx context.Context, authorizationKey string) (User, error) {
+	if authorizationKey == "" {
+		return User{}, &APIError{Err: ErrUnauthorized, Status: "no access token"}
+	}
+	whoAmIUrl, err := url.Parse(h.BaseUrl + whoAmIEndpoint)
+	if err != nil {
+		return User{}, err
+	}
+
+	// Execute API call
+	response, err := h.apiGetWithRetry(ctx, whoAmIUrl, authorizationKey, "")
+	if err != nil {
+		return User{}, err
+	}
+
+	// Unmarshal API response
+	var user User
+	if err = json.Unmarshal(response.body, &user); err != nil {
+		return User{}, err
+	}
+	if user.Name == "" {
+		return User{}, errors.New("the hub didn't return the owner of the access token")
+	}
+	return user, nil
+}
@@ -0,0 +1,65 @@
+package huggingface
+
+import (
+	"context"
+	"errors"
+	"github.com/easy-model-fusion/emf-cli/test"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestWhoAmI tests that the owner of the token is returned along with the scopes of the token.
+func TestWhoAmI(t *testing.T) {
+	// Init
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != whoAmIEndpoint || r.Header.Get("Authorization") != "Bearer hf_token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"type":"user","name":"user","email":"user@mail.com",
+			"orgs":[{"name":"org","roleInOrg":"admin"}],
+			"auth":{"type":"access_token","accessToken":{"displayName":"cli","role":"fineGrained",
+			"fineGrained":{"global":["discussion.write"],
+			"scoped":[{"entity":{"type":"model","name":"org/model"},"permissions":["repo.content.read"]}]}}}}`))
+	}))
+	defer server.Close()
+
+	// Execute
+	user, err := newTestHuggingFace(server.URL).WhoAmI(context.Background(), "hf_token")
+
+	// Assert
+	test.AssertEqual(t, err, nil, "The api call should've passed.")
+	test.AssertEqual(t, user.Name, "user")
+	test.AssertEqual(t, strings.Join(user.OrgNames(), ","), "org")
+	test.AssertEqual(t, user.Auth.AccessToken.DisplayName, "cli")
+	test.AssertEqual(t, strings.Join(user.Auth.AccessToken.Scopes(), ","), "discussion.write,org/model: repo.content.read")
+}
+
+// TestWhoAmI_Unauthorized tests that a missing or invalid token is refused.
+func TestWhoAmI_Unauthorized(t *testing.T) {
+	// Init
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+	h := newTestHuggingFace(server.URL)
+
+	// Execute
+	_, err := h.WhoAmI(context.Background(), "invalid")
+	_, errMissing := h.WhoAmI(context.Background(), "")
+
+	// Assert
+	test.AssertEqual(t, errors.Is(err, ErrUnauthorized), true)
+	test.AssertEqual(t, errors.Is(errMissing, ErrUnauthorized), true)
+	test.AssertEqual(t, calls, 1)
+}
+
+// TestTokenInfo_Scopes tests that the role is the scope of the tokens that aren't fine-grained.
+func TestTokenInfo_Scopes(t *testing.T) {
+	test.AssertEqual(t, strings.Join(TokenInfo{Role: "read"}.Scopes(), ","), "read")
+	test.AssertEqual(t, len(TokenInfo{}.Scopes()), 0)
+}
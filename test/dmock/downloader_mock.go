@@ -4,13 +4,32 @@ import (
 	"context"
 	"github.com/easy-model-fusion/emf-cli/internal/downloader/model"
 	"github.com/easy-model-fusion/emf-cli/internal/utils/python"
+	"sync"
 )
 
 type MockDownloader struct {
 	DownloaderModel downloadermodel.Model
 	DownloaderError error
+	// Args are the arguments of the last execution
+	Args downloadermodel.Args
+	// Calls are the arguments of every execution, in the order they started
+	Calls []downloadermodel.Args
+	// OnExecute is called on every execution, i.e. to write the downloaded files
+	OnExecute func(args downloadermodel.Args)
+
+	// mutex guards the recorded executions, the models being possibly downloaded at the same time
+	mutex sync.Mutex
 }
 
-func (d *MockDownloader) Execute(_ downloadermodel.Args, _ python.Python, _ context.Context) (downloadermodel.Model, error) {
+func (d *MockDownloader) Execute(args downloadermodel.Args, _ python.Python, _ context.Context) (downloadermodel.Model, error) {
+	d.mutex.Lock()
+	d.Args = args
+	d.Calls = append(d.Calls, args)
+	onExecute := d.OnExecute
+	d.mutex.Unlock()
+
+	if onExecute != nil {
+		onExecute(args)
+	}
 	return d.DownloaderModel, d.DownloaderError
 }
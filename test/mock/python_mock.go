@@ -1,6 +1,7 @@
 package mock
 
 import (
+	"bytes"
 	"context"
 	"github.com/easy-model-fusion/emf-cli/internal/ui"
 )
@@ -16,6 +17,8 @@ type MockPython struct {
 	ScriptResult             []byte
 	ScriptExit               int
 	CalledFunctions          map[string]int
+	// PipCommands records the arguments of every pip call, when not nil
+	PipCommands *[][]string
 }
 
 func (m MockPython) CheckPythonVersion(_ string) (string, bool) {
@@ -43,8 +46,11 @@ func (m MockPython) InstallDependencies(_, _ string) error {
 	return m.InstallDependenciesError
 }
 
-func (m MockPython) ExecutePip(_ string, _ []string) error {
+func (m MockPython) ExecutePip(_ string, args []string) error {
 	m.callFunction("ExecutePip")
+	if m.PipCommands != nil {
+		*m.PipCommands = append(*m.PipCommands, args)
+	}
 	return m.ExecutePipError
 }
 
@@ -53,6 +59,16 @@ func (m MockPython) ExecuteScript(_, _ string, _ []string, _ context.Context) ([
 	return m.ScriptResult, m.ExecuteScriptError, m.ScriptExit
 }
 
+func (m MockPython) ExecuteScriptStream(_, _ string, _ []string, _ []string, _ context.Context, onLine func(line []byte)) (error, int) {
+	m.callFunction("ExecuteScriptStream")
+	for _, line := range bytes.Split(m.ScriptResult, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) > 0 {
+			onLine(line)
+		}
+	}
+	return m.ExecuteScriptError, m.ScriptExit
+}
+
 func (m MockPython) CheckAskForPython(_ ui.UI) (string, bool) {
 	m.callFunction("CheckAskForPython")
 	return m.Path, m.Success
@@ -10,6 +10,8 @@ type MockUI struct {
 	MultiselectResult      []string
 	SelectResult           string
 	UserConfirmationResult bool
+	// Tables records the rows (header included) of every displayed table, when not nil
+	Tables *[][][]string
 }
 
 type mockPrinter struct {
@@ -30,10 +32,26 @@ func (m MockUI) StartSpinner(_ string) ui.Spinner {
 	return &MockSpinner{}
 }
 
+type MockProgressBar struct{}
+
+func (m MockProgressBar) Add(_ int) {
+}
+
+func (m MockProgressBar) Stop() {
+}
+
+func (m MockUI) StartProgressBar(_ string, _ int) ui.ProgressBar {
+	return &MockProgressBar{}
+}
+
 func (m MockUI) AskForUsersInput(_ string) string {
 	return m.UserInputResult
 }
 
+func (m MockUI) AskForUsersSecret(_ string) string {
+	return m.UserInputResult
+}
+
 func (m MockUI) DisplayInteractiveMultiselect(_ string, _ []string, _ ui.Checkmark, _, _ bool, _ int) []string {
 	return m.MultiselectResult
 }
@@ -45,6 +63,12 @@ func (m MockUI) DisplayInteractiveSelect(_ string, _ []string, _ bool, _ int) st
 func (m MockUI) DisplaySelectedItems(_ []string) {
 }
 
+func (m MockUI) DisplayTable(data [][]string) {
+	if m.Tables != nil {
+		*m.Tables = append(*m.Tables, data)
+	}
+}
+
 func (m MockUI) AskForUsersConfirmation(_ string) bool {
 	return m.UserConfirmationResult
 }
@@ -115,3 +115,14 @@ func (ts *TestSuite) CleanTestSuite(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+// CreateFiles creates the files, mapped by their path relative to the directory, along with their parent directories
+func CreateFiles(t *testing.T, directory string, files map[string]string) {
+	for name, content := range files {
+		path := filepath.Join(directory, name)
+		err := os.MkdirAll(filepath.Dir(path), os.ModePerm)
+		AssertEqual(t, err, nil)
+		err = os.WriteFile(path, []byte(content), os.ModePerm)
+		AssertEqual(t, err, nil)
+	}
+}